@@ -0,0 +1,139 @@
+package ash
+
+import (
+	"sort"
+	"time"
+)
+
+// CPU is the wait event reported for samples of backends which were active, but not waiting for anything
+const CPU = "CPU"
+
+// Load represents the amount of sampled activity attributed to a single key within a time window
+type Load struct {
+	// Aggregation key, e.g. a wait event or application name
+	Key string `json:"key"`
+	// Number of samples attributed to the key
+	Samples int `json:"samples"`
+	// Estimated time spent active, i.e. number of samples multiplied by the sampling interval
+	ActiveTime time.Duration `json:"active_time"`
+	// Average number of active sessions attributed to the key within the window
+	AverageActiveSessions float64 `json:"average_active_sessions"`
+}
+
+// QueryLoad represents the amount of sampled activity attributed to a single query within a time window
+type QueryLoad struct {
+	Load
+	// Identifier of the query (zero if query identifiers are not available)
+	QueryId int64 `json:"query_id"`
+	// Text of the most recently sampled execution of the query
+	Query string `json:"query"`
+}
+
+// TopWaitEvents returns at most n wait events (in "type:event" form, or CPU if not waiting)
+// ordered by the number of samples taken within [from, to). Non-positive n means no limit.
+func (s *Sampler) TopWaitEvents(from, to time.Time, n int) []Load {
+	return s.aggregate(from, to, n, func(sample Sample) string {
+		if sample.WaitEventType == "" {
+			return CPU
+		}
+		return sample.WaitEventType + ":" + sample.WaitEvent
+	})
+}
+
+// ApplicationLoad returns at most n application names ordered by the number of samples taken within [from, to).
+// Non-positive n means no limit.
+func (s *Sampler) ApplicationLoad(from, to time.Time, n int) []Load {
+	return s.aggregate(from, to, n, func(sample Sample) string {
+		return sample.ApplicationName
+	})
+}
+
+// TopQueries returns at most n queries ordered by the number of samples taken within [from, to).
+// Queries are identified by query_id if available, and by their text otherwise.
+// Non-positive n means no limit.
+func (s *Sampler) TopQueries(from, to time.Time, n int) []QueryLoad {
+	samples := s.Samples(from, to)
+	window := windowLength(from, to, samples, s.interval)
+	type queryKey struct {
+		id   int64
+		text string
+	}
+	byKey := make(map[queryKey]*QueryLoad)
+	for _, sample := range samples {
+		key := queryKey{id: sample.QueryId}
+		if sample.QueryId == 0 {
+			key.text = sample.Query
+		}
+		q, ok := byKey[key]
+		if !ok {
+			q = &QueryLoad{QueryId: sample.QueryId}
+			byKey[key] = q
+		}
+		q.Samples++
+		q.Query = sample.Query
+	}
+	res := make([]QueryLoad, 0, len(byKey))
+	for _, q := range byKey {
+		q.Key = q.Query
+		q.fill(s.interval, window)
+		res = append(res, *q)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Samples != res[j].Samples {
+			return res[i].Samples > res[j].Samples
+		}
+		return res[i].Key < res[j].Key
+	})
+	if n > 0 && len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
+
+func (s *Sampler) aggregate(from, to time.Time, n int, key func(Sample) string) []Load {
+	samples := s.Samples(from, to)
+	window := windowLength(from, to, samples, s.interval)
+	counts := make(map[string]int)
+	for _, sample := range samples {
+		counts[key(sample)]++
+	}
+	res := make([]Load, 0, len(counts))
+	for k, c := range counts {
+		l := Load{Key: k, Samples: c}
+		l.fill(s.interval, window)
+		res = append(res, l)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Samples != res[j].Samples {
+			return res[i].Samples > res[j].Samples
+		}
+		return res[i].Key < res[j].Key
+	})
+	if n > 0 && len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
+
+func (l *Load) fill(interval time.Duration, window time.Duration) {
+	l.ActiveTime = time.Duration(l.Samples) * interval
+	if window > 0 {
+		l.AverageActiveSessions = float64(l.ActiveTime) / float64(window)
+	}
+}
+
+// windowLength returns the length of [from, to), falling back to the time span of samples for open sides
+func windowLength(from, to time.Time, samples []Sample, interval time.Duration) time.Duration {
+	if len(samples) > 0 {
+		if from.IsZero() {
+			from = samples[0].Time
+		}
+		if to.IsZero() {
+			to = samples[len(samples)-1].Time.Add(interval)
+		}
+	}
+	if from.IsZero() || to.IsZero() {
+		return 0
+	}
+	return to.Sub(from)
+}
@@ -0,0 +1,208 @@
+// Package ash provides Active Session History - periodic sampling of active backends
+// from pg_stat_activity into an in-memory ring buffer, with aggregations over arbitrary time windows.
+package ash
+
+import (
+	"github.com/pkg/errors"
+	"github.com/vynaloze/pgstats"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sample represents a single active backend observed at a single point in time
+type Sample struct {
+	// Time at which the sample was taken
+	Time time.Time `json:"time"`
+	// Process ID of the backend
+	Pid int64 `json:"pid"`
+	// Name of the database this backend is connected to
+	Datname string `json:"datname"`
+	// Name of the user logged into this backend
+	Usename string `json:"usename"`
+	// Name of the application that is connected to this backend
+	ApplicationName string `json:"application_name"`
+	// Type of the backend (empty before PostgreSQL 10)
+	BackendType string `json:"backend_type"`
+	// Current overall state of the backend
+	State string `json:"state"`
+	// The type of event for which the backend is waiting, if any
+	WaitEventType string `json:"wait_event_type"`
+	// Wait event name if the backend is waiting
+	WaitEvent string `json:"wait_event"`
+	// Identifier of the query being executed (zero before PostgreSQL 14 or if compute_query_id is disabled)
+	QueryId int64 `json:"query_id"`
+	// Text of the query being executed
+	Query string `json:"query"`
+}
+
+// Filter decides whether a backend should be recorded as a sample
+type Filter func(row pgstats.PgStatActivityRow) bool
+
+// DefaultFilter accepts active backends, except for the ones querying pg_stat_activity
+// (which includes the sampler itself).
+func DefaultFilter(row pgstats.PgStatActivityRow) bool {
+	if row.State.String != "active" {
+		return false
+	}
+	return !strings.Contains(row.Query.String, "pg_stat_activity")
+}
+
+// Sampler periodically polls pg_stat_activity and records active backends in a ring buffer of fixed capacity.
+// Once the buffer is full, the oldest samples are overwritten.
+type Sampler struct {
+	// Filter decides which backends are recorded. Defaults to DefaultFilter.
+	Filter Filter
+
+	stats    *pgstats.PgStats
+	interval time.Duration
+
+	mu      sync.RWMutex
+	samples []Sample
+	next    int
+	full    bool
+	err     error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSampler creates a sampler polling given connection every interval,
+// keeping at most capacity most recent samples.
+// Both interval and capacity must be positive.
+func NewSampler(stats *pgstats.PgStats, interval time.Duration, capacity int) (*Sampler, error) {
+	if interval <= 0 {
+		return nil, errors.Errorf("Invalid sampling interval: %s", interval)
+	}
+	if capacity <= 0 {
+		return nil, errors.Errorf("Invalid capacity: %d", capacity)
+	}
+	return &Sampler{
+		Filter:   DefaultFilter,
+		stats:    stats,
+		interval: interval,
+		samples:  make([]Sample, capacity),
+	}, nil
+}
+
+// Interval returns the sampling interval
+func (s *Sampler) Interval() time.Duration {
+	return s.interval
+}
+
+// Start starts sampling in background. It does nothing if the sampler is already running.
+func (s *Sampler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.run(s.stop, s.done)
+}
+
+// Stop stops sampling and waits for the background goroutine to finish.
+// Recorded samples are kept.
+func (s *Sampler) Stop() {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// Err returns the error of the most recent sampling attempt, if it failed
+func (s *Sampler) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.err
+}
+
+func (s *Sampler) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case t := <-ticker.C:
+			err := s.Sample(t)
+			s.mu.Lock()
+			s.err = err
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Sample fetches pg_stat_activity once and records active backends as observed at given time
+func (s *Sampler) Sample(t time.Time) error {
+	rows, err := s.stats.PgStatActivity()
+	if err != nil {
+		return err
+	}
+	s.Record(t, rows)
+	return nil
+}
+
+// Record stores backends from given pg_stat_activity content accepted by the Filter, as observed at given time
+func (s *Sampler) Record(t time.Time, rows pgstats.PgStatActivityView) {
+	filter := s.Filter
+	if filter == nil {
+		filter = DefaultFilter
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.samples) == 0 {
+		return
+	}
+	for _, row := range rows {
+		if !filter(row) {
+			continue
+		}
+		s.samples[s.next] = Sample{
+			Time:            t,
+			Pid:             row.Pid,
+			Datname:         row.Datname.String,
+			Usename:         row.Usename.String,
+			ApplicationName: row.ApplicationName.String,
+			BackendType:     row.BackendType.String,
+			State:           row.State.String,
+			WaitEventType:   row.WaitEventType.String,
+			WaitEvent:       row.WaitEvent.String,
+			QueryId:         row.QueryId.Int64,
+			Query:           row.Query.String,
+		}
+		s.next = (s.next + 1) % len(s.samples)
+		if s.next == 0 {
+			s.full = true
+		}
+	}
+}
+
+// Samples returns recorded samples taken within [from, to), ordered by time.
+// Zero from or to leave the window open on the respective side.
+func (s *Sampler) Samples(from, to time.Time) []Sample {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ordered := s.samples[:s.next]
+	if s.full {
+		ordered = append(append(make([]Sample, 0, len(s.samples)), s.samples[s.next:]...), s.samples[:s.next]...)
+	}
+	res := make([]Sample, 0)
+	for _, sample := range ordered {
+		if !from.IsZero() && sample.Time.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !sample.Time.Before(to) {
+			continue
+		}
+		res = append(res, sample)
+	}
+	return res
+}
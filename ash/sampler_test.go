@@ -0,0 +1,101 @@
+package ash
+
+import (
+	"github.com/vynaloze/pgstats"
+	"github.com/vynaloze/pgstats/nullable"
+	"testing"
+	"time"
+)
+
+func activity(pid int64, state string, app string, waitType string, wait string) pgstats.PgStatActivityRow {
+	row := pgstats.PgStatActivityRow{Pid: pid}
+	row.State = str(state)
+	row.ApplicationName = str(app)
+	row.WaitEventType = str(waitType)
+	row.WaitEvent = str(wait)
+	row.Query = str("select 1")
+	return row
+}
+
+func str(s string) nullable.String {
+	n := nullable.String{}
+	n.String = s
+	n.Valid = s != ""
+	return n
+}
+
+func TestRingBufferOverwritesOldestSamples(t *testing.T) {
+	s, err := NewSampler(nil, time.Second, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		s.Record(start.Add(time.Duration(i)*time.Second), pgstats.PgStatActivityView{
+			activity(int64(i), "active", "app", "", ""),
+			activity(100, "idle", "app", "Client", "ClientRead"),
+		})
+	}
+	samples := s.Samples(time.Time{}, time.Time{})
+	if len(samples) != 3 {
+		t.Fatalf("Expected 3 samples; actual %d", len(samples))
+	}
+	for i, sample := range samples {
+		if sample.Pid != int64(i+2) {
+			t.Errorf("Expected pid %d; actual %d", i+2, sample.Pid)
+		}
+	}
+	window := s.Samples(start.Add(3*time.Second), start.Add(4*time.Second))
+	if len(window) != 1 || window[0].Pid != 3 {
+		t.Errorf("Expected single sample of pid 3; actual %+v", window)
+	}
+}
+
+func TestAggregations(t *testing.T) {
+	s, err := NewSampler(nil, time.Second, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		rows := pgstats.PgStatActivityView{
+			activity(1, "active", "web", "Lock", "transactionid"),
+			activity(2, "active", "batch", "", ""),
+		}
+		if i%2 == 0 {
+			rows = append(rows, activity(3, "active", "web", "Lock", "transactionid"))
+		}
+		s.Record(start.Add(time.Duration(i)*time.Second), rows)
+	}
+
+	waits := s.TopWaitEvents(start, start.Add(4*time.Second), 0)
+	if len(waits) != 2 || waits[0].Key != "Lock:transactionid" || waits[0].Samples != 6 || waits[1].Key != CPU {
+		t.Fatalf("Unexpected wait events: %+v", waits)
+	}
+	if waits[0].AverageActiveSessions != 1.5 || waits[0].ActiveTime != 6*time.Second {
+		t.Errorf("Expected 1.5 AAS and 6s of active time; actual %f and %s",
+			waits[0].AverageActiveSessions, waits[0].ActiveTime)
+	}
+
+	apps := s.ApplicationLoad(time.Time{}, time.Time{}, 1)
+	if len(apps) != 1 || apps[0].Key != "web" || apps[0].Samples != 6 {
+		t.Errorf("Unexpected application load: %+v", apps)
+	}
+
+	queries := s.TopQueries(time.Time{}, time.Time{}, 0)
+	if len(queries) != 1 || queries[0].Samples != 10 || queries[0].Query != "select 1" {
+		t.Errorf("Unexpected top queries: %+v", queries)
+	}
+}
+
+func TestNewSamplerValidation(t *testing.T) {
+	if _, err := NewSampler(nil, 0, 10); err == nil {
+		t.Error("Expected error for zero interval")
+	}
+	if _, err := NewSampler(nil, time.Second, 0); err == nil {
+		t.Error("Expected error for zero capacity")
+	}
+	if _, err := NewSampler(nil, -time.Second, 10); err == nil {
+		t.Error("Expected error for negative interval")
+	}
+}
@@ -0,0 +1,298 @@
+package pgstats
+
+import (
+	"github.com/lib/pq"
+	"github.com/vynaloze/pgstats/nullable"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AutovacuumQueueView represents tables of the current database, and their TOAST tables, ordered by how urgently
+// they need to be vacuumed or analyzed by the autovacuum daemon
+type AutovacuumQueueView []AutovacuumQueueRow
+
+// AutovacuumQueueRow represents autovacuum state of a single table
+type AutovacuumQueueRow struct {
+	// OID of a table
+	Relid int64 `json:"relid"`
+	// Name of the schema that this table is in
+	Schemaname string `json:"schemaname"`
+	// Name of this table
+	Relname string `json:"relname"`
+	// Schema-qualified name of the table owning this TOAST table; null for other tables.
+	// TOAST tables are vacuumed on their own and never analyzed. They follow toast.* storage parameters
+	// of the owning table, or its regular storage parameters if no toast.* ones are set.
+	ToastOf nullable.String `json:"toast_of"`
+	// True if autovacuum is enabled both globally and for this table
+	Enabled bool `json:"enabled"`
+	// Estimated number of live rows, as recorded by the last vacuum or analyze (pg_class.reltuples)
+	Reltuples float64 `json:"reltuples"`
+	// Estimated number of dead rows
+	NDeadTup int64 `json:"n_dead_tup"`
+	// Estimated number of rows modified since this table was last analyzed
+	NModSinceAnalyze int64 `json:"n_mod_since_analyze"`
+	// Estimated number of rows inserted since this table was last vacuumed.
+	// Supported since PostgreSQL 13
+	NInsSinceVacuum nullable.Int64 `json:"n_ins_since_vacuum"`
+	// Number of dead rows above which autovacuum vacuums this table:
+	// autovacuum_vacuum_threshold + autovacuum_vacuum_scale_factor * reltuples,
+	// taking table storage parameters into account
+	VacuumThreshold float64 `json:"vacuum_threshold"`
+	// Number of modified rows above which autovacuum analyzes this table:
+	// autovacuum_analyze_threshold + autovacuum_analyze_scale_factor * reltuples,
+	// taking table storage parameters into account
+	AnalyzeThreshold float64 `json:"analyze_threshold"`
+	// Number of inserted rows above which autovacuum vacuums this table:
+	// autovacuum_vacuum_insert_threshold + autovacuum_vacuum_insert_scale_factor * reltuples,
+	// taking table storage parameters into account. Null if insert-driven vacuum is disabled (threshold -1).
+	// Supported since PostgreSQL 13
+	InsertThreshold nullable.Float64 `json:"insert_threshold"`
+	// NDeadTup as a fraction of VacuumThreshold. Above 1, the table is due to be vacuumed.
+	VacuumRatio float64 `json:"vacuum_ratio"`
+	// NInsSinceVacuum as a fraction of InsertThreshold. Above 1, the table is due to be vacuumed.
+	// Supported since PostgreSQL 13
+	InsertRatio nullable.Float64 `json:"insert_ratio"`
+	// NModSinceAnalyze as a fraction of AnalyzeThreshold. Above 1, the table is due to be analyzed.
+	// Always zero for TOAST tables.
+	AnalyzeRatio float64 `json:"analyze_ratio"`
+	// Last time at which this table was vacuumed by the autovacuum daemon
+	LastAutovacuum nullable.Time `json:"last_autovacuum"`
+	// Last time at which this table was analyzed by the autovacuum daemon
+	LastAutoanalyze nullable.Time `json:"last_autoanalyze"`
+	// Process ID of the backend currently vacuuming this table, if any.
+	// Supported since PostgreSQL 9.6
+	VacuumPid nullable.Int64 `json:"vacuum_pid"`
+	// Current processing phase of the vacuum running on this table, if any.
+	// Supported since PostgreSQL 9.6
+	VacuumPhase nullable.String `json:"vacuum_phase"`
+}
+
+// Due returns only the tables past their vacuum or analyze threshold, leaving out the ones autovacuum is disabled for:
+// the autovacuum daemon never processes them because of the thresholds, only to prevent transaction ID wraparound.
+func (v AutovacuumQueueView) Due() AutovacuumQueueView {
+	res := make(AutovacuumQueueView, 0)
+	for _, row := range v {
+		if row.Enabled && row.urgency() > 1 {
+			res = append(res, row)
+		}
+	}
+	return res
+}
+
+// urgency is the ratio of the threshold the table is furthest past
+func (r AutovacuumQueueRow) urgency() float64 {
+	urgency := r.VacuumRatio
+	if r.AnalyzeRatio > urgency {
+		urgency = r.AnalyzeRatio
+	}
+	if r.InsertRatio.Valid && r.InsertRatio.Float64 > urgency {
+		urgency = r.InsertRatio.Float64
+	}
+	return urgency
+}
+
+type autovacuumSettings struct {
+	enabled            bool
+	vacuumThreshold    float64
+	vacuumScaleFactor  float64
+	analyzeThreshold   float64
+	analyzeScaleFactor float64
+	insertThreshold    float64
+	insertScaleFactor  float64
+}
+
+type autovacuumTable struct {
+	row        AutovacuumQueueRow
+	reloptions []string
+	// storage parameters of the table owning a TOAST table
+	ownerReloptions []string
+}
+
+func (s *PgStats) fetchAutovacuumQueue() (AutovacuumQueueView, error) {
+	version, err := s.getPgVersion()
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.fetchAutovacuumSettings(version)
+	if err != nil {
+		return nil, err
+	}
+
+	insSinceVacuum := "null::bigint"
+	if version >= 13 {
+		insSinceVacuum = "s.n_ins_since_vacuum"
+	}
+	columns := "s.relid,s.schemaname,s.relname,c.reltuples,c.reloptions,s.n_dead_tup,s.n_mod_since_analyze," +
+		insSinceVacuum + ",s.last_autovacuum,s.last_autoanalyze,current_database()"
+	// TOAST tables of user tables hold their toast.* storage parameters in their own reloptions, without the prefix
+	db := s.db()
+	query := "select " + columns + ",null,null::text[] " +
+		"from pg_stat_user_tables s join pg_class c on c.oid=s.relid " +
+		"union all " +
+		"select " + columns + ",quote_ident(u.schemaname)||'.'||quote_ident(u.relname),o.reloptions " +
+		"from pg_stat_all_tables s join pg_class c on c.oid=s.relid " +
+		"join pg_class o on o.reltoastrelid=c.oid join pg_stat_user_tables u on u.relid=o.oid"
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var datname string
+	tables := make([]autovacuumTable, 0)
+	for rows.Next() {
+		t := autovacuumTable{}
+		var deadTup, modSinceAnalyze nullable.Int64
+		err := rows.Scan(&t.row.Relid, &t.row.Schemaname, &t.row.Relname, &t.row.Reltuples, (*pq.StringArray)(&t.reloptions),
+			&deadTup, &modSinceAnalyze, &t.row.NInsSinceVacuum, &t.row.LastAutovacuum, &t.row.LastAutoanalyze, &datname, &t.row.ToastOf,
+			(*pq.StringArray)(&t.ownerReloptions))
+		if err != nil {
+			return nil, err
+		}
+		t.row.NDeadTup = deadTup.Int64
+		t.row.NModSinceAnalyze = modSinceAnalyze.Int64
+		tables = append(tables, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	progress := make(PgStatProgressVacuumView, 0)
+	if version >= 9.6 {
+		all, err := s.fetchProgressVacuum()
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range all {
+			if p.Datname == datname {
+				progress = append(progress, p)
+			}
+		}
+	}
+	return autovacuumQueue(tables, settings, progress), nil
+}
+
+func (s *PgStats) fetchAutovacuumSettings(version float64) (autovacuumSettings, error) {
+	var err error
+	settings := autovacuumSettings{}
+	if settings.enabled, err = s.fetchSettingBool("autovacuum"); err != nil {
+		return settings, err
+	}
+	if settings.vacuumThreshold, err = s.fetchSettingFloat("autovacuum_vacuum_threshold"); err != nil {
+		return settings, err
+	}
+	if settings.vacuumScaleFactor, err = s.fetchSettingFloat("autovacuum_vacuum_scale_factor"); err != nil {
+		return settings, err
+	}
+	if settings.analyzeThreshold, err = s.fetchSettingFloat("autovacuum_analyze_threshold"); err != nil {
+		return settings, err
+	}
+	if settings.analyzeScaleFactor, err = s.fetchSettingFloat("autovacuum_analyze_scale_factor"); err != nil {
+		return settings, err
+	}
+	if version >= 13 {
+		if settings.insertThreshold, err = s.fetchSettingFloat("autovacuum_vacuum_insert_threshold"); err != nil {
+			return settings, err
+		}
+		settings.insertScaleFactor, err = s.fetchSettingFloat("autovacuum_vacuum_insert_scale_factor")
+	}
+	return settings, err
+}
+
+// autovacuumQueue computes thresholds of given tables, applying their storage parameters over global settings,
+// and orders them by urgency
+func autovacuumQueue(tables []autovacuumTable, settings autovacuumSettings, progress PgStatProgressVacuumView) AutovacuumQueueView {
+	running := make(map[int64]PgStatProgressVacuumRow, len(progress))
+	for _, p := range progress {
+		running[p.Relid] = p
+	}
+
+	queue := make(AutovacuumQueueView, 0, len(tables))
+	for _, t := range tables {
+		row := t.row
+		reloptions := t.reloptions
+		if row.ToastOf.Valid && len(reloptions) == 0 {
+			// like autovacuum, fall back to the owning table for TOAST tables without parameters of their own
+			reloptions = t.ownerReloptions
+		}
+		opts := parseReloptions(reloptions)
+		reltuples := row.Reltuples
+		if reltuples < 0 {
+			// never vacuumed nor analyzed yet (since PostgreSQL 14)
+			reltuples = 0
+		}
+
+		row.Enabled = settings.enabled && optBool(opts, "autovacuum_enabled", true)
+		row.VacuumThreshold = optFloat(opts, "autovacuum_vacuum_threshold", settings.vacuumThreshold) +
+			optFloat(opts, "autovacuum_vacuum_scale_factor", settings.vacuumScaleFactor)*reltuples
+		row.VacuumRatio = thresholdRatio(float64(row.NDeadTup), row.VacuumThreshold)
+		if !row.ToastOf.Valid {
+			row.AnalyzeThreshold = optFloat(opts, "autovacuum_analyze_threshold", settings.analyzeThreshold) +
+				optFloat(opts, "autovacuum_analyze_scale_factor", settings.analyzeScaleFactor)*reltuples
+			row.AnalyzeRatio = thresholdRatio(float64(row.NModSinceAnalyze), row.AnalyzeThreshold)
+		}
+		// insert threshold of -1 disables vacuuming driven by inserts
+		if threshold := optFloat(opts, "autovacuum_vacuum_insert_threshold", settings.insertThreshold); row.NInsSinceVacuum.Valid && threshold >= 0 {
+			row.InsertThreshold.Valid = true
+			row.InsertThreshold.Float64 = threshold + optFloat(opts, "autovacuum_vacuum_insert_scale_factor", settings.insertScaleFactor)*reltuples
+			row.InsertRatio.Valid = true
+			row.InsertRatio.Float64 = thresholdRatio(float64(row.NInsSinceVacuum.Int64), row.InsertThreshold.Float64)
+		}
+
+		if p, ok := running[row.Relid]; ok {
+			row.VacuumPid.Valid, row.VacuumPid.Int64 = true, p.Pid
+			row.VacuumPhase.Valid, row.VacuumPhase.String = true, p.Phase
+		}
+		queue = append(queue, row)
+	}
+
+	sort.SliceStable(queue, func(i, j int) bool {
+		return queue[i].urgency() > queue[j].urgency()
+	})
+	return queue
+}
+
+func thresholdRatio(value float64, threshold float64) float64 {
+	if threshold <= 0 {
+		if value > 0 {
+			return value
+		}
+		return 0
+	}
+	return value / threshold
+}
+
+// parseReloptions converts storage parameters in the form of pg_class.reloptions ("name=value") to a map
+func parseReloptions(reloptions []string) map[string]string {
+	opts := make(map[string]string, len(reloptions))
+	for _, opt := range reloptions {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) == 2 {
+			opts[kv[0]] = kv[1]
+		}
+	}
+	return opts
+}
+
+func optFloat(opts map[string]string, name string, def float64) float64 {
+	if v, ok := opts[name]; ok {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
+
+func optBool(opts map[string]string, name string, def bool) bool {
+	if v, ok := opts[name]; ok {
+		switch strings.ToLower(v) {
+		case "true", "on", "yes", "1", "t", "y":
+			return true
+		case "false", "off", "no", "0", "f", "n":
+			return false
+		}
+	}
+	return def
+}
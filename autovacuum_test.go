@@ -0,0 +1,103 @@
+package pgstats
+
+import "testing"
+
+func TestAutovacuumQueue(t *testing.T) {
+	settings := autovacuumSettings{
+		enabled:            true,
+		vacuumThreshold:    50,
+		vacuumScaleFactor:  0.2,
+		analyzeThreshold:   50,
+		analyzeScaleFactor: 0.1,
+	}
+	tables := []autovacuumTable{
+		{row: AutovacuumQueueRow{Relid: 1, Relname: "quiet", Reltuples: 1000, NDeadTup: 10, NModSinceAnalyze: 10}},
+		{row: AutovacuumQueueRow{Relid: 2, Relname: "tuned", Reltuples: 1000, NDeadTup: 100, NModSinceAnalyze: 0},
+			reloptions: []string{"autovacuum_vacuum_scale_factor=0.01", "autovacuum_vacuum_threshold=0", "fillfactor=90"}},
+		{row: AutovacuumQueueRow{Relid: 3, Relname: "disabled", Reltuples: -1, NDeadTup: 100, NModSinceAnalyze: 75},
+			reloptions: []string{"autovacuum_enabled=false"}},
+	}
+	progress := PgStatProgressVacuumView{{Pid: 42, Relid: 2, Phase: "scanning heap"}}
+
+	q := autovacuumQueue(tables, settings, progress)
+	if len(q) != 3 || q[0].Relid != 2 || q[1].Relid != 3 || q[2].Relid != 1 {
+		t.Fatalf("Unexpected order of the queue: %+v", q)
+	}
+	if q[0].VacuumThreshold != 10 || q[0].VacuumRatio != 10 || !q[0].Enabled {
+		t.Errorf("Expected threshold 10 and ratio 10; actual %f and %f", q[0].VacuumThreshold, q[0].VacuumRatio)
+	}
+	if !q[0].VacuumPid.Valid || q[0].VacuumPid.Int64 != 42 || q[0].VacuumPhase.String != "scanning heap" {
+		t.Errorf("Expected running vacuum to be attached; actual %+v", q[0])
+	}
+	if q[1].Enabled || q[1].VacuumThreshold != 50 || q[1].AnalyzeRatio != 1.5 {
+		t.Errorf("Unexpected state of disabled table: %+v", q[1])
+	}
+	if due := q.Due(); len(due) != 1 || due[0].Relid != 2 {
+		t.Errorf("Expected only the enabled table due; actual %+v", due)
+	}
+}
+
+func TestAutovacuumQueueInserts(t *testing.T) {
+	settings := autovacuumSettings{
+		enabled:            true,
+		vacuumThreshold:    50,
+		vacuumScaleFactor:  0.2,
+		analyzeThreshold:   50,
+		analyzeScaleFactor: 0.1,
+		insertThreshold:    1000,
+		insertScaleFactor:  0.2,
+	}
+	insertOnly := AutovacuumQueueRow{Relid: 1, Relname: "events", Reltuples: 10000}
+	insertOnly.NInsSinceVacuum.Valid, insertOnly.NInsSinceVacuum.Int64 = true, 6000
+	toast := AutovacuumQueueRow{Relid: 2, Schemaname: "pg_toast", Relname: "pg_toast_1", NDeadTup: 100, NModSinceAnalyze: 1000}
+	toast.ToastOf.Valid, toast.ToastOf.String = true, "public.events"
+	toast.NInsSinceVacuum.Valid, toast.NInsSinceVacuum.Int64 = true, 5000
+	old := AutovacuumQueueRow{Relid: 3, Relname: "legacy", Reltuples: 100, NDeadTup: 10}
+	tables := []autovacuumTable{
+		{row: insertOnly},
+		{row: toast, reloptions: []string{"autovacuum_vacuum_threshold=0", "autovacuum_vacuum_insert_threshold=-1"}},
+		{row: old},
+	}
+
+	q := autovacuumQueue(tables, settings, nil)
+	if len(q) != 3 || q[0].Relid != 2 || q[1].Relid != 1 || q[2].Relid != 3 {
+		t.Fatalf("Unexpected order of the queue: %+v", q)
+	}
+	if !q[1].InsertThreshold.Valid || q[1].InsertThreshold.Float64 != 3000 || q[1].InsertRatio.Float64 != 2 || q[1].VacuumRatio != 0 {
+		t.Errorf("Expected insert-only table due by inserts; actual %+v", q[1])
+	}
+	if q[0].VacuumThreshold != 0 || q[0].VacuumRatio != 100 || q[0].InsertThreshold.Valid || q[0].AnalyzeRatio != 0 {
+		t.Errorf("Expected storage parameters of the TOAST table applied and no analyze; actual %+v", q[0])
+	}
+	if q[2].InsertThreshold.Valid || q[2].InsertRatio.Valid {
+		t.Errorf("Expected no insert threshold before PostgreSQL 13; actual %+v", q[2])
+	}
+	if due := q.Due(); len(due) != 2 {
+		t.Errorf("Expected 2 tables due; actual %d", len(due))
+	}
+}
+
+func TestAutovacuumQueueToastFallback(t *testing.T) {
+	settings := autovacuumSettings{enabled: true, vacuumThreshold: 50, vacuumScaleFactor: 0.2}
+	toast := func(relid int64) AutovacuumQueueRow {
+		row := AutovacuumQueueRow{Relid: relid, Schemaname: "pg_toast", Relname: "pg_toast", Reltuples: 1000, NDeadTup: 100}
+		row.ToastOf.Valid, row.ToastOf.String = true, "public.documents"
+		return row
+	}
+	owner := []string{"autovacuum_vacuum_scale_factor=0.01", "autovacuum_enabled=false"}
+	tables := []autovacuumTable{
+		{row: toast(1), ownerReloptions: owner},
+		{row: toast(2), reloptions: []string{"autovacuum_vacuum_threshold=100"}, ownerReloptions: owner},
+	}
+
+	q := autovacuumQueue(tables, settings, nil)
+	if len(q) != 2 || q[0].Relid != 1 || q[1].Relid != 2 {
+		t.Fatalf("Unexpected order of the queue: %+v", q)
+	}
+	if q[0].Enabled || q[0].VacuumThreshold != 60 {
+		t.Errorf("Expected parameters of the owning table applied to TOAST table without its own; actual %+v", q[0])
+	}
+	if !q[1].Enabled || q[1].VacuumThreshold != 300 {
+		t.Errorf("Expected own parameters of TOAST table applied; actual %+v", q[1])
+	}
+}
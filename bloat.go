@@ -0,0 +1,298 @@
+package pgstats
+
+// TableBloatView represents estimated bloat of user tables in the current database
+type TableBloatView []TableBloatRow
+
+// TableBloatRow represents estimated bloat of a single table
+type TableBloatRow struct {
+	// OID of a table
+	Relid int64 `json:"relid"`
+	// Name of the schema that this table is in
+	Schemaname string `json:"schemaname"`
+	// Name of this table
+	Relname string `json:"relname"`
+	// Size of this table (including its TOAST table), in bytes
+	Size int64 `json:"size"`
+	// Estimated space wasted by dead rows and free space exceeding the fillfactor reserve, in bytes
+	BloatSize int64 `json:"bloat_size"`
+	// BloatSize as a fraction of Size
+	BloatRatio float64 `json:"bloat_ratio"`
+	// False if the estimate is known to be inaccurate
+	// (the table has columns of type name or columns without statistics)
+	Reliable bool `json:"reliable"`
+	// True if the value was measured with pgstattuple rather than estimated from statistics
+	Precise bool `json:"precise"`
+}
+
+// IndexBloatView represents estimated bloat of user btree indexes in the current database
+type IndexBloatView []IndexBloatRow
+
+// IndexBloatRow represents estimated bloat of a single btree index
+type IndexBloatRow struct {
+	// OID of the table for this index
+	Relid int64 `json:"relid"`
+	// OID of this index
+	Indexrelid int64 `json:"indexrelid"`
+	// Name of the schema this index is in
+	Schemaname string `json:"schemaname"`
+	// Name of the table for this index
+	Relname string `json:"relname"`
+	// Name of this index
+	Indexrelname string `json:"indexrelname"`
+	// Size of this index, in bytes
+	Size int64 `json:"size"`
+	// Estimated space wasted by leaf pages filled below the fillfactor, in bytes
+	BloatSize int64 `json:"bloat_size"`
+	// BloatSize as a fraction of Size
+	BloatRatio float64 `json:"bloat_ratio"`
+	// False if the estimate is known to be inaccurate (the index has columns of type name)
+	Reliable bool `json:"reliable"`
+	// True if the value was measured with pgstattuple rather than estimated from statistics
+	Precise bool `json:"precise"`
+}
+
+// userSchemas filters out system schemas of the pg_namespace aliased with given name
+func userSchemas(alias string) string {
+	return alias + ".nspname not in ('pg_catalog','information_schema') and " + alias + ".nspname !~ '^pg_toast'"
+}
+
+// Estimation of table bloat from pg_class, pg_stats and pg_attribute, based on:
+// https://github.com/ioguix/pgsql-bloat-estimation
+var tableBloatEstimateQuery = "select tblid,schemaname,tblname,(bs*tblpages)::bigint," +
+	"(greatest(tblpages-est_tblpages_ff,0)*bs)::bigint," +
+	"is_na " +
+	"from (" +
+	" select ceil(reltuples/((bs-page_hdr)*fillfactor/(tpl_size*100)))+ceil(toasttuples/4) as est_tblpages_ff," +
+	" tblpages,bs,tblid,schemaname,tblname,is_na" +
+	" from (" +
+	"  select (4+tpl_hdr_size+tpl_data_size+(2*ma)" +
+	"   -case when tpl_hdr_size%ma=0 then ma else tpl_hdr_size%ma end" +
+	"   -case when ceil(tpl_data_size)::int%ma=0 then ma else ceil(tpl_data_size)::int%ma end) as tpl_size," +
+	"  (heappages+toastpages) as tblpages,reltuples,toasttuples,bs,page_hdr,tblid,schemaname,tblname,fillfactor,is_na" +
+	"  from (" +
+	"   select tbl.oid as tblid,ns.nspname as schemaname,tbl.relname as tblname,greatest(tbl.reltuples,0) as reltuples," +
+	"   tbl.relpages as heappages,coalesce(toast.relpages,0) as toastpages,coalesce(toast.reltuples,0) as toasttuples," +
+	"   coalesce(substring(array_to_string(tbl.reloptions,' ') from 'fillfactor=([0-9]+)')::smallint,100) as fillfactor," +
+	"   current_setting('block_size')::numeric as bs," +
+	"   case when version()~'mingw32' or version()~'64-bit|x86_64|ppc64|ia64|amd64' then 8 else 4 end as ma," +
+	"   24 as page_hdr," +
+	"   23+case when max(coalesce(s.null_frac,0))>0 then (7+count(s.attname))/8 else 0::int end" +
+	"   +case when bool_or(att.attname='oid' and att.attnum<0) then 4 else 0 end as tpl_hdr_size," +
+	"   sum((1-coalesce(s.null_frac,0))*coalesce(s.avg_width,0)) as tpl_data_size," +
+	"   bool_or(att.atttypid='pg_catalog.name'::regtype)" +
+	"   or sum(case when att.attnum>0 then 1 else 0 end)<>count(s.attname) as is_na" +
+	"   from pg_attribute as att" +
+	"   join pg_class as tbl on att.attrelid=tbl.oid" +
+	"   join pg_namespace as ns on ns.oid=tbl.relnamespace" +
+	"   left join pg_stats as s on s.schemaname=ns.nspname and s.tablename=tbl.relname" +
+	"    and s.inherited=false and s.attname=att.attname" +
+	"   left join pg_class as toast on tbl.reltoastrelid=toast.oid" +
+	"   where not att.attisdropped and tbl.relkind in ('r','m') and " + userSchemas("ns") +
+	"   group by 1,2,3,4,5,6,7,8,9,10" +
+	"  ) as s" +
+	" ) as s2" +
+	") as s3"
+
+// Measurement of table bloat with pgstattuple, of the table and its TOAST table (if any).
+// Free space reserved by the fillfactor is not counted as bloat; TOAST tables have no such reserve.
+var tableBloatPreciseQuery = "select c.oid,n.nspname,c.relname,t.table_len+coalesce(tt.table_len,0)," +
+	"greatest(t.dead_tuple_len+t.free_space-t.table_len*(100-coalesce(substring(" +
+	"array_to_string(c.reloptions,' ') from 'fillfactor=([0-9]+)')::smallint,100))/100,0)::bigint" +
+	"+coalesce(tt.dead_tuple_len+tt.free_space,0) " +
+	"from pg_class c join pg_namespace n on n.oid=c.relnamespace " +
+	"cross join lateral pgstattuple(quote_ident(n.nspname)||'.'||quote_ident(c.relname)) t " +
+	"left join lateral (select * from pgstattuple(c.reltoastrelid::regclass::text) where c.reltoastrelid<>0) tt on true " +
+	"where c.relkind in ('r','m') and c.relpersistence<>'t' and " + userSchemas("n")
+
+// Estimation of btree index bloat from pg_class, pg_stats and pg_attribute, based on:
+// https://github.com/ioguix/pgsql-bloat-estimation
+var indexBloatEstimateQuery = "select tbloid,idxoid,nspname,tblname,idxname,(bs*relpages)::bigint," +
+	"(greatest(relpages-est_pages_ff,0)*bs)::bigint," +
+	"is_na " +
+	"from (" +
+	" select coalesce(1+ceil(reltuples/floor((bs-pageopqdata-pagehdr)*fillfactor/(100*(4+nulldatahdrwidth)::float))),0)" +
+	" as est_pages_ff,bs,nspname,tblname,idxname,relpages,tbloid,idxoid,is_na" +
+	" from (" +
+	"  select maxalign,bs,nspname,tblname,idxname,reltuples,relpages,tbloid,idxoid,fillfactor," +
+	"  (index_tuple_hdr_bm+maxalign-case when index_tuple_hdr_bm%maxalign=0 then maxalign else index_tuple_hdr_bm%maxalign end" +
+	"  +nulldatawidth+maxalign-case when nulldatawidth=0 then 0" +
+	"   when nulldatawidth::integer%maxalign=0 then maxalign else nulldatawidth::integer%maxalign end" +
+	"  )::numeric as nulldatahdrwidth,pagehdr,pageopqdata,is_na" +
+	"  from (" +
+	"   select n.nspname,i.tblname,i.idxname,i.reltuples,i.relpages,i.tbloid,i.idxoid,i.fillfactor," +
+	"   current_setting('block_size')::numeric as bs," +
+	"   case when version()~'mingw32' or version()~'64-bit|x86_64|ppc64|ia64|amd64' then 8 else 4 end as maxalign," +
+	"   24 as pagehdr,16 as pageopqdata," +
+	"   case when max(coalesce(s.null_frac,0))=0 then 8 else 8+((32+8-1)/8) end as index_tuple_hdr_bm," +
+	"   sum((1-coalesce(s.null_frac,0))*coalesce(s.avg_width,1024)) as nulldatawidth," +
+	"   max(case when i.atttypid='pg_catalog.name'::regtype then 1 else 0 end)>0 as is_na" +
+	"   from (" +
+	"    select ct.relname as tblname,ct.relnamespace,ic.idxname,ic.attpos,ic.indkey,ic.indkey[ic.attpos]," +
+	"    ic.reltuples,ic.relpages,ic.tbloid,ic.idxoid,ic.fillfactor," +
+	"    coalesce(a1.attnum,a2.attnum) as attnum,coalesce(a1.attname,a2.attname) as attname," +
+	"    coalesce(a1.atttypid,a2.atttypid) as atttypid," +
+	"    case when a1.attnum is null then ic.idxname else ct.relname end as attrelname" +
+	"    from (" +
+	"     select idxname,reltuples,relpages,tbloid,idxoid,fillfactor,indkey," +
+	"     pg_catalog.generate_series(1,indnatts) as attpos" +
+	"     from (" +
+	"      select ci.relname as idxname,ci.reltuples,ci.relpages,i.indrelid as tbloid,i.indexrelid as idxoid," +
+	"      coalesce(substring(array_to_string(ci.reloptions,' ') from 'fillfactor=([0-9]+)')::smallint,90) as fillfactor," +
+	"      i.indnatts," +
+	"      pg_catalog.string_to_array(pg_catalog.textin(pg_catalog.int2vectorout(i.indkey)),' ')::int[] as indkey" +
+	"      from pg_catalog.pg_index i" +
+	"      join pg_catalog.pg_class ci on ci.oid=i.indexrelid" +
+	"      where ci.relam=(select oid from pg_am where amname='btree') and ci.relpages>0" +
+	"     ) as idx_data" +
+	"    ) as ic" +
+	"    join pg_catalog.pg_class ct on ct.oid=ic.tbloid" +
+	"    left join pg_catalog.pg_attribute a1 on ic.indkey[ic.attpos]<>0" +
+	"     and a1.attrelid=ic.tbloid and a1.attnum=ic.indkey[ic.attpos]" +
+	"    left join pg_catalog.pg_attribute a2 on ic.indkey[ic.attpos]=0" +
+	"     and a2.attrelid=ic.idxoid and a2.attnum=ic.attpos" +
+	"   ) i" +
+	"   join pg_catalog.pg_namespace n on n.oid=i.relnamespace" +
+	"   join pg_catalog.pg_stats s on s.schemaname=n.nspname and s.tablename=i.attrelname and s.attname=i.attname" +
+	"   where " + userSchemas("n") +
+	"   group by 1,2,3,4,5,6,7,8,9,10,11" +
+	"  ) as rows_data_stats" +
+	" ) as rows_hdr_pdg_stats" +
+	") as relation_stats"
+
+// Measurement of btree index bloat with pgstatindex.
+// Leaf pages are expected to be filled up to the fillfactor.
+var indexBloatPreciseQuery = "select i.indrelid,i.indexrelid,n.nspname,t.relname,c.relname,s.index_size," +
+	"case when s.leaf_pages>0 then greatest(s.index_size*(1-s.avg_leaf_density/coalesce(substring(" +
+	"array_to_string(c.reloptions,' ') from 'fillfactor=([0-9]+)')::smallint,90)),0)::bigint else 0 end " +
+	"from pg_index i join pg_class c on c.oid=i.indexrelid join pg_class t on t.oid=i.indrelid " +
+	"join pg_namespace n on n.oid=c.relnamespace," +
+	"lateral pgstatindex(quote_ident(n.nspname)||'.'||quote_ident(c.relname)) s " +
+	"where c.relam=(select oid from pg_am where amname='btree') and i.indisvalid " +
+	"and c.relpersistence<>'t' and " + userSchemas("n")
+
+// bloatOf returns bloat as a fraction of the size of the relation (zero if it is empty) and whether it can be relied on:
+// measurements always can, estimates only if they are not based on unsupported columns (isNA)
+func bloatOf(size int64, bloatSize int64, precise bool, isNA bool) (float64, bool) {
+	var ratio float64
+	if size > 0 {
+		ratio = float64(bloatSize) / float64(size)
+	}
+	return ratio, precise || !isNA
+}
+
+func (s *PgStats) hasExtension(name string) (bool, error) {
+	db := s.db()
+	query := "select exists(select 1 from pg_extension where extname=$1)"
+	row := db.QueryRow(query, name)
+	var installed bool
+	err := row.Scan(&installed)
+	return installed, err
+}
+
+func (s *PgStats) fetchTableBloat(precise bool) (TableBloatView, error) {
+	if precise {
+		installed, err := s.hasExtension("pgstattuple")
+		if err != nil {
+			return nil, err
+		}
+		if installed {
+			return s.fetchTableBloatPrecise()
+		}
+	}
+
+	db := s.db()
+	rows, err := db.Query(tableBloatEstimateQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make(TableBloatView, 0)
+	for rows.Next() {
+		row := new(TableBloatRow)
+		var isNA bool
+		err := rows.Scan(&row.Relid, &row.Schemaname, &row.Relname, &row.Size, &row.BloatSize, &isNA)
+		if err != nil {
+			return nil, err
+		}
+		row.BloatRatio, row.Reliable = bloatOf(row.Size, row.BloatSize, false, isNA)
+		data = append(data, *row)
+	}
+	return data, rows.Err()
+}
+
+func (s *PgStats) fetchTableBloatPrecise() (TableBloatView, error) {
+	db := s.db()
+	rows, err := db.Query(tableBloatPreciseQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make(TableBloatView, 0)
+	for rows.Next() {
+		row := &TableBloatRow{Precise: true}
+		err := rows.Scan(&row.Relid, &row.Schemaname, &row.Relname, &row.Size, &row.BloatSize)
+		if err != nil {
+			return nil, err
+		}
+		row.BloatRatio, row.Reliable = bloatOf(row.Size, row.BloatSize, true, false)
+		data = append(data, *row)
+	}
+	return data, rows.Err()
+}
+
+func (s *PgStats) fetchIndexBloat(precise bool) (IndexBloatView, error) {
+	if precise {
+		installed, err := s.hasExtension("pgstattuple")
+		if err != nil {
+			return nil, err
+		}
+		if installed {
+			return s.fetchIndexBloatPrecise()
+		}
+	}
+
+	db := s.db()
+	rows, err := db.Query(indexBloatEstimateQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make(IndexBloatView, 0)
+	for rows.Next() {
+		row := new(IndexBloatRow)
+		var isNA bool
+		err := rows.Scan(&row.Relid, &row.Indexrelid, &row.Schemaname, &row.Relname, &row.Indexrelname,
+			&row.Size, &row.BloatSize, &isNA)
+		if err != nil {
+			return nil, err
+		}
+		row.BloatRatio, row.Reliable = bloatOf(row.Size, row.BloatSize, false, isNA)
+		data = append(data, *row)
+	}
+	return data, rows.Err()
+}
+
+func (s *PgStats) fetchIndexBloatPrecise() (IndexBloatView, error) {
+	db := s.db()
+	rows, err := db.Query(indexBloatPreciseQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make(IndexBloatView, 0)
+	for rows.Next() {
+		row := &IndexBloatRow{Precise: true}
+		err := rows.Scan(&row.Relid, &row.Indexrelid, &row.Schemaname, &row.Relname, &row.Indexrelname,
+			&row.Size, &row.BloatSize)
+		if err != nil {
+			return nil, err
+		}
+		row.BloatRatio, row.Reliable = bloatOf(row.Size, row.BloatSize, true, false)
+		data = append(data, *row)
+	}
+	return data, rows.Err()
+}
@@ -0,0 +1,29 @@
+package pgstats
+
+import "testing"
+
+var bloatTests = []struct {
+	name      string
+	size      int64
+	bloatSize int64
+	precise   bool
+	isNA      bool
+	ratio     float64
+	reliable  bool
+}{
+	{"estimated", 8192 * 100, 8192 * 25, false, false, 0.25, true},
+	{"estimated with name columns", 8192 * 100, 8192 * 25, false, true, 0.25, false},
+	{"measured", 1000, 100, true, false, 0.1, true},
+	{"measured ignores statistics", 1000, 100, true, true, 0.1, true},
+	{"empty", 0, 0, false, false, 0, true},
+	{"not bloated", 8192, 0, true, false, 0, true},
+}
+
+func TestBloatOf(t *testing.T) {
+	for _, tt := range bloatTests {
+		ratio, reliable := bloatOf(tt.size, tt.bloatSize, tt.precise, tt.isNA)
+		if ratio != tt.ratio || reliable != tt.reliable {
+			t.Errorf("%s: expected ratio %f and reliable %t; actual %f and %t", tt.name, tt.ratio, tt.reliable, ratio, reliable)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+// Command pgstats prints any view supported by the pgstats library.
+//
+// Usage:
+//
+//	pgstats [connection flags] <view> [--format table|json|csv|tsv] [--sort col] [--limit n] [--watch 2s]
+//	pgstats [connection flags] top [--watch 2s]
+//	pgstats list
+//
+// The top mode shows a live, refreshing screen with activity, top statements, table I/O and replication,
+// allowing to sort, filter, and cancel or terminate backends.
+//
+// Connection flags default to the libpq environment variables:
+// PGHOST, PGPORT, PGDATABASE, PGUSER, PGPASSWORD, PGSSLMODE, PGCONNECT_TIMEOUT,
+// PGSSLCERT, PGSSLKEY and PGSSLROOTCERT. The password can be given only in the environment.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/vynaloze/pgstats"
+	"github.com/vynaloze/pgstats/pgstatshttp"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type options struct {
+	host           string
+	port           int
+	dbname         string
+	user           string
+	sslmode        string
+	connectTimeout int
+	sslcert        string
+	sslkey         string
+	sslrootcert    string
+
+	format string
+	sort   string
+	limit  int
+	watch  time.Duration
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	o := &options{}
+	fs := newFlagSet(o, stderr)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	view := fs.Arg(0)
+	// flags may follow the view as well
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "Unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		return 2
+	}
+	o.resolveDefaults()
+
+	if view == "list" {
+		for _, name := range pgstats.Views() {
+			fmt.Fprintln(stdout, name)
+		}
+		return 0
+	}
+	if alias, ok := pgstatshttp.Aliases[view]; ok {
+		view = alias
+	}
+	if view == "top" {
+		return top(o, stderr)
+	}
+	if _, err := pgstats.ViewType(view); err != nil {
+		fmt.Fprintf(stderr, "%v. Run 'pgstats list' for all views.\n", err)
+		return 2
+	}
+	out, err := newOutput(o.format, o.sort, o.limit)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	stats, err := connect(o)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	defer stats.Close()
+
+	if o.watch <= 0 {
+		if err := show(stats, view, out, stdout); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		return 0
+	}
+	return watch(stats, view, out, o.watch, stdout, stderr)
+}
+
+func top(o *options, stderr io.Writer) int {
+	interval := o.watch
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	stats, err := connect(o)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	defer stats.Close()
+	if err := runTop(stats, interval, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func newFlagSet(o *options, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("pgstats", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&o.host, "host", env("PGHOST", "localhost"), "database server host or socket directory")
+	fs.IntVar(&o.port, "port", envInt("PGPORT", 5432), "database server port")
+	fs.StringVar(&o.dbname, "dbname", env("PGDATABASE", ""), "database name to connect to (default the user name)")
+	fs.StringVar(&o.user, "username", env("PGUSER", "postgres"), "database user name")
+	fs.StringVar(&o.sslmode, "sslmode", env("PGSSLMODE", "require"), "SSL mode: disable, require, verify-ca or verify-full")
+	fs.IntVar(&o.connectTimeout, "connect-timeout", envInt("PGCONNECT_TIMEOUT", 0), "maximum wait for connection, in seconds")
+	fs.StringVar(&o.sslcert, "sslcert", env("PGSSLCERT", ""), "client certificate file")
+	fs.StringVar(&o.sslkey, "sslkey", env("PGSSLKEY", ""), "client key file")
+	fs.StringVar(&o.sslrootcert, "sslrootcert", env("PGSSLROOTCERT", ""), "root certificate file")
+	fs.StringVar(&o.format, "format", "table", "output format: table, json, csv or tsv")
+	fs.StringVar(&o.sort, "sort", "", "column to sort rows by, descending if prefixed with -")
+	fs.IntVar(&o.limit, "limit", 0, "maximum number of rows, 0 for all")
+	fs.DurationVar(&o.watch, "watch", 0, "refresh interval, e.g. 2s; 0 prints the view once (top refreshes every 2s)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: pgstats [flags] <view> [flags]")
+		fmt.Fprintln(stderr, "       pgstats [flags] top")
+		fmt.Fprintln(stderr, "       pgstats list")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// resolveDefaults fills the options which default to other options, once all flags have been parsed
+func (o *options) resolveDefaults() {
+	// like libpq, the database name defaults to the user name, whether given in the environment or as a flag
+	if o.dbname == "" {
+		o.dbname = o.user
+	}
+}
+
+func connect(o *options) (*pgstats.PgStats, error) {
+	options := []pgstats.Option{
+		pgstats.Host(o.host),
+		pgstats.Port(o.port),
+		pgstats.SslMode(o.sslmode),
+		pgstats.FallbackApplicationName("pgstats"),
+	}
+	if o.connectTimeout > 0 {
+		options = append(options, pgstats.ConnectTimeout(o.connectTimeout))
+	}
+	if o.sslcert != "" {
+		options = append(options, pgstats.SslCert(o.sslcert))
+	}
+	if o.sslkey != "" {
+		options = append(options, pgstats.SslKey(o.sslkey))
+	}
+	if o.sslrootcert != "" {
+		options = append(options, pgstats.SslRootCert(o.sslrootcert))
+	}
+	return pgstats.Connect(o.dbname, o.user, os.Getenv("PGPASSWORD"), options...)
+}
+
+func show(stats *pgstats.PgStats, view string, out *output, w io.Writer) error {
+	v, err := stats.Fetch(view)
+	if err != nil {
+		return err
+	}
+	return out.write(w, v)
+}
+
+// watch shows the view every interval until interrupted. Tables are redrawn on a cleared screen.
+func watch(stats *pgstats.PgStats, view string, out *output, interval time.Duration, stdout io.Writer, stderr io.Writer) int {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if out.format == "table" {
+			fmt.Fprint(stdout, "\033[H\033[2J")
+			fmt.Fprintf(stdout, "Every %v: %s    %s\n\n", interval, view, time.Now().Format("2006-01-02 15:04:05"))
+		}
+		if err := show(stats, view, out, stdout); err != nil {
+			fmt.Fprintln(stderr, err)
+		}
+		select {
+		case <-interrupt:
+			return 0
+		case <-ticker.C:
+		}
+	}
+}
+
+func env(name string, fallback string) string {
+	if value, ok := os.LookupEnv(name); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func envInt(name string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/vynaloze/pgstats/tabular"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// output renders views in the requested format, order and number of rows
+type output struct {
+	format     string
+	sort       string
+	descending bool
+	limit      int
+}
+
+func newOutput(format string, sortBy string, limit int) (*output, error) {
+	switch format {
+	case "table", "json", "csv", "tsv":
+	default:
+		return nil, errors.Errorf("Unknown format: %s. Allowed values: table, json, csv, tsv", format)
+	}
+	if limit < 0 {
+		return nil, errors.Errorf("Invalid limit: %d", limit)
+	}
+	return &output{
+		format:     format,
+		sort:       strings.TrimPrefix(sortBy, "-"),
+		descending: strings.HasPrefix(sortBy, "-"),
+		limit:      limit,
+	}, nil
+}
+
+// table represents a view as columns named after json tags and rows of values (nil for nulls)
+type table struct {
+	columns []string
+	rows    [][]interface{}
+	// view the table has been built from; for slices, items are reordered together with rows
+	view reflect.Value
+}
+
+func (o *output) write(w io.Writer, v interface{}) error {
+	t := toTable(v)
+	if err := o.arrange(t); err != nil {
+		return err
+	}
+	switch o.format {
+	case "json":
+		return writeJSON(w, t.view)
+	case "csv":
+		return tabular.NewEncoder(w, tabular.CSV).EncodeRows(t.columns, t.rows)
+	case "tsv":
+		return tabular.NewEncoder(w, tabular.TSV).EncodeRows(t.columns, t.rows)
+	}
+	return tabular.NewEncoder(w, tabular.Aligned).EncodeRows(t.columns, t.rows)
+}
+
+func toTable(v interface{}) *table {
+	t := &table{view: reflect.ValueOf(v)}
+	for t.view.Kind() == reflect.Ptr && !t.view.IsNil() {
+		t.view = t.view.Elem()
+	}
+	t.columns, t.rows = tabular.Rows(v)
+	return t
+}
+
+// arrange sorts and limits rows of the table
+func (o *output) arrange(t *table) error {
+	if o.sort != "" {
+		column := -1
+		for i, name := range t.columns {
+			if name == o.sort {
+				column = i
+			}
+		}
+		if column < 0 {
+			return errors.Errorf("Unknown column: %s", o.sort)
+		}
+		order := make([]int, len(t.rows))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			a, b := t.rows[order[i]][column], t.rows[order[j]][column]
+			c := compare(a, b)
+			if o.descending && a != nil && b != nil {
+				return c > 0
+			}
+			return c < 0
+		})
+		t.reorder(order)
+	}
+	if o.limit > 0 && o.limit < len(t.rows) {
+		t.rows = t.rows[:o.limit]
+		if t.view.Kind() == reflect.Slice {
+			t.view = t.view.Slice(0, o.limit)
+		}
+	}
+	return nil
+}
+
+// reorder puts rows (and items of the view, if it is a slice) in given order of their indexes
+func (t *table) reorder(order []int) {
+	rows := make([][]interface{}, len(order))
+	for i, index := range order {
+		rows[i] = t.rows[index]
+	}
+	t.rows = rows
+	if t.view.Kind() != reflect.Slice {
+		return
+	}
+	view := reflect.MakeSlice(t.view.Type(), len(order), len(order))
+	for i, index := range order {
+		view.Index(i).Set(t.view.Index(index))
+	}
+	t.view = view
+}
+
+// compare orders values: numbers numerically, times chronologically, everything else by text; nulls are last
+func compare(a interface{}, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return number(float64(ta.UnixNano()), float64(tb.UnixNano()))
+		}
+	}
+	if fa, ok := toFloat(a); ok {
+		if fb, ok := toFloat(b); ok {
+			return number(fa, fb)
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func number(a float64, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	r := reflect.ValueOf(v)
+	switch r.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(r.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(r.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return r.Float(), true
+	}
+	return 0, false
+}
+
+func writeJSON(w io.Writer, view reflect.Value) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if !view.IsValid() {
+		return encoder.Encode(nil)
+	}
+	return encoder.Encode(view.Interface())
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"github.com/vynaloze/pgstats"
+	"github.com/vynaloze/pgstats/nullable"
+	"os"
+	"strings"
+	"testing"
+)
+
+var databases = pgstats.PgStatDatabaseView{
+	{Datid: 1, Datname: "app", NumBackends: 3, XactCommit: nullable.Int64{NullInt64: sql.NullInt64{Int64: 10, Valid: true}}},
+	{Datid: 2, Datname: "other", NumBackends: 7},
+	{Datid: 3, Datname: "template1", NumBackends: 0, XactCommit: nullable.Int64{NullInt64: sql.NullInt64{Int64: 20, Valid: true}}},
+}
+
+func render(t *testing.T, format string, sortBy string, limit int) string {
+	out, err := newOutput(format, sortBy, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := out.write(&buf, databases); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestOutputCSV(t *testing.T) {
+	lines := strings.Split(render(t, "csv", "-xact_commit", 2), "\n")
+	if !strings.HasPrefix(lines[0], "datid,datname,numbackends,xact_commit,") {
+		t.Errorf("Unexpected header: %s", lines[0])
+	}
+	if len(lines) != 4 || !strings.HasPrefix(lines[1], "3,template1,0,20,") || !strings.HasPrefix(lines[2], "1,app,3,10,") {
+		t.Errorf("Expected two rows with most commits; actual %v", lines)
+	}
+}
+
+func TestOutputTable(t *testing.T) {
+	actual := render(t, "table", "xact_commit", 0)
+	lines := strings.Split(actual, "\n")
+	if !strings.HasPrefix(lines[0], " datid |  datname  | numbackends | xact_commit |") {
+		t.Errorf("Unexpected header: %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[4], "     2 | other     |           7 |             |") || lines[5] != "(3 rows)" {
+		t.Errorf("Expected nulls last; actual:\n%s", actual)
+	}
+}
+
+func TestOutputJSON(t *testing.T) {
+	actual := render(t, "json", "-numbackends", 1)
+	if !strings.Contains(actual, `"datname": "other"`) || strings.Contains(actual, `"app"`) {
+		t.Errorf("Expected only the database with most backends; actual:\n%s", actual)
+	}
+}
+
+func TestOutputErrors(t *testing.T) {
+	if _, err := newOutput("xml", "", 0); err == nil {
+		t.Error("Expected unknown format error")
+	}
+	out, _ := newOutput("csv", "nothing", 0)
+	if err := out.write(&bytes.Buffer{}, databases); err == nil {
+		t.Error("Expected unknown column error")
+	}
+}
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"list"}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "pg_stat_database\n") {
+		t.Errorf("Expected list of views; actual %d: %s%s", code, stdout.String(), stderr.String())
+	}
+	if code := run([]string{"pg_stat_nothing"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected usage error for unknown view; actual %d", code)
+	}
+	if code := run([]string{"tables", "--format", "xml"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected usage error for unknown format; actual %d", code)
+	}
+	if code := run([]string{"tables", "extra"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected usage error for extra arguments; actual %d", code)
+	}
+}
+
+func TestDbnameDefaultsToUsername(t *testing.T) {
+	defer os.Setenv("PGDATABASE", os.Getenv("PGDATABASE"))
+	os.Unsetenv("PGDATABASE")
+	o := &options{}
+	fs := newFlagSet(o, &bytes.Buffer{})
+	if err := fs.Parse([]string{"--username", "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	o.resolveDefaults()
+	if o.dbname != "alice" {
+		t.Errorf("Expected dbname alice; actual %s", o.dbname)
+	}
+	o = &options{}
+	fs = newFlagSet(o, &bytes.Buffer{})
+	if err := fs.Parse([]string{"--username", "alice", "--dbname", "app"}); err != nil {
+		t.Fatal(err)
+	}
+	o.resolveDefaults()
+	if o.dbname != "app" {
+		t.Errorf("Expected dbname app; actual %s", o.dbname)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// The terminal is controlled with stty(1), which keeps the command free of platform-specific dependencies.
+
+// isTerminal reports whether f is a character device, e.g. an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// makeRaw puts the terminal f into raw mode and returns a function restoring its previous state
+func makeRaw(f *os.File) (func(), error) {
+	state, err := stty(f, "-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty(f, "raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { stty(f, state) }, nil
+}
+
+// terminalSize returns the number of columns and rows of the terminal f
+func terminalSize(f *os.File) (int, int, error) {
+	size, err := stty(f, "size")
+	if err != nil {
+		return 0, 0, err
+	}
+	var width, height int
+	if _, err := fmt.Sscan(size, &height, &width); err != nil {
+		return 0, 0, err
+	}
+	return width, height, nil
+}
+
+// stty runs stty with the terminal f as its standard input and returns its trimmed output
+func stty(f *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = f
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
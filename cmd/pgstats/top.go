@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/vynaloze/pgstats"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// topCollector fetches data shown on the top screen, keeping previous snapshots to compute deltas
+type topCollector struct {
+	stats      *pgstats.PgStats
+	interval   time.Duration
+	statements pgstats.PgStatStatementsView
+	tables     pgstats.PgStatIoUserTablesView
+	collected  bool
+}
+
+func (c *topCollector) collect() topData {
+	data := topData{time: time.Now(), interval: c.interval, ready: c.collected, errors: make(map[string]error)}
+	var err error
+	if data.activity, err = c.stats.PgStatActivity(); err != nil {
+		data.errors["activity"] = err
+	}
+
+	statements, err := c.stats.PgStatStatements()
+	if err != nil {
+		data.errors["statements"] = errors.Wrap(err, "pg_stat_statements is not available")
+	} else if c.statements != nil {
+		delta := statements.Delta(c.statements)
+		data.statements = &delta
+	}
+	c.statements = statements
+
+	tables, err := c.stats.PgStatIoUserTables()
+	if err != nil {
+		data.errors["tables"] = err
+	} else {
+		data.tables = tablesIO(tables, c.tables)
+	}
+	c.tables = tables
+
+	if data.replication, err = c.stats.ReplicationLag(); err != nil {
+		data.errors["replication"] = err
+	}
+	c.collected = true
+	return data
+}
+
+// runTop shows the interactive top screen until the user quits
+func runTop(stats *pgstats.PgStats, interval time.Duration, stdin *os.File, stdout *os.File) error {
+	if !isTerminal(stdin) {
+		return errors.New("top requires an interactive terminal")
+	}
+	restore, err := makeRaw(stdin)
+	if err != nil {
+		return err
+	}
+	defer restore()
+	// switch to the alternate screen and hide the cursor, restoring both on exit
+	fmt.Fprint(stdout, "\033[?1049h\033[?25l")
+	defer fmt.Fprint(stdout, "\033[?25h\033[?1049l")
+
+	keys := make(chan string)
+	go readKeys(stdin, keys)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// data is collected in the background, so that keys are handled while the server is slow to respond
+	collector := &topCollector{stats: stats, interval: interval}
+	results := make(chan topData, 1)
+	collecting := false
+	collect := func() {
+		if !collecting {
+			collecting = true
+			go func() { results <- collector.collect() }()
+		}
+	}
+	collect()
+	m := &topModel{data: topData{time: time.Now(), interval: interval}}
+	for {
+		width, height, err := terminalSize(stdin)
+		if err != nil {
+			width, height = 120, 40
+		}
+		fmt.Fprint(stdout, "\033[H"+strings.Join(m.render(width, height), "\033[K\r\n")+"\033[J")
+
+		select {
+		case m.data = <-results:
+			collecting = false
+		case <-ticker.C:
+			collect()
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			action, target := m.handleKey(key)
+			switch action {
+			case actionQuit:
+				return nil
+			case actionRefresh:
+				collect()
+			case actionCancel:
+				m.message = signalResult("Cancelled query of", target, stats.CancelBackendStartedAt)
+			case actionTerminate:
+				m.message = signalResult("Terminated", target, stats.TerminateBackendStartedAt)
+			}
+		}
+	}
+}
+
+func signalResult(verb string, target topTarget, signal func(pid int64, backendStart time.Time) (bool, error)) string {
+	ok, err := signal(target.pid, target.backendStart)
+	switch {
+	case err != nil:
+		return err.Error()
+	case !ok:
+		return fmt.Sprintf("Backend %d not found (it may have exited)", target.pid)
+	}
+	return fmt.Sprintf("%s backend %d", verb, target.pid)
+}
+
+// readKeys reads key presses from the terminal in raw mode until it is closed
+func readKeys(r io.Reader, keys chan<- string) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := r.Read(buf)
+		for _, key := range parseKeys(buf[:n]) {
+			keys <- key
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// parseKeys names keys read from the terminal: arrows, escape, enter, backspace and ctrl-c;
+// other keys are returned as they are
+func parseKeys(input []byte) []string {
+	keys := make([]string, 0, len(input))
+	for i := 0; i < len(input); i++ {
+		switch b := input[i]; {
+		case b == 0x1b && i+2 < len(input) && input[i+1] == '[':
+			switch input[i+2] {
+			case 'A':
+				keys = append(keys, "up")
+			case 'B':
+				keys = append(keys, "down")
+			}
+			i += 2
+		case b == 0x1b:
+			keys = append(keys, "esc")
+		case b == 0x03:
+			keys = append(keys, "ctrl-c")
+		case b == '\r' || b == '\n':
+			keys = append(keys, "enter")
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, "backspace")
+		default:
+			keys = append(keys, string(b))
+		}
+	}
+	return keys
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"database/sql"
+	"github.com/lib/pq"
+	"github.com/vynaloze/pgstats"
+	"github.com/vynaloze/pgstats/nullable"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func backend(pid int64, state string, started time.Duration, query string) pgstats.PgStatActivityRow {
+	return pgstats.PgStatActivityRow{
+		Pid:          pid,
+		State:        nullable.String{NullString: sql.NullString{String: state, Valid: true}},
+		QueryStart:   nullable.Time{NullTime: pq.NullTime{Time: now.Add(-started), Valid: true}},
+		BackendStart: nullable.Time{NullTime: pq.NullTime{Time: now.Add(-time.Duration(pid) * time.Hour), Valid: true}},
+		Query:        nullable.String{NullString: sql.NullString{String: query, Valid: true}},
+	}
+}
+
+func newTopModel() *topModel {
+	return &topModel{data: topData{time: now, interval: 2 * time.Second, activity: pgstats.PgStatActivityView{
+		backend(10, "active", time.Second, "select 1"),
+		backend(11, "idle", time.Hour, "commit"),
+		backend(12, "active", time.Minute, "update orders set paid = true"),
+		backend(13, "idle in transaction", 5*time.Second, "select * from orders"),
+	}}}
+}
+
+func pids(rows []pgstats.PgStatActivityRow) []int64 {
+	result := make([]int64, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, r.Pid)
+	}
+	return result
+}
+
+func TestTopVisibleActivity(t *testing.T) {
+	m := newTopModel()
+	if actual := pids(m.visibleActivity()); !reflect.DeepEqual(actual, []int64{12, 13, 10}) {
+		t.Errorf("Expected non-idle backends by query age; actual %v", actual)
+	}
+	m.handleKey("i")
+	m.handleKey("s")
+	m.handleKey("s")
+	if actual := pids(m.visibleActivity()); !reflect.DeepEqual(actual, []int64{10, 11, 12, 13}) {
+		t.Errorf("Expected all backends by pid; actual %v", actual)
+	}
+	for _, key := range []string{"/", "o", "r", "x", "backspace", "d", "e", "r", "s", "enter"} {
+		m.handleKey(key)
+	}
+	if m.filter != "orders" {
+		t.Errorf("Expected filter orders; actual %s", m.filter)
+	}
+	if actual := pids(m.visibleActivity()); !reflect.DeepEqual(actual, []int64{12, 13}) {
+		t.Errorf("Expected backends querying orders; actual %v", actual)
+	}
+	m.handleKey("/")
+	m.handleKey("esc")
+	if m.filter != "" || m.mode != modeNormal {
+		t.Errorf("Expected filter cleared; actual %q", m.filter)
+	}
+}
+
+func TestTopHandleKey(t *testing.T) {
+	m := newTopModel()
+	if action, _ := m.handleKey("c"); action != actionNone || m.mode != modeNormal || m.message == "" {
+		t.Errorf("Expected no cancel without selection; actual action %d, mode %d", action, m.mode)
+	}
+	m.handleKey("down")
+	m.handleKey("down")
+	m.handleKey("up")
+	m.handleKey("j")
+	if m.selected != 13 {
+		t.Errorf("Expected backend 13 selected; actual %d", m.selected)
+	}
+	m.handleKey("t")
+	if action, target := m.handleKey("y"); action != actionTerminate || target.pid != 13 || !target.backendStart.Equal(now.Add(-13*time.Hour)) {
+		t.Errorf("Expected terminate of 13 started 13 hours ago; actual action %d, target %+v", action, target)
+	}
+	m.handleKey("c")
+	if action, _ := m.handleKey("n"); action != actionNone || m.message != "Aborted" {
+		t.Errorf("Expected cancel aborted; actual action %d, message %s", action, m.message)
+	}
+	if action, _ := m.handleKey("r"); action != actionRefresh {
+		t.Errorf("Expected refresh; actual %d", action)
+	}
+	if action, _ := m.handleKey("ctrl-c"); action != actionQuit {
+		t.Errorf("Expected quit; actual %d", action)
+	}
+}
+
+func TestTopRender(t *testing.T) {
+	m := newTopModel()
+	for _, size := range [][2]int{{80, 24}, {200, 60}, {20, 8}} {
+		lines := m.render(size[0], size[1])
+		if len(lines) != size[1] {
+			t.Errorf("Expected %d lines; actual %d", size[1], len(lines))
+		}
+		for _, line := range lines {
+			if len([]rune(line)) > size[0] {
+				t.Errorf("Expected lines of at most %d characters; actual %q", size[0], line)
+			}
+		}
+	}
+}
+
+func TestTablesIO(t *testing.T) {
+	int64Of := func(v int64) nullable.Int64 {
+		return nullable.Int64{NullInt64: sql.NullInt64{Int64: v, Valid: true}}
+	}
+	previous := pgstats.PgStatIoUserTablesView{
+		{Relid: 1, Schemaname: "public", Relname: "orders", HeapBlksRead: int64Of(10), HeapBlksHit: int64Of(100)},
+		{Relid: 2, Schemaname: "public", Relname: "users", HeapBlksRead: int64Of(5), HeapBlksHit: int64Of(50)},
+	}
+	current := pgstats.PgStatIoUserTablesView{
+		{Relid: 1, Schemaname: "public", Relname: "orders", HeapBlksRead: int64Of(12), HeapBlksHit: int64Of(300)},
+		{Relid: 2, Schemaname: "public", Relname: "users", HeapBlksRead: int64Of(15), HeapBlksHit: int64Of(50)},
+		{Relid: 3, Schemaname: "public", Relname: "new", HeapBlksRead: int64Of(99)},
+	}
+	expected := []tableIO{
+		{name: "public.users", heapRead: 10},
+		{name: "public.orders", heapRead: 2, heapHit: 200},
+	}
+	if actual := tablesIO(current, previous); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v; actual %v", expected, actual)
+	}
+	if actual := tablesIO(current, nil); len(actual) != 0 {
+		t.Errorf("Expected no tables without previous snapshot; actual %v", actual)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	expected := []string{"up", "down", "esc", "enter", "backspace", "ctrl-c", "q"}
+	if actual := parseKeys([]byte("\033[A\033[B\033\r\x7f\x03q")); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v; actual %v", expected, actual)
+	}
+}
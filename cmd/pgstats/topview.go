@@ -0,0 +1,439 @@
+package main
+
+import (
+	"fmt"
+	"github.com/vynaloze/pgstats"
+	"github.com/vynaloze/pgstats/nullable"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// topData holds everything shown on a single refresh of the top screen
+type topData struct {
+	time       time.Time
+	interval   time.Duration
+	activity   pgstats.PgStatActivityView
+	statements *pgstats.PgStatStatementsDelta
+	tables     []tableIO
+	// ready is false until two snapshots have been taken, so that deltas are known
+	ready       bool
+	replication pgstats.ReplicationLagView
+	// errors by panel
+	errors map[string]error
+}
+
+// tableIO represents blocks read and hit in a single table within the refresh interval
+type tableIO struct {
+	name     string
+	heapRead int64
+	heapHit  int64
+	idxRead  int64
+	idxHit   int64
+}
+
+// actions returned by key handling
+const (
+	actionNone = iota
+	actionQuit
+	actionRefresh
+	actionCancel
+	actionTerminate
+)
+
+// modes of key handling
+const (
+	modeNormal = iota
+	modeFilter
+	modeConfirm
+)
+
+// topTarget identifies the backend an action is taken on. The process ID may be reused by a new backend,
+// so the start time of the backend seen on the screen is kept as well.
+type topTarget struct {
+	pid          int64
+	backendStart time.Time
+}
+
+var activitySorts = []string{"query age", "xact age", "pid", "state", "wait"}
+
+var statementSorts = []string{"time", "calls", "blocks read", "temp blocks"}
+
+// topModel holds the state of the top screen: data, ordering, filter and selected backend
+type topModel struct {
+	data          topData
+	activitySort  int
+	statementSort int
+	showIdle      bool
+	filter        string
+	mode          int
+	input         string
+	selected      int64
+	confirm       int
+	target        topTarget
+	message       string
+}
+
+// visibleActivity returns backends matching the filter, in the chosen order
+func (m *topModel) visibleActivity() []pgstats.PgStatActivityRow {
+	filter := strings.ToLower(m.filter)
+	rows := make([]pgstats.PgStatActivityRow, 0, len(m.data.activity))
+	for _, a := range m.data.activity {
+		if !m.showIdle && (a.State.String == "idle" || !a.State.Valid) {
+			continue
+		}
+		text := strings.ToLower(strings.Join([]string{a.Usename.String, a.Datname.String, a.ApplicationName.String,
+			a.State.String, a.WaitEventType.String, a.WaitEvent.String, a.Query.String, strconv.FormatInt(a.Pid, 10)}, " "))
+		if filter != "" && !strings.Contains(text, filter) {
+			continue
+		}
+		rows = append(rows, a)
+	}
+	now := m.data.time
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch activitySorts[m.activitySort] {
+		case "xact age":
+			return age(now, a.XactStart) > age(now, b.XactStart)
+		case "pid":
+			return a.Pid < b.Pid
+		case "state":
+			return a.State.String < b.State.String
+		case "wait":
+			return waitEvent(a) > waitEvent(b)
+		}
+		return age(now, a.QueryStart) > age(now, b.QueryStart)
+	})
+	return rows
+}
+
+// handleKey updates the model according to the pressed key and returns the action to take and its target
+func (m *topModel) handleKey(key string) (int, topTarget) {
+	switch m.mode {
+	case modeFilter:
+		switch key {
+		case "enter":
+			m.filter, m.mode = m.input, modeNormal
+		case "esc":
+			m.filter, m.mode = "", modeNormal
+		case "backspace":
+			if m.input != "" {
+				m.input = m.input[:len(m.input)-1]
+			}
+		default:
+			if len(key) == 1 && key[0] >= ' ' && key[0] <= '~' {
+				m.input += key
+			}
+		}
+		return actionNone, topTarget{}
+	case modeConfirm:
+		m.mode = modeNormal
+		if key == "y" {
+			return m.confirm, m.target
+		}
+		m.message = "Aborted"
+		return actionNone, topTarget{}
+	}
+
+	m.message = ""
+	switch key {
+	case "q", "ctrl-c":
+		return actionQuit, topTarget{}
+	case "r":
+		return actionRefresh, topTarget{}
+	case "up", "k":
+		m.move(-1)
+	case "down", "j":
+		m.move(1)
+	case "s":
+		m.activitySort = (m.activitySort + 1) % len(activitySorts)
+	case "o":
+		m.statementSort = (m.statementSort + 1) % len(statementSorts)
+	case "i":
+		m.showIdle = !m.showIdle
+	case "/":
+		m.mode, m.input = modeFilter, m.filter
+	case "c", "t":
+		if m.selected == 0 {
+			m.message = "Select a backend first (up/down)"
+			break
+		}
+		for _, a := range m.data.activity {
+			if a.Pid == m.selected {
+				m.target = topTarget{pid: a.Pid, backendStart: a.BackendStart.Time}
+			}
+		}
+		m.mode, m.confirm = modeConfirm, actionCancel
+		if key == "t" {
+			m.confirm = actionTerminate
+		}
+	}
+	return actionNone, topTarget{}
+}
+
+// move moves the selection by delta rows of visible backends
+func (m *topModel) move(delta int) {
+	rows := m.visibleActivity()
+	if len(rows) == 0 {
+		m.selected = 0
+		return
+	}
+	index := -1
+	for i, r := range rows {
+		if r.Pid == m.selected {
+			index = i
+		}
+	}
+	index += delta
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(rows) {
+		index = len(rows) - 1
+	}
+	m.selected = rows[index].Pid
+}
+
+// render returns lines of the screen of given size
+func (m *topModel) render(width int, height int) []string {
+	lines := make([]string, 0, height)
+	add := func(line string) {
+		lines = append(lines, fit(line, width))
+	}
+	add(fmt.Sprintf("pgstats top - %s, refresh every %v, %d backends", m.data.time.Format("15:04:05"), m.data.interval, len(m.data.activity)))
+	switch m.mode {
+	case modeFilter:
+		add("Filter: " + m.input + "_")
+	case modeConfirm:
+		verb := "Cancel query of"
+		if m.confirm == actionTerminate {
+			verb = "Terminate"
+		}
+		add(fmt.Sprintf("%s backend %d? (y/n)", verb, m.target.pid))
+	default:
+		if m.message != "" {
+			add(m.message)
+		} else {
+			add("q quit  up/down select  c cancel  t terminate  s sort  o order statements  i idle  / filter  r refresh")
+		}
+	}
+
+	body := height - len(lines)
+	sizes := []int{body * 40 / 100, body * 25 / 100, body * 20 / 100}
+	sizes = append(sizes, body-sizes[0]-sizes[1]-sizes[2])
+	panels := [][]string{m.activityPanel(), m.statementsPanel(), m.tablesPanel(), m.replicationPanel()}
+	for i, panel := range panels {
+		panel = scroll(panel, sizes[i])
+		for j := 0; j < sizes[i]; j++ {
+			if j < len(panel) {
+				add(panel[j])
+			} else {
+				add("")
+			}
+		}
+	}
+	return lines
+}
+
+// scroll keeps the title and the header of the panel, and scrolls its rows so that the selected one is visible
+func scroll(panel []string, size int) []string {
+	const fixed = 2
+	if len(panel) <= size || size <= fixed {
+		return panel
+	}
+	for i := fixed; i < len(panel); i++ {
+		if strings.HasPrefix(panel[i], "> ") && i >= size {
+			offset := i - size + 1
+			return append(panel[:fixed:fixed], panel[fixed+offset:]...)
+		}
+	}
+	return panel
+}
+
+func (m *topModel) activityPanel() []string {
+	title := fmt.Sprintf("== Activity (sorted by %s", activitySorts[m.activitySort])
+	if m.filter != "" {
+		title += ", filter: " + m.filter
+	}
+	if !m.showIdle {
+		title += ", idle hidden"
+	}
+	lines := []string{title + ") =="}
+	if err := m.data.errors["activity"]; err != nil {
+		return append(lines, err.Error())
+	}
+	lines = append(lines, fmt.Sprintf("  %-7s %-12s %-12s %-14s %-20s %-22s %8s %8s %s",
+		"PID", "USER", "DATABASE", "APPLICATION", "STATE", "WAIT", "XACT", "QUERY", "QUERY TEXT"))
+	for _, a := range m.visibleActivity() {
+		marker := "  "
+		if a.Pid == m.selected {
+			marker = "> "
+		}
+		lines = append(lines, fmt.Sprintf("%s%-7d %-12s %-12s %-14s %-20s %-22s %8s %8s %s", marker, a.Pid,
+			fit(a.Usename.String, 12), fit(a.Datname.String, 12), fit(a.ApplicationName.String, 14), fit(a.State.String, 20),
+			fit(waitEvent(a), 22), formatAge(age(m.data.time, a.XactStart)), formatAge(age(m.data.time, a.QueryStart)),
+			oneLine(a.Query.String)))
+	}
+	return lines
+}
+
+func (m *topModel) statementsPanel() []string {
+	lines := []string{fmt.Sprintf("== Top statements in the last interval (ordered by %s) ==", statementSorts[m.statementSort])}
+	if err := m.data.errors["statements"]; err != nil {
+		return append(lines, err.Error())
+	}
+	if !m.data.ready || m.data.statements == nil {
+		return append(lines, "Waiting for the second snapshot...")
+	}
+	rows := append([]pgstats.PgStatStatementsDeltaRow(nil), m.data.statements.Rows...)
+	sort.SliceStable(rows, func(i, j int) bool {
+		switch statementSorts[m.statementSort] {
+		case "calls":
+			return rows[i].Calls > rows[j].Calls
+		case "blocks read":
+			return rows[i].SharedBlksRead > rows[j].SharedBlksRead
+		case "temp blocks":
+			return rows[i].TempBlksWritten > rows[j].TempBlksWritten
+		}
+		return rows[i].TotalTime > rows[j].TotalTime
+	})
+	lines = append(lines, fmt.Sprintf("  %10s %8s %10s %6s %10s %10s %s", "TIME ms", "CALLS", "MEAN ms", "TIME%", "BLKS READ", "TEMP WRITE", "QUERY"))
+	for _, r := range rows {
+		lines = append(lines, fmt.Sprintf("  %10.1f %8d %10.2f %5.1f%% %10d %10d %s", r.TotalTime, r.Calls, r.MeanTime,
+			r.TimeShare*100, r.SharedBlksRead, r.TempBlksWritten, oneLine(r.Query)))
+	}
+	return lines
+}
+
+func (m *topModel) tablesPanel() []string {
+	lines := []string{"== Table I/O in the last interval (by blocks read) =="}
+	if err := m.data.errors["tables"]; err != nil {
+		return append(lines, err.Error())
+	}
+	if !m.data.ready {
+		return append(lines, "Waiting for the second snapshot...")
+	}
+	lines = append(lines, fmt.Sprintf("  %-40s %10s %10s %10s %10s %6s", "TABLE", "HEAP READ", "HEAP HIT", "IDX READ", "IDX HIT", "HIT%"))
+	for _, t := range m.data.tables {
+		lines = append(lines, fmt.Sprintf("  %-40s %10d %10d %10d %10d %6s", fit(t.name, 40), t.heapRead, t.heapHit, t.idxRead, t.idxHit,
+			hitRatio(t.heapHit+t.idxHit, t.heapRead+t.idxRead)))
+	}
+	return lines
+}
+
+func (m *topModel) replicationPanel() []string {
+	lines := []string{"== Replication =="}
+	if err := m.data.errors["replication"]; err != nil {
+		return append(lines, err.Error())
+	}
+	if len(m.data.replication) == 0 {
+		return append(lines, "No standbys or logical replication connections")
+	}
+	lines = append(lines, fmt.Sprintf("  %-20s %-16s %-10s %-6s %10s %10s %10s %10s", "APPLICATION", "CLIENT", "STATE", "SYNC",
+		"SENT LAG", "FLUSH LAG", "REPLAY LAG", "REPLAY s"))
+	for _, r := range m.data.replication {
+		replay := "-"
+		if r.ReplayLag.Valid {
+			replay = strconv.FormatFloat(r.ReplayLag.Float64, 'f', 1, 64)
+		}
+		lines = append(lines, fmt.Sprintf("  %-20s %-16s %-10s %-6s %10s %10s %10s %10s", fit(r.ApplicationName.String, 20),
+			fit(r.ClientAddr.String, 16), fit(r.State.String, 10), fit(r.SyncState.String, 6), formatBytes(r.SentLagBytes.Int64),
+			formatBytes(r.FlushLagBytes.Int64), formatBytes(r.ReplayLagBytes.Int64), replay))
+	}
+	return lines
+}
+
+// tablesIO computes blocks read and hit in each table between two snapshots, most read first
+func tablesIO(current pgstats.PgStatIoUserTablesView, previous pgstats.PgStatIoUserTablesView) []tableIO {
+	prev := make(map[int64]pgstats.PgStatIoTablesRow, len(previous))
+	for _, p := range previous {
+		prev[p.Relid] = p
+	}
+	tables := make([]tableIO, 0)
+	for _, c := range current {
+		p, ok := prev[c.Relid]
+		if !ok {
+			continue
+		}
+		t := tableIO{
+			name:     c.Schemaname + "." + c.Relname,
+			heapRead: c.HeapBlksRead.Int64 - p.HeapBlksRead.Int64,
+			heapHit:  c.HeapBlksHit.Int64 - p.HeapBlksHit.Int64,
+			idxRead:  c.IdxBlksRead.Int64 - p.IdxBlksRead.Int64,
+			idxHit:   c.IdxBlksHit.Int64 - p.IdxBlksHit.Int64,
+		}
+		if t.heapRead+t.heapHit+t.idxRead+t.idxHit > 0 {
+			tables = append(tables, t)
+		}
+	}
+	sort.SliceStable(tables, func(i, j int) bool {
+		if tables[i].heapRead+tables[i].idxRead != tables[j].heapRead+tables[j].idxRead {
+			return tables[i].heapRead+tables[i].idxRead > tables[j].heapRead+tables[j].idxRead
+		}
+		return tables[i].heapHit+tables[i].idxHit > tables[j].heapHit+tables[j].idxHit
+	})
+	return tables
+}
+
+func waitEvent(a pgstats.PgStatActivityRow) string {
+	if !a.WaitEventType.Valid {
+		return ""
+	}
+	return a.WaitEventType.String + ":" + a.WaitEvent.String
+}
+
+// age returns the time elapsed since t, or -1 if t is null
+func age(now time.Time, t nullable.Time) time.Duration {
+	if !t.Valid {
+		return -1
+	}
+	return now.Sub(t.Time)
+}
+
+// formatAge renders a duration compactly, e.g. 12.3s, 4m05s or 2h13m; negative durations mean none
+func formatAge(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "-"
+	case d < time.Minute:
+		return strconv.FormatFloat(d.Seconds(), 'f', 1, 64) + "s"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func formatBytes(b int64) string {
+	units := []string{"B", "kB", "MB", "GB", "TB"}
+	value := float64(b)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return strconv.FormatInt(b, 10) + " B"
+	}
+	return strconv.FormatFloat(value, 'f', 1, 64) + " " + units[unit]
+}
+
+func hitRatio(hit int64, read int64) string {
+	if hit+read == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(float64(hit)*100/float64(hit+read), 'f', 1, 64)
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// fit truncates the text to given number of characters
+func fit(s string, width int) string {
+	r := []rune(s)
+	if width < 0 || len(r) <= width {
+		return s
+	}
+	return string(r[:width])
+}
@@ -0,0 +1,137 @@
+package pgstats
+
+import (
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// columnsFor returns the select list and indexes of the fields of struct type t scanned from it,
+// for given PostgreSQL version.
+//
+// Rows declare columns they are fetched from in pg tags of their fields. A tag is a list of alternatives
+// separated with semicolons, each of them being a column (or any SQL expression), optionally followed by
+// the minimum and maximum PostgreSQL versions it is supported in, e.g.
+//
+//	TotalTime float64 `pg:"total_exec_time,min=13;total_time,min=9.5"`
+//
+// The first alternative supported by the server is selected. Fields without a supported alternative
+// are not selected, so they keep their zero value (null for nullable types); fields without a pg tag are ignored.
+func columnsFor(t reflect.Type, version float64) ([]string, []int, error) {
+	exprs := make([]string, 0, t.NumField())
+	fields := make([]int, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup("pg")
+		if !ok {
+			continue
+		}
+		for _, alternative := range strings.Split(tag, ";") {
+			expr, min, max, err := parseColumn(alternative)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "Invalid pg tag of %s.%s", t.Name(), t.Field(i).Name)
+			}
+			if (min == 0 || version >= min) && (max == 0 || version <= max) {
+				exprs = append(exprs, expr)
+				fields = append(fields, i)
+				break
+			}
+		}
+	}
+	if len(exprs) == 0 {
+		return nil, nil, errors.Errorf("No columns of %s supported in PostgreSQL version: %f", t.Name(), version)
+	}
+	return exprs, fields, nil
+}
+
+// parseColumn parses a single alternative of a pg tag. Expressions may contain commas themselves,
+// so only the trailing min= and max= options are split off.
+func parseColumn(alternative string) (string, float64, float64, error) {
+	parts := strings.Split(alternative, ",")
+	var min, max float64
+	for len(parts) > 1 {
+		option := strings.TrimSpace(parts[len(parts)-1])
+		var bound *float64
+		switch {
+		case strings.HasPrefix(option, "min="):
+			bound = &min
+		case strings.HasPrefix(option, "max="):
+			bound = &max
+		}
+		if bound == nil {
+			break
+		}
+		v, err := strconv.ParseFloat(option[4:], 64)
+		if err != nil {
+			return "", 0, 0, errors.Errorf("invalid version: %s", option)
+		}
+		*bound = v
+		parts = parts[:len(parts)-1]
+	}
+	expr := strings.TrimSpace(strings.Join(parts, ","))
+	if expr == "" || strings.HasPrefix(expr, "min=") || strings.HasPrefix(expr, "max=") {
+		return "", 0, 0, errors.Errorf("missing column in %q", alternative)
+	}
+	return expr, min, max, nil
+}
+
+func scanTargets(row reflect.Value, fields []int) []interface{} {
+	targets := make([]interface{}, len(fields))
+	for i, field := range fields {
+		targets[i] = row.Field(field).Addr().Interface()
+	}
+	return targets
+}
+
+// fetchRows selects columns of the row type supported by the server from given relation (anything following from)
+// into dest, which must be a pointer to a slice of structs with pg tags
+func (s *PgStats) fetchRows(dest interface{}, from string) error {
+	slice := reflect.ValueOf(dest).Elem()
+	rowType := slice.Type().Elem()
+	version, err := s.getPgVersion()
+	if err != nil {
+		return err
+	}
+	exprs, fields, err := columnsFor(rowType, version)
+	if err != nil {
+		return err
+	}
+
+	db := s.db()
+	rows, err := db.Query("select " + strings.Join(exprs, ",") + " from " + from)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	data := reflect.MakeSlice(slice.Type(), 0, 0)
+	for rows.Next() {
+		row := reflect.New(rowType).Elem()
+		if err := rows.Scan(scanTargets(row, fields)...); err != nil {
+			return err
+		}
+		data = reflect.Append(data, row)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	slice.Set(data)
+	return nil
+}
+
+// fetchRow selects columns of the row type supported by the server from given relation (anything following from)
+// into dest, which must be a pointer to a struct with pg tags. It returns sql.ErrNoRows if there is no row.
+func (s *PgStats) fetchRow(dest interface{}, from string) error {
+	row := reflect.ValueOf(dest).Elem()
+	version, err := s.getPgVersion()
+	if err != nil {
+		return err
+	}
+	exprs, fields, err := columnsFor(row.Type(), version)
+	if err != nil {
+		return err
+	}
+
+	db := s.db()
+	return db.QueryRow("select " + strings.Join(exprs, ",") + " from " + from).Scan(scanTargets(row, fields)...)
+}
@@ -0,0 +1,86 @@
+package pgstats
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var columnsTests = []struct {
+	row     interface{}
+	version float64
+	columns string
+}{
+	{PgStatActivityRow{}, 14, "datid,datname,pid,usesysid,usename,application_name,client_addr,client_hostname,client_port," +
+		"backend_start,xact_start,query_start,state_change,wait_event_type,wait_event,state,backend_xid,backend_xmin," +
+		"query,backend_type,query_id"},
+	{PgStatActivityRow{}, 10, "datid,datname,pid,usesysid,usename,application_name,client_addr,client_hostname,client_port," +
+		"backend_start,xact_start,query_start,state_change,wait_event_type,wait_event,state,backend_xid,backend_xmin," +
+		"query,backend_type"},
+	{PgStatActivityRow{}, 9.5, "datid,datname,pid,usesysid,usename,application_name,client_addr,client_hostname,client_port," +
+		"backend_start,xact_start,query_start,state_change,waiting,state,backend_xid,backend_xmin,query"},
+	{PgStatWalReceiverView{}, 11, "pid,status,receive_start_lsn,receive_start_tli,received_lsn,received_tli," +
+		"last_msg_send_time,last_msg_receipt_time,latest_end_lsn,latest_end_time,slot_name,sender_host,sender_port,conninfo"},
+	{PgStatWalReceiverView{}, 10, "pid,status,receive_start_lsn,receive_start_tli,received_lsn,received_tli," +
+		"last_msg_send_time,last_msg_receipt_time,latest_end_lsn,latest_end_time,slot_name,conninfo"},
+	{PgStatStatementsRow{}, 17, "userid,dbid,queryid,query,calls," +
+		"total_exec_time,min_exec_time,max_exec_time,mean_exec_time,stddev_exec_time," +
+		"rows,shared_blks_hit,shared_blks_read,shared_blks_dirtied,shared_blks_written," +
+		"local_blks_hit,local_blks_read,local_blks_dirtied,local_blks_written,temp_blks_read," +
+		"temp_blks_written,shared_blk_read_time,shared_blk_write_time"},
+	{PgStatStatementsRow{}, 9.5, "userid,dbid,queryid,query,calls," +
+		"total_time,min_time,max_time,mean_time,stddev_time," +
+		"rows,shared_blks_hit,shared_blks_read,shared_blks_dirtied,shared_blks_written," +
+		"local_blks_hit,local_blks_read,local_blks_dirtied,local_blks_written,temp_blks_read," +
+		"temp_blks_written,blk_read_time,blk_write_time"},
+	{PgStatStatementsRow{}, 9.4, "userid,dbid,queryid,query,calls," +
+		"rows,shared_blks_hit,shared_blks_read,shared_blks_dirtied,shared_blks_written," +
+		"local_blks_hit,local_blks_read,local_blks_dirtied,local_blks_written,temp_blks_read," +
+		"temp_blks_written,blk_read_time,blk_write_time"},
+	{PgStatReplicationRow{}, 9.6, "pid,usesysid,usename,application_name,client_addr," +
+		"client_hostname,client_port,backend_start,backend_xmin,state," +
+		"sent_location,write_location,flush_location,replay_location,sync_priority,sync_state"},
+}
+
+func TestColumnsFor(t *testing.T) {
+	for _, tt := range columnsTests {
+		rowType := reflect.TypeOf(tt.row)
+		exprs, fields, err := columnsFor(rowType, tt.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := strings.Join(exprs, ","); actual != tt.columns {
+			t.Errorf("Expected columns of %s in %.1f: %s; actual %s", rowType.Name(), tt.version, tt.columns, actual)
+		}
+		if len(fields) != len(exprs) {
+			t.Errorf("Expected %d fields; actual %d", len(exprs), len(fields))
+		}
+	}
+}
+
+func TestScanTargets(t *testing.T) {
+	row := PgStatWalReceiverView{}
+	_, fields, err := columnsFor(reflect.TypeOf(row), 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	targets := scanTargets(reflect.ValueOf(&row).Elem(), fields)
+	if targets[11] != &row.SenderHost || targets[12] != &row.SenderPort || targets[13] != &row.Conninfo {
+		t.Error("Expected sender_host, sender_port and conninfo scanned into their fields")
+	}
+}
+
+func TestParseColumn(t *testing.T) {
+	expr, min, max, err := parseColumn("pg_wal_lsn_diff(sent_lsn,'0/0'),min=10,max=12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expr != "pg_wal_lsn_diff(sent_lsn,'0/0')" || min != 10 || max != 12 {
+		t.Errorf("Unexpected column: %s, %f, %f", expr, min, max)
+	}
+	for _, invalid := range []string{"", "min=10", "column,min=x"} {
+		if _, _, _, err := parseColumn(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
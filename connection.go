@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	// import to register driver
 	_ "github.com/lib/pq"
+	"strconv"
 	"strings"
 )
 
@@ -18,6 +19,21 @@ type connection struct {
 	db         *sql.DB
 }
 
+// queryer is implemented by both *sql.DB and *sql.Tx, so that views can be fetched inside a transaction
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// db returns the transaction the views are fetched in, or the connection pool if there is none
+func (s *PgStats) db() queryer {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.conn.db
+}
+
 func (s *PgStats) prepareConnection(dbname string, user string, password string, options ...Option) error {
 	conn := &connection{}
 	conn.setRequiredParams(dbname, user, password)
@@ -75,3 +91,16 @@ func (c *connection) buildConnectionString() {
 	}
 	c.connString = str.String()
 }
+
+// connectTo opens a new connection to another server, using the same parameters except for host and port
+func (s *PgStats) connectTo(host string, port int) (*PgStats, error) {
+	conn := &connection{config: make(connectionConfig, len(s.conn.config))}
+	for param, value := range s.conn.config {
+		conn.config[param] = value
+	}
+	conn.config["host"] = host
+	conn.config["port"] = strconv.Itoa(port)
+	conn.buildConnectionString()
+	n := &PgStats{conn: conn}
+	return n, n.openConnection()
+}
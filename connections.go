@@ -0,0 +1,273 @@
+package pgstats
+
+import (
+	"github.com/vynaloze/pgstats/nullable"
+	"sort"
+	"time"
+)
+
+// ConnectionThresholds defines how long connections may stay idle before they are reported.
+// Zero value disables reporting of the given kind of connections.
+type ConnectionThresholds struct {
+	// Duration after which an idle connection is reported
+	Idle time.Duration
+	// Duration after which a connection idle in transaction is reported
+	IdleInTransaction time.Duration
+}
+
+// ConnectionsReportView represents client connections compared to their limits
+type ConnectionsReportView struct {
+	// Server time at which the report was made
+	Time time.Time `json:"time"`
+	// Maximum number of concurrent connections to the server (max_connections)
+	MaxConnections int64 `json:"max_connections"`
+	// Number of connection slots reserved for superusers (superuser_reserved_connections)
+	SuperuserReservedConnections int64 `json:"superuser_reserved_connections"`
+	// Number of client connections
+	Total int64 `json:"total"`
+	// Number of connection slots still available to non-superusers
+	Available int64 `json:"available"`
+	// Total as a fraction of connection slots available to non-superusers
+	Usage float64 `json:"usage"`
+	// Connections grouped by state
+	ByState []ConnectionGroup `json:"by_state"`
+	// Connections grouped by database, with per-database connection limits
+	ByDatabase []ConnectionGroup `json:"by_database"`
+	// Connections grouped by user, with per-role connection limits
+	ByUser []ConnectionGroup `json:"by_user"`
+	// Connections grouped by application name
+	ByApplication []ConnectionGroup `json:"by_application"`
+	// Connections grouped by client address: "local" for Unix socket connections, "unknown" for connections
+	// of other users whose details are hidden from the current user (lacking pg_read_all_stats privileges)
+	ByClientAddr []ConnectionGroup `json:"by_client_addr"`
+	// Connections idle for longer than the threshold, longest first
+	Idle []IdleConnection `json:"idle"`
+	// Connections idle in transaction for longer than the threshold, longest first
+	IdleInTransaction []IdleConnection `json:"idle_in_transaction"`
+}
+
+// ConnectionGroup represents a number of connections sharing the same value of a grouping attribute
+type ConnectionGroup struct {
+	// Value of the grouping attribute (state, database, user, application name or client address)
+	Key string `json:"key"`
+	// Number of connections in this group
+	Count int64 `json:"count"`
+	// Number of active connections in this group
+	Active int64 `json:"active"`
+	// Number of idle connections in this group
+	Idle int64 `json:"idle"`
+	// Number of connections idle in transaction (including aborted ones) in this group
+	IdleInTransaction int64 `json:"idle_in_transaction"`
+	// Connection limit of the database or role, if set (datconnlimit or rolconnlimit)
+	ConnLimit nullable.Int64 `json:"conn_limit"`
+	// Count as a fraction of ConnLimit, if set
+	Usage nullable.Float64 `json:"usage"`
+}
+
+// IdleConnection represents a single connection idle for longer than the threshold
+type IdleConnection struct {
+	// Process ID of the backend
+	Pid int64 `json:"pid"`
+	// Name of the database this backend is connected to
+	Datname nullable.String `json:"datname"`
+	// Name of the user logged into this backend
+	Usename nullable.String `json:"usename"`
+	// Name of the application that is connected to this backend
+	ApplicationName nullable.String `json:"application_name"`
+	// IP address of the client connected to this backend
+	ClientAddr nullable.String `json:"client_addr"`
+	// Current state of this backend
+	State string `json:"state"`
+	// Time since the state was last changed
+	Duration time.Duration `json:"duration"`
+}
+
+type connectionLimits struct {
+	maxConnections    int64
+	reservedSuperuser int64
+	databases         map[string]int64
+	roles             map[string]int64
+}
+
+func (s *PgStats) fetchConnectionsReport(thresholds ConnectionThresholds) (*ConnectionsReportView, error) {
+	limits, err := s.fetchConnectionLimits()
+	if err != nil {
+		return nil, err
+	}
+	activity, err := s.fetchActivity()
+	if err != nil {
+		return nil, err
+	}
+	now, err := s.fetchNow()
+	if err != nil {
+		return nil, err
+	}
+	return connectionsReport(activity, now, limits, thresholds), nil
+}
+
+func (s *PgStats) fetchNow() (time.Time, error) {
+	db := s.db()
+	row := db.QueryRow("select now()")
+	var now time.Time
+	err := row.Scan(&now)
+	return now, err
+}
+
+func (s *PgStats) fetchConnectionLimits() (connectionLimits, error) {
+	var err error
+	limits := connectionLimits{databases: make(map[string]int64), roles: make(map[string]int64)}
+	if limits.maxConnections, err = s.fetchSettingInt("max_connections"); err != nil {
+		return limits, err
+	}
+	if limits.reservedSuperuser, err = s.fetchSettingInt("superuser_reserved_connections"); err != nil {
+		return limits, err
+	}
+
+	db := s.db()
+	query := "select 'database',datname::text,datconnlimit from pg_database where datconnlimit>=0 " +
+		"union all " +
+		"select 'role',rolname::text,rolconnlimit from pg_roles where rolconnlimit>=0"
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return limits, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var kind, name string
+		var limit int64
+		if err := rows.Scan(&kind, &name, &limit); err != nil {
+			return limits, err
+		}
+		if kind == "database" {
+			limits.databases[name] = limit
+		} else {
+			limits.roles[name] = limit
+		}
+	}
+	return limits, rows.Err()
+}
+
+// insufficientPrivilege replaces details of sessions of other users in pg_stat_activity,
+// unless the current user has privileges of pg_read_all_stats
+const insufficientPrivilege = "<insufficient privilege>"
+
+// connectionsReport aggregates client backends and compares them to the limits.
+// Background processes (e.g. autovacuum workers or WAL senders) are not taken into account.
+func connectionsReport(activity []PgStatActivityRow, now time.Time, limits connectionLimits, thresholds ConnectionThresholds) *ConnectionsReportView {
+	report := &ConnectionsReportView{
+		Time:                         now,
+		MaxConnections:               limits.maxConnections,
+		SuperuserReservedConnections: limits.reservedSuperuser,
+		Idle:                         make([]IdleConnection, 0),
+		IdleInTransaction:            make([]IdleConnection, 0),
+	}
+	byState := make(map[string]*ConnectionGroup)
+	byDatabase := make(map[string]*ConnectionGroup)
+	byUser := make(map[string]*ConnectionGroup)
+	byApplication := make(map[string]*ConnectionGroup)
+	byClientAddr := make(map[string]*ConnectionGroup)
+
+	for _, a := range activity {
+		if a.BackendType.Valid && a.BackendType.String != "client backend" || !a.Datname.Valid {
+			continue
+		}
+		report.Total++
+		state := a.State.String
+		clientAddr := "local"
+		switch {
+		case a.ClientAddr.Valid:
+			clientAddr = a.ClientAddr.String
+		case a.Query.String == insufficientPrivilege:
+			// client_addr is null for hidden sessions as well
+			clientAddr = "unknown"
+		}
+		addToGroup(byState, state, state)
+		addToGroup(byDatabase, a.Datname.String, state)
+		addToGroup(byUser, a.Usename.String, state)
+		addToGroup(byApplication, a.ApplicationName.String, state)
+		addToGroup(byClientAddr, clientAddr, state)
+
+		if !a.StateChange.Valid {
+			continue
+		}
+		idle := IdleConnection{
+			Pid:             a.Pid,
+			Datname:         a.Datname,
+			Usename:         a.Usename,
+			ApplicationName: a.ApplicationName,
+			ClientAddr:      a.ClientAddr,
+			State:           state,
+			Duration:        now.Sub(a.StateChange.Time),
+		}
+		switch {
+		case state == "idle" && thresholds.Idle > 0 && idle.Duration > thresholds.Idle:
+			report.Idle = append(report.Idle, idle)
+		case isIdleInTransaction(state) && thresholds.IdleInTransaction > 0 && idle.Duration > thresholds.IdleInTransaction:
+			report.IdleInTransaction = append(report.IdleInTransaction, idle)
+		}
+	}
+
+	slots := limits.maxConnections - limits.reservedSuperuser
+	report.Available = slots - report.Total
+	if slots > 0 {
+		report.Usage = float64(report.Total) / float64(slots)
+	}
+	report.ByState = sortedGroups(byState, nil)
+	report.ByDatabase = sortedGroups(byDatabase, limits.databases)
+	report.ByUser = sortedGroups(byUser, limits.roles)
+	report.ByApplication = sortedGroups(byApplication, nil)
+	report.ByClientAddr = sortedGroups(byClientAddr, nil)
+	sortIdleConnections(report.Idle)
+	sortIdleConnections(report.IdleInTransaction)
+	return report
+}
+
+func isIdleInTransaction(state string) bool {
+	return state == "idle in transaction" || state == "idle in transaction (aborted)"
+}
+
+func addToGroup(groups map[string]*ConnectionGroup, key string, state string) {
+	g, ok := groups[key]
+	if !ok {
+		g = &ConnectionGroup{Key: key}
+		groups[key] = g
+	}
+	g.Count++
+	switch {
+	case state == "active":
+		g.Active++
+	case state == "idle":
+		g.Idle++
+	case isIdleInTransaction(state):
+		g.IdleInTransaction++
+	}
+}
+
+// sortedGroups applies connection limits to the groups and orders them by the number of connections (highest first)
+func sortedGroups(groups map[string]*ConnectionGroup, limits map[string]int64) []ConnectionGroup {
+	res := make([]ConnectionGroup, 0, len(groups))
+	for _, g := range groups {
+		if limit, ok := limits[g.Key]; ok {
+			g.ConnLimit.Valid, g.ConnLimit.Int64 = true, limit
+			if limit > 0 {
+				g.Usage.Valid, g.Usage.Float64 = true, float64(g.Count)/float64(limit)
+			}
+		}
+		res = append(res, *g)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count != res[j].Count {
+			return res[i].Count > res[j].Count
+		}
+		return res[i].Key < res[j].Key
+	})
+	return res
+}
+
+func sortIdleConnections(connections []IdleConnection) {
+	sort.SliceStable(connections, func(i, j int) bool {
+		return connections[i].Duration > connections[j].Duration
+	})
+}
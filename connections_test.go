@@ -0,0 +1,79 @@
+package pgstats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConnectionsReport(t *testing.T) {
+	now := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	activity := []PgStatActivityRow{
+		backend(1, "app", "alice", "web", "10.0.0.1", "active", now.Add(-time.Second)),
+		backend(2, "app", "alice", "web", "10.0.0.1", "idle", now.Add(-2*time.Hour)),
+		backend(3, "app", "bob", "batch", "", "idle in transaction", now.Add(-10*time.Minute)),
+		backend(4, "other", "bob", "batch", "", "idle", now.Add(-time.Minute)),
+		backend(5, "app", "alice", "web", "10.0.0.2", "idle in transaction (aborted)", now.Add(-time.Hour)),
+	}
+	worker := backend(6, "", "", "", "", "", now)
+	worker.BackendType.Valid, worker.BackendType.String = true, "autovacuum launcher"
+	activity = append(activity, worker)
+
+	limits := connectionLimits{
+		maxConnections:    13,
+		reservedSuperuser: 3,
+		databases:         map[string]int64{"app": 8},
+		roles:             map[string]int64{"bob": 0},
+	}
+	r := connectionsReport(activity, now, limits, ConnectionThresholds{Idle: 30 * time.Minute, IdleInTransaction: 5 * time.Minute})
+
+	if r.Total != 5 || r.Available != 5 || r.Usage != 0.5 {
+		t.Errorf("Unexpected totals: %+v", r)
+	}
+	if len(r.ByDatabase) != 2 || r.ByDatabase[0].Key != "app" || r.ByDatabase[0].Count != 4 ||
+		r.ByDatabase[0].IdleInTransaction != 2 || r.ByDatabase[0].Usage.Float64 != 0.5 || r.ByDatabase[1].ConnLimit.Valid {
+		t.Errorf("Unexpected grouping by database: %+v", r.ByDatabase)
+	}
+	if len(r.ByUser) != 2 || r.ByUser[1].Key != "bob" || !r.ByUser[1].ConnLimit.Valid || r.ByUser[1].Usage.Valid {
+		t.Errorf("Unexpected grouping by user: %+v", r.ByUser)
+	}
+	if len(r.ByClientAddr) != 3 || r.ByClientAddr[0].Key != "10.0.0.1" || r.ByClientAddr[1].Key != "local" {
+		t.Errorf("Unexpected grouping by client address: %+v", r.ByClientAddr)
+	}
+	if len(r.ByState) != 4 {
+		t.Errorf("Expected 4 states; actual %+v", r.ByState)
+	}
+	if len(r.Idle) != 1 || r.Idle[0].Pid != 2 {
+		t.Errorf("Unexpected idle connections: %+v", r.Idle)
+	}
+	if len(r.IdleInTransaction) != 2 || r.IdleInTransaction[0].Pid != 5 || r.IdleInTransaction[1].Duration != 10*time.Minute {
+		t.Errorf("Unexpected connections idle in transaction: %+v", r.IdleInTransaction)
+	}
+}
+
+func backend(pid int64, datname string, usename string, app string, addr string, state string, stateChange time.Time) PgStatActivityRow {
+	row := PgStatActivityRow{Pid: pid}
+	row.Datname.Valid, row.Datname.String = datname != "", datname
+	row.Usename.Valid, row.Usename.String = usename != "", usename
+	row.ApplicationName.Valid, row.ApplicationName.String = app != "", app
+	row.ClientAddr.Valid, row.ClientAddr.String = addr != "", addr
+	row.State.Valid, row.State.String = state != "", state
+	row.StateChange.Valid, row.StateChange.Time = true, stateChange
+	return row
+}
+
+func TestConnectionsReportHiddenSessions(t *testing.T) {
+	now := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	hidden := backend(1, "app", "carol", "", "", "", now)
+	hidden.Query.Valid, hidden.Query.String = true, "<insufficient privilege>"
+	socket := backend(2, "app", "alice", "web", "", "active", now)
+	socket.Query.Valid, socket.Query.String = true, "select 1"
+
+	r := connectionsReport([]PgStatActivityRow{hidden, socket}, now, connectionLimits{maxConnections: 10}, ConnectionThresholds{})
+	keys := make(map[string]int64)
+	for _, g := range r.ByClientAddr {
+		keys[g.Key] = g.Count
+	}
+	if len(keys) != 2 || keys["unknown"] != 1 || keys["local"] != 1 {
+		t.Errorf("Expected hidden session with unknown client address; actual %+v", r.ByClientAddr)
+	}
+}
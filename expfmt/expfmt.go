@@ -0,0 +1,158 @@
+// Package expfmt renders pgstats views and snapshots in the Prometheus text exposition format
+// or in the OpenMetrics text format, without depending on the Prometheus client library.
+//
+// Metric names are derived from json tags of the fields: namespace, view name and the path of tags
+// joined with underscores, e.g. pgstats_pg_stat_database_xact_commit_total.
+// Times, counted by PostgreSQL in milliseconds, are exposed in seconds,
+// e.g. pgstats_pg_stat_statements_time_seconds_total.
+// HELP texts come from doc comments of the fields.
+package expfmt
+
+import (
+	"github.com/vynaloze/pgstats/internal/schema"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Format represents an exposition format
+type Format int
+
+const (
+	// TextFormat is the Prometheus text exposition format, version 0.0.4
+	TextFormat Format = iota
+	// OpenMetricsFormat is the OpenMetrics text format, version 1.0.0
+	OpenMetricsFormat
+)
+
+// ContentType returns the value of the Content-Type header for the format
+func (f Format) ContentType() string {
+	if f == OpenMetricsFormat {
+		return "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	}
+	return "text/plain; version=0.0.4; charset=utf-8"
+}
+
+// DefaultNamespace is the prefix of all metric names, unless changed in the Encoder
+const DefaultNamespace = "pgstats"
+
+// Encoder writes views in the chosen format
+type Encoder struct {
+	// Prefix of all metric names. May be empty.
+	Namespace string
+	w         io.Writer
+	format    Format
+}
+
+// NewEncoder returns a new encoder writing to w in given format
+func NewEncoder(w io.Writer, format Format) *Encoder {
+	return &Encoder{Namespace: DefaultNamespace, w: w, format: format}
+}
+
+// Encode writes all values of v - a view, a single row or a snapshot built from views - as metrics
+// named after name, e.g. "pg_stat_database". Metrics of different calls must not share names.
+func (e *Encoder) Encode(name string, v interface{}) error {
+	prefix := e.prefix(name)
+	for _, family := range schema.Flatten(v) {
+		if err := e.writeFamily(prefix+family.Name, family); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close finishes the exposition. In OpenMetrics format it writes the mandatory EOF marker.
+// It does not close the underlying writer.
+func (e *Encoder) Close() error {
+	if e.format == OpenMetricsFormat {
+		_, err := io.WriteString(e.w, "# EOF\n")
+		return err
+	}
+	return nil
+}
+
+// Write writes v as metrics named after name in Prometheus text format, with the default namespace
+func Write(w io.Writer, name string, v interface{}) error {
+	return NewEncoder(w, TextFormat).Encode(name, v)
+}
+
+func (e *Encoder) prefix(name string) string {
+	prefix := ""
+	for _, part := range []string{e.Namespace, name} {
+		if part != "" {
+			prefix += schema.Sanitize(part) + "_"
+		}
+	}
+	return prefix
+}
+
+func (e *Encoder) writeFamily(name string, family schema.Family) error {
+	if family.Unit == schema.Seconds && strings.HasSuffix(name, "_time") {
+		name = inSeconds(name)
+	}
+	sampleName := name
+	if family.Kind == schema.Counter {
+		if !strings.HasSuffix(name, "_total") {
+			sampleName = name + "_total"
+		}
+		if e.format == OpenMetricsFormat {
+			name = strings.TrimSuffix(name, "_total")
+		} else {
+			name = sampleName
+		}
+	}
+
+	var b strings.Builder
+	if family.Help != "" {
+		b.WriteString("# HELP " + name + " " + escapeHelp(family.Help) + "\n")
+	}
+	b.WriteString("# TYPE " + name + " " + family.Kind.String() + "\n")
+	for _, s := range family.Samples {
+		b.WriteString(sampleName)
+		if len(s.Labels) > 0 {
+			b.WriteString("{")
+			for i, l := range s.Labels {
+				if i > 0 {
+					b.WriteString(",")
+				}
+				b.WriteString(l.Name + "=\"" + escapeLabelValue(l.Value) + "\"")
+			}
+			b.WriteString("}")
+		}
+		b.WriteString(" " + formatValue(s.Value) + "\n")
+	}
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+// inSeconds names a time, counted by PostgreSQL in milliseconds (e.g. total_time or blk_read_time)
+// and converted to seconds by schema, with the base unit of Prometheus, e.g. pg_stat_statements_time_seconds
+// instead of pg_stat_statements_total_time
+func inSeconds(name string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(name, "_time"), "_total") + "_time_seconds"
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,92 @@
+package expfmt
+
+import (
+	"bytes"
+	"database/sql"
+	"github.com/vynaloze/pgstats"
+	"github.com/vynaloze/pgstats/nullable"
+	"math"
+	"strings"
+	"testing"
+)
+
+var view = pgstats.PgStatDatabaseView{
+	{Datid: 1, Datname: "app", NumBackends: 3, XactCommit: nullable.Int64{NullInt64: sql.NullInt64{Int64: 10, Valid: true}}},
+	{Datid: 2, Datname: "we\"ird\\db\n", NumBackends: 0},
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "pg_stat_database", view); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP pgstats_pg_stat_database_numbackends Number of backends currently connected to this database. This is the only column in this view that returns a value reflecting current state; all other columns return the accumulated values since the last reset.
+# TYPE pgstats_pg_stat_database_numbackends gauge
+pgstats_pg_stat_database_numbackends{datid="1",datname="app"} 3
+pgstats_pg_stat_database_numbackends{datid="2",datname="we\"ird\\db\n"} 0
+# HELP pgstats_pg_stat_database_xact_commit_total Number of transactions in this database that have been committed
+# TYPE pgstats_pg_stat_database_xact_commit_total counter
+pgstats_pg_stat_database_xact_commit_total{datid="1",datname="app"} 10
+`
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Expected:\n%s\nactual:\n%s", expected, actual)
+	}
+}
+
+func TestOpenMetrics(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf, OpenMetricsFormat)
+	e.Namespace = ""
+	if err := e.Encode("db", view[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP db_numbackends Number of backends currently connected to this database. This is the only column in this view that returns a value reflecting current state; all other columns return the accumulated values since the last reset.
+# TYPE db_numbackends gauge
+db_numbackends{datid="1",datname="app"} 3
+# HELP db_xact_commit Number of transactions in this database that have been committed
+# TYPE db_xact_commit counter
+db_xact_commit_total{datid="1",datname="app"} 10
+# EOF
+`
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Expected:\n%s\nactual:\n%s", expected, actual)
+	}
+}
+
+func TestMilliseconds(t *testing.T) {
+	var buf bytes.Buffer
+	statements := pgstats.PgStatStatementsView{{Userid: 10, Dbid: 1, Queryid: 42, Query: "select 1", TotalTime: 1500, BlkReadTime: 250, MeanTime: 750}}
+	if err := Write(&buf, "pg_stat_statements", statements); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"# HELP pgstats_pg_stat_statements_time_seconds_total Total time spent in the statement, in seconds (",
+		"# TYPE pgstats_pg_stat_statements_time_seconds_total counter\n",
+		"pgstats_pg_stat_statements_time_seconds_total{userid=\"10\",dbid=\"1\",queryid=\"42\"} 1.5\n",
+		"pgstats_pg_stat_statements_blk_read_time_seconds_total{userid=\"10\",dbid=\"1\",queryid=\"42\"} 0.25\n",
+		"pgstats_pg_stat_statements_mean_time_seconds{userid=\"10\",dbid=\"1\",queryid=\"42\"} 0.75\n",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected %q in:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	for in, expected := range map[float64]string{
+		1.5:          "1.5",
+		1e21:         "1e+21",
+		math.Inf(1):  "+Inf",
+		math.Inf(-1): "-Inf",
+	} {
+		if actual := formatValue(in); actual != expected {
+			t.Errorf("Expected %s; actual %s", expected, actual)
+		}
+	}
+	if actual := formatValue(math.NaN()); actual != "NaN" {
+		t.Errorf("Expected NaN; actual %s", actual)
+	}
+}
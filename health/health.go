@@ -0,0 +1,123 @@
+// Package health evaluates advisory rules against pgstats statistics,
+// reporting common problems of PostgreSQL instances together with the objects causing them.
+package health
+
+import (
+	"github.com/vynaloze/pgstats"
+	"sort"
+	"time"
+)
+
+// Severity represents how serious a finding is
+type Severity int
+
+const (
+	// Info marks findings worth knowing about, but not requiring any action
+	Info Severity = iota
+	// Warning marks findings which should be looked into
+	Warning
+	// Critical marks findings which require immediate action
+	Critical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Critical:
+		return "critical"
+	}
+	return "unknown"
+}
+
+// MarshalText encodes severity as its name
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Finding represents a single problem detected by a rule
+type Finding struct {
+	// Name of the rule which produced the finding
+	Rule string `json:"rule"`
+	// Severity of the finding
+	Severity Severity `json:"severity"`
+	// Human-readable description of the problem
+	Message string `json:"message"`
+	// Names of the offending objects (databases, tables, indexes, backends, ...)
+	Objects []string `json:"objects"`
+}
+
+// Snapshot holds statistics the rules are evaluated against
+type Snapshot struct {
+	// Time at which the snapshot was taken
+	Time      time.Time                     `json:"time"`
+	Databases pgstats.PgStatDatabaseView    `json:"databases"`
+	Tables    pgstats.PgStatUserTablesView  `json:"tables"`
+	Indexes   pgstats.PgStatUserIndexesView `json:"indexes"`
+	Activity  pgstats.PgStatActivityView    `json:"activity"`
+	Archiver  pgstats.PgStatArchiverView    `json:"archiver"`
+	BgWriter  pgstats.PgStatBgWriterView    `json:"bgwriter"`
+}
+
+// Collect takes a snapshot of all statistics used by the built-in rules
+func Collect(s *pgstats.PgStats) (*Snapshot, error) {
+	var err error
+	snap := &Snapshot{Time: time.Now()}
+	if snap.Databases, err = s.PgStatDatabase(); err != nil {
+		return nil, err
+	}
+	if snap.Tables, err = s.PgStatUserTables(); err != nil {
+		return nil, err
+	}
+	if snap.Indexes, err = s.PgStatUserIndexes(); err != nil {
+		return nil, err
+	}
+	if snap.Activity, err = s.PgStatActivity(); err != nil {
+		return nil, err
+	}
+	if snap.Archiver, err = s.PgStatArchiver(); err != nil {
+		return nil, err
+	}
+	if snap.BgWriter, err = s.PgStatBgWriter(); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// Rule evaluates a single check against a snapshot
+type Rule interface {
+	// Name returns the name of the rule
+	Name() string
+	// Evaluate returns problems found in the snapshot, if any
+	Evaluate(snapshot *Snapshot) []Finding
+}
+
+// DefaultRules returns all built-in rules with their default thresholds
+func DefaultRules() []Rule {
+	return []Rule{
+		DefaultCacheHitRatio(),
+		DefaultDeadTuples(),
+		UnusedIndexes{},
+		DefaultIdleInTransaction(),
+		ArchiverFailures{},
+		DefaultRequestedCheckpoints(),
+	}
+}
+
+// Check evaluates given rules (or DefaultRules, if none are given) against the snapshot.
+// Findings are ordered by severity, most severe first.
+func Check(snapshot *Snapshot, rules ...Rule) []Finding {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	findings := make([]Finding, 0)
+	for _, rule := range rules {
+		findings = append(findings, rule.Evaluate(snapshot)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity > findings[j].Severity
+	})
+	return findings
+}
@@ -0,0 +1,84 @@
+package health
+
+import (
+	"github.com/vynaloze/pgstats"
+	"testing"
+	"time"
+)
+
+func testSnapshot() *Snapshot {
+	now := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	s := &Snapshot{Time: now}
+
+	good := pgstats.PgStatDatabaseRow{Datname: "good"}
+	good.BlksHit.Valid, good.BlksHit.Int64 = true, 9900
+	good.BlksRead.Valid, good.BlksRead.Int64 = true, 100
+	bad := pgstats.PgStatDatabaseRow{Datname: "bad"}
+	bad.BlksHit.Valid, bad.BlksHit.Int64 = true, 800
+	bad.BlksRead.Valid, bad.BlksRead.Int64 = true, 200
+	s.Databases = pgstats.PgStatDatabaseView{good, bad}
+
+	bloated := pgstats.PgStatTablesRow{Schemaname: "public", Relname: "bloated"}
+	bloated.NDeadTup.Valid, bloated.NDeadTup.Int64 = true, 30000
+	bloated.NLiveTup.Valid, bloated.NLiveTup.Int64 = true, 70000
+	s.Tables = pgstats.PgStatUserTablesView{bloated}
+
+	unused := pgstats.PgStatIndexesRow{Schemaname: "public", Relname: "bloated", Indexrelname: "bloated_idx"}
+	unused.IdxScan.Valid = true
+	s.Indexes = pgstats.PgStatUserIndexesView{unused}
+
+	idle := pgstats.PgStatActivityRow{Pid: 42}
+	idle.State.Valid, idle.State.String = true, "idle in transaction"
+	idle.StateChange.Valid, idle.StateChange.Time = true, now.Add(-time.Hour)
+	s.Activity = pgstats.PgStatActivityView{idle}
+
+	s.Archiver.FailedCount.Valid, s.Archiver.FailedCount.Int64 = true, 3
+	s.Archiver.LastFailedTime.Valid, s.Archiver.LastFailedTime.Time = true, now.Add(-time.Minute)
+	s.Archiver.LastArchivedTime.Valid, s.Archiver.LastArchivedTime.Time = true, now.Add(-time.Hour)
+
+	s.BgWriter.CheckpointsTimed.Valid, s.BgWriter.CheckpointsTimed.Int64 = true, 70
+	s.BgWriter.CheckpointsReq.Valid, s.BgWriter.CheckpointsReq.Int64 = true, 30
+	return s
+}
+
+func TestDefaultRules(t *testing.T) {
+	findings := Check(testSnapshot())
+	expected := map[string]Severity{
+		"cache_hit_ratio":       Critical,
+		"dead_tuples":           Warning,
+		"unused_indexes":        Info,
+		"idle_in_transaction":   Critical,
+		"archiver_failures":     Critical,
+		"requested_checkpoints": Warning,
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings; actual %+v", len(expected), findings)
+	}
+	for _, f := range findings {
+		sev, ok := expected[f.Rule]
+		if !ok || sev != f.Severity {
+			t.Errorf("Unexpected finding: %+v", f)
+		}
+		if len(f.Objects) != 1 {
+			t.Errorf("Expected single offending object; actual %v", f.Objects)
+		}
+	}
+	for i := 1; i < len(findings); i++ {
+		if findings[i].Severity > findings[i-1].Severity {
+			t.Errorf("Findings are not ordered by severity")
+		}
+	}
+}
+
+func TestNoFindingsBelowThresholds(t *testing.T) {
+	snapshot := testSnapshot()
+	rules := []Rule{
+		CacheHitRatio{Warning: 0.5, Critical: 0.1},
+		DeadTuples{Warning: 0.5, Critical: 0.9},
+		IdleInTransaction{Warning: 2 * time.Hour, Critical: 3 * time.Hour},
+		RequestedCheckpoints{Warning: 0.5, Critical: 0.9},
+	}
+	if findings := Check(snapshot, rules...); len(findings) != 0 {
+		t.Errorf("Expected no findings; actual %+v", findings)
+	}
+}
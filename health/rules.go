@@ -0,0 +1,260 @@
+package health
+
+import (
+	"fmt"
+	"time"
+)
+
+// CacheHitRatio reports databases in which the ratio of blocks found in the buffer cache
+// to all blocks accessed is below the thresholds
+type CacheHitRatio struct {
+	// Ratio below which a warning is reported
+	Warning float64
+	// Ratio below which a critical finding is reported
+	Critical float64
+	// Databases with fewer accessed blocks are skipped, as their ratio is not meaningful
+	MinBlocks int64
+}
+
+// DefaultCacheHitRatio returns CacheHitRatio rule with default thresholds
+func DefaultCacheHitRatio() CacheHitRatio {
+	return CacheHitRatio{Warning: 0.95, Critical: 0.9, MinBlocks: 1000}
+}
+
+// Name returns the name of the rule
+func (r CacheHitRatio) Name() string {
+	return "cache_hit_ratio"
+}
+
+// Evaluate returns problems found in the snapshot, if any
+func (r CacheHitRatio) Evaluate(snapshot *Snapshot) []Finding {
+	o := offenders{}
+	for _, db := range snapshot.Databases {
+		total := db.BlksHit.Int64 + db.BlksRead.Int64
+		if total == 0 || total < r.MinBlocks {
+			continue
+		}
+		ratio := float64(db.BlksHit.Int64) / float64(total)
+		object := fmt.Sprintf("%s (%.2f%%)", db.Datname, ratio*100)
+		if ratio < r.Critical {
+			o.add(Critical, object)
+		} else if ratio < r.Warning {
+			o.add(Warning, object)
+		}
+	}
+	return o.findings(r.Name(), func(sev Severity, n int) string {
+		threshold := r.Warning
+		if sev == Critical {
+			threshold = r.Critical
+		}
+		return fmt.Sprintf("%d database(s) with cache hit ratio below %.2f%%", n, threshold*100)
+	})
+}
+
+// DeadTuples reports tables in which the fraction of dead rows exceeds the thresholds
+type DeadTuples struct {
+	// Fraction of dead rows above which a warning is reported
+	Warning float64
+	// Fraction of dead rows above which a critical finding is reported
+	Critical float64
+	// Tables with fewer dead rows are skipped
+	MinDeadTuples int64
+}
+
+// DefaultDeadTuples returns DeadTuples rule with default thresholds
+func DefaultDeadTuples() DeadTuples {
+	return DeadTuples{Warning: 0.2, Critical: 0.5, MinDeadTuples: 10000}
+}
+
+// Name returns the name of the rule
+func (r DeadTuples) Name() string {
+	return "dead_tuples"
+}
+
+// Evaluate returns problems found in the snapshot, if any
+func (r DeadTuples) Evaluate(snapshot *Snapshot) []Finding {
+	o := offenders{}
+	for _, t := range snapshot.Tables {
+		dead := t.NDeadTup.Int64
+		if dead == 0 || dead < r.MinDeadTuples {
+			continue
+		}
+		ratio := float64(dead) / float64(dead+t.NLiveTup.Int64)
+		object := fmt.Sprintf("%s.%s (%d dead, %.2f%%)", t.Schemaname, t.Relname, dead, ratio*100)
+		if ratio > r.Critical {
+			o.add(Critical, object)
+		} else if ratio > r.Warning {
+			o.add(Warning, object)
+		}
+	}
+	return o.findings(r.Name(), func(sev Severity, n int) string {
+		threshold := r.Warning
+		if sev == Critical {
+			threshold = r.Critical
+		}
+		return fmt.Sprintf("%d table(s) with more than %.2f%% dead rows", n, threshold*100)
+	})
+}
+
+// UnusedIndexes reports indexes which have never been scanned since statistics were last reset
+type UnusedIndexes struct{}
+
+// Name returns the name of the rule
+func (r UnusedIndexes) Name() string {
+	return "unused_indexes"
+}
+
+// Evaluate returns problems found in the snapshot, if any
+func (r UnusedIndexes) Evaluate(snapshot *Snapshot) []Finding {
+	o := offenders{}
+	for _, i := range snapshot.Indexes {
+		if i.IdxScan.Valid && i.IdxScan.Int64 == 0 {
+			o.add(Info, fmt.Sprintf("%s.%s on %s", i.Schemaname, i.Indexrelname, i.Relname))
+		}
+	}
+	return o.findings(r.Name(), func(sev Severity, n int) string {
+		return fmt.Sprintf("%d index(es) never scanned since statistics reset", n)
+	})
+}
+
+// IdleInTransaction reports backends which stay idle inside an open transaction for too long
+type IdleInTransaction struct {
+	// Duration above which a warning is reported
+	Warning time.Duration
+	// Duration above which a critical finding is reported
+	Critical time.Duration
+}
+
+// DefaultIdleInTransaction returns IdleInTransaction rule with default thresholds
+func DefaultIdleInTransaction() IdleInTransaction {
+	return IdleInTransaction{Warning: 5 * time.Minute, Critical: 30 * time.Minute}
+}
+
+// Name returns the name of the rule
+func (r IdleInTransaction) Name() string {
+	return "idle_in_transaction"
+}
+
+// Evaluate returns problems found in the snapshot, if any
+func (r IdleInTransaction) Evaluate(snapshot *Snapshot) []Finding {
+	o := offenders{}
+	for _, a := range snapshot.Activity {
+		state := a.State.String
+		if state != "idle in transaction" && state != "idle in transaction (aborted)" || !a.StateChange.Valid {
+			continue
+		}
+		idle := snapshot.Time.Sub(a.StateChange.Time)
+		object := fmt.Sprintf("pid %d (%s@%s, %s)", a.Pid, a.Usename.String, a.Datname.String, idle.Truncate(time.Second))
+		if idle > r.Critical {
+			o.add(Critical, object)
+		} else if idle > r.Warning {
+			o.add(Warning, object)
+		}
+	}
+	return o.findings(r.Name(), func(sev Severity, n int) string {
+		threshold := r.Warning
+		if sev == Critical {
+			threshold = r.Critical
+		}
+		return fmt.Sprintf("%d backend(s) idle in transaction for more than %s", n, threshold)
+	})
+}
+
+// ArchiverFailures reports failing WAL archiving
+type ArchiverFailures struct{}
+
+// Name returns the name of the rule
+func (r ArchiverFailures) Name() string {
+	return "archiver_failures"
+}
+
+// Evaluate returns problems found in the snapshot, if any
+func (r ArchiverFailures) Evaluate(snapshot *Snapshot) []Finding {
+	a := snapshot.Archiver
+	if !a.FailedCount.Valid || a.FailedCount.Int64 == 0 || !a.LastFailedTime.Valid {
+		return nil
+	}
+	if a.LastArchivedTime.Valid && a.LastArchivedTime.Time.After(a.LastFailedTime.Time) {
+		return []Finding{{
+			Rule:     r.Name(),
+			Severity: Info,
+			Message: fmt.Sprintf("WAL archiving failed %d time(s), but has succeeded since %s",
+				a.FailedCount.Int64, a.LastFailedTime.Time.Format(time.RFC3339)),
+			Objects: []string{a.LastFailedWal.String},
+		}}
+	}
+	return []Finding{{
+		Rule:     r.Name(),
+		Severity: Critical,
+		Message: fmt.Sprintf("WAL archiving is failing; last failure at %s (%d failure(s) in total)",
+			a.LastFailedTime.Time.Format(time.RFC3339), a.FailedCount.Int64),
+		Objects: []string{a.LastFailedWal.String},
+	}}
+}
+
+// RequestedCheckpoints reports a high fraction of requested (as opposed to scheduled) checkpoints,
+// which usually means max_wal_size is too low for the workload
+type RequestedCheckpoints struct {
+	// Fraction of requested checkpoints above which a warning is reported
+	Warning float64
+	// Fraction of requested checkpoints above which a critical finding is reported
+	Critical float64
+	// The rule is skipped if fewer checkpoints have been performed in total
+	MinCheckpoints int64
+}
+
+// DefaultRequestedCheckpoints returns RequestedCheckpoints rule with default thresholds
+func DefaultRequestedCheckpoints() RequestedCheckpoints {
+	return RequestedCheckpoints{Warning: 0.2, Critical: 0.5, MinCheckpoints: 10}
+}
+
+// Name returns the name of the rule
+func (r RequestedCheckpoints) Name() string {
+	return "requested_checkpoints"
+}
+
+// Evaluate returns problems found in the snapshot, if any
+func (r RequestedCheckpoints) Evaluate(snapshot *Snapshot) []Finding {
+	b := snapshot.BgWriter
+	total := b.CheckpointsTimed.Int64 + b.CheckpointsReq.Int64
+	if total == 0 || total < r.MinCheckpoints {
+		return nil
+	}
+	ratio := float64(b.CheckpointsReq.Int64) / float64(total)
+	var sev Severity
+	var threshold float64
+	switch {
+	case ratio > r.Critical:
+		sev, threshold = Critical, r.Critical
+	case ratio > r.Warning:
+		sev, threshold = Warning, r.Warning
+	default:
+		return nil
+	}
+	return []Finding{{
+		Rule:     r.Name(),
+		Severity: sev,
+		Message: fmt.Sprintf("%.2f%% of checkpoints were requested (more than %.2f%%); consider increasing max_wal_size",
+			ratio*100, threshold*100),
+		Objects: []string{"pg_stat_bgwriter"},
+	}}
+}
+
+// offenders groups offending objects by severity
+type offenders map[Severity][]string
+
+func (o offenders) add(sev Severity, object string) {
+	o[sev] = append(o[sev], object)
+}
+
+func (o offenders) findings(rule string, message func(sev Severity, n int) string) []Finding {
+	res := make([]Finding, 0)
+	for _, sev := range []Severity{Critical, Warning, Info} {
+		objects, ok := o[sev]
+		if !ok {
+			continue
+		}
+		res = append(res, Finding{Rule: rule, Severity: sev, Message: message(sev, len(objects)), Objects: objects})
+	}
+	return res
+}
@@ -0,0 +1,286 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxFileSize is the size after which FileStore starts a new file
+const DefaultMaxFileSize = 64 << 20
+
+const (
+	filePrefix = "snapshots-"
+	fileSuffix = ".jsonl"
+)
+
+// FileStore keeps snapshots in a directory of append-only JSON lines files, one snapshot per line.
+// Files are named after the ID of their first snapshot. When the current file grows over MaxFileSize,
+// a new one is started, and old files are removed according to MaxAge and MaxFiles.
+// IDs are the times of snapshots in nanoseconds since the Unix epoch, increased if needed to be unique.
+type FileStore struct {
+	// Size in bytes after which a new file is started
+	MaxFileSize int64
+	// Files whose all snapshots are older are removed. Zero keeps files regardless of their age.
+	MaxAge time.Duration
+	// Maximum number of files kept, including the current one. Zero keeps all files.
+	MaxFiles int
+	dir      string
+	mu       sync.Mutex
+	// IDs of first snapshots of files, in ascending order
+	files  []int64
+	file   *os.File
+	size   int64
+	lastID int64
+	now    func() time.Time
+}
+
+// OpenFileStore opens the store in given directory, creating the directory if it does not exist
+func OpenFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	s := &FileStore{MaxFileSize: DefaultMaxFileSize, dir: dir, now: time.Now}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		id, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		s.files = append(s.files, id)
+	}
+	sort.Slice(s.files, func(i, j int) bool { return s.files[i] < s.files[j] })
+	if len(s.files) > 0 {
+		current := s.files[len(s.files)-1]
+		s.lastID = current
+		if err := s.scan(current, func(snap *Snapshot) bool {
+			if snap.ID > s.lastID {
+				s.lastID = snap.ID
+			}
+			return true
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Save appends the snapshot to the current file, assigning its ID
+func (s *FileStore) Save(snap *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := snap.Time.UnixNano()
+	if id <= s.lastID {
+		id = s.lastID + 1
+	}
+	snap.ID = id
+	line, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if len(s.files) == 0 || (s.size > 0 && s.size+int64(len(line)) > s.MaxFileSize) {
+		if err := s.rotate(id); err != nil {
+			return err
+		}
+	} else if s.file == nil {
+		if err := s.openCurrent(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	if err != nil {
+		return err
+	}
+	s.lastID = id
+	return s.prune()
+}
+
+// List describes all stored snapshots, oldest first
+func (s *FileStore) List() ([]Info, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	infos := make([]Info, 0)
+	for _, file := range s.files {
+		if err := s.scan(file, func(snap *Snapshot) bool {
+			infos = append(infos, snap.Info())
+			return true
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return infos, nil
+}
+
+// Load returns the snapshot of given ID
+func (s *FileStore) Load(id int64) (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the snapshot can only be in the last file started before it
+	i := sort.Search(len(s.files), func(i int) bool { return s.files[i] > id }) - 1
+	if i < 0 {
+		return nil, errors.Errorf("Snapshot %d not found", id)
+	}
+	var found *Snapshot
+	if err := s.scan(s.files[i], func(snap *Snapshot) bool {
+		if snap.ID == id {
+			found = snap
+			return false
+		}
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, errors.Errorf("Snapshot %d not found", id)
+	}
+	return found, nil
+}
+
+// Prune removes files according to MaxAge and MaxFiles. It is done on every Save as well.
+func (s *FileStore) Prune() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.prune()
+}
+
+// Close closes the current file
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func (s *FileStore) path(id int64) string {
+	return filepath.Join(s.dir, filePrefix+strconv.FormatInt(id, 10)+fileSuffix)
+}
+
+func (s *FileStore) rotate(id int64) error {
+	if s.file != nil {
+		if err := s.file.Close(); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(s.path(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		s.file = nil
+		return err
+	}
+	s.file, s.size = file, 0
+	s.files = append(s.files, id)
+	return nil
+}
+
+// openCurrent opens the last file for appending. A line left incomplete, e.g. by a crash while saving,
+// is terminated first, so that the next snapshot starts on its own line instead of being appended to the fragment.
+func (s *FileStore) openCurrent() error {
+	file, err := os.OpenFile(s.path(s.files[len(s.files)-1]), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	size, err := terminateLastLine(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file, s.size = file, size
+	return nil
+}
+
+// terminateLastLine appends a newline to the file unless it is empty or already ends with one,
+// and returns its size
+func terminateLastLine(file *os.File) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return 0, nil
+	}
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, size-1); err != nil {
+		return 0, err
+	}
+	if last[0] == '\n' {
+		return size, nil
+	}
+	n, err := file.Write([]byte{'\n'})
+	return size + int64(n), err
+}
+
+// prune removes old files; the current file is always kept
+func (s *FileStore) prune() error {
+	var cutoff int64
+	if s.MaxAge > 0 {
+		cutoff = s.now().Add(-s.MaxAge).UnixNano()
+	}
+	removed := 0
+	for i := 0; i < len(s.files)-1; i++ {
+		tooMany := s.MaxFiles > 0 && len(s.files)-i > s.MaxFiles
+		// all snapshots of the file were taken before the next file has been started
+		tooOld := cutoff != 0 && s.files[i+1] < cutoff
+		if !tooMany && !tooOld {
+			break
+		}
+		if err := os.Remove(s.path(s.files[i])); err != nil && !os.IsNotExist(err) {
+			s.files = s.files[removed:]
+			return err
+		}
+		removed++
+	}
+	s.files = s.files[removed:]
+	return nil
+}
+
+// scan decodes snapshots of the file until fn returns false. Lines which cannot be decoded,
+// e.g. truncated by a crash during a write, are skipped.
+func (s *FileStore) scan(file int64, fn func(snap *Snapshot) bool) error {
+	f, err := os.Open(s.path(file))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			snap := &Snapshot{}
+			if json.Unmarshal(line, snap) == nil && !fn(snap) {
+				return nil
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
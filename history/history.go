@@ -0,0 +1,171 @@
+// Package history keeps timestamped snapshots of pgstats views, so that statistics can be compared
+// over time without a time series database.
+//
+// Snapshots are taken with Take, saved in a Store and loaded back into the typed views, e.g.:
+//
+//	snap, _ := history.Take(stats, "pg_stat_database", "pg_stat_user_tables")
+//	_ = store.Save(snap)
+//	...
+//	var databases pgstats.PgStatDatabaseView
+//	_ = snap.Decode("pg_stat_database", &databases)
+package history
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/vynaloze/pgstats"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// DefaultViews are saved by Take when no views are given
+var DefaultViews = []string{
+	"pg_stat_database",
+	"pg_stat_bgwriter",
+	"pg_stat_archiver",
+	"pg_stat_user_tables",
+	"pg_stat_user_indexes",
+	"pg_statio_user_tables",
+	"pg_statio_user_indexes",
+	"pg_stat_statements",
+	"pg_stat_activity",
+}
+
+// Snapshot holds views fetched at a single moment, encoded as JSON
+type Snapshot struct {
+	// Identifier of the snapshot, assigned by the store when saved
+	ID int64 `json:"id"`
+	// Time at which the snapshot was taken
+	Time time.Time `json:"time"`
+	// Name of the database the views have been fetched from
+	Database string `json:"database"`
+	// Size of a disk block of the server in bytes, e.g. to convert block counts to bytes; zero if unknown
+	BlockSize int64 `json:"block_size,omitempty"`
+	// Views by name, as returned by PgStats.Fetch and encoded as JSON
+	Views map[string]json.RawMessage `json:"views"`
+	// Errors of views which could not be fetched, by name
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Info describes a stored snapshot, without its views
+type Info struct {
+	ID       int64     `json:"id"`
+	Time     time.Time `json:"time"`
+	Database string    `json:"database"`
+	// Names of the views held in the snapshot, sorted
+	Views []string `json:"views"`
+}
+
+// Store saves snapshots and loads them back
+type Store interface {
+	// Save stores the snapshot, assigning its ID
+	Save(snap *Snapshot) error
+	// List describes all stored snapshots, oldest first
+	List() ([]Info, error)
+	// Load returns the snapshot of given ID
+	Load(id int64) (*Snapshot, error)
+	// Close releases resources of the store
+	Close() error
+}
+
+// Take fetches given views (or DefaultViews, if none given) into a new snapshot,
+// in a single transaction (see PgStats.Snapshot).
+// Views which cannot be fetched, e.g. pg_stat_statements without the extension, are recorded in Errors;
+// an error is returned only if no view could be fetched.
+func Take(stats *pgstats.PgStats, views ...string) (*Snapshot, error) {
+	if len(views) == 0 {
+		views = DefaultViews
+	}
+	taken, err := stats.Snapshot(views...)
+	if err != nil {
+		return nil, err
+	}
+	snap := &Snapshot{
+		Time:      taken.Time,
+		Database:  stats.DatabaseName(),
+		BlockSize: taken.BlockSize,
+		Views:     make(map[string]json.RawMessage, len(views)),
+		Errors:    make(map[string]string),
+	}
+	var firstErr error
+	for _, name := range views {
+		err, failed := taken.Errors[name]
+		if !failed {
+			err = snap.Set(name, taken.Views[name])
+		}
+		if err != nil {
+			snap.Errors[name] = err.Error()
+			if firstErr == nil {
+				firstErr = errors.Wrapf(err, "Cannot fetch %s", name)
+			}
+		}
+	}
+	if len(snap.Views) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return snap, nil
+}
+
+// Set encodes the view into the snapshot under given name
+func (s *Snapshot) Set(name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if s.Views == nil {
+		s.Views = make(map[string]json.RawMessage)
+	}
+	s.Views[name] = data
+	return nil
+}
+
+// Decode decodes the view of given name into v, which should point to the type returned by PgStats.Fetch,
+// e.g. *PgStatDatabaseView for "pg_stat_database"
+func (s *Snapshot) Decode(name string, v interface{}) error {
+	data, ok := s.Views[name]
+	if !ok {
+		return errors.Errorf("View %s not found in snapshot %d", name, s.ID)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// View returns the view of given name, decoded into the type returned by PgStats.Fetch
+func (s *Snapshot) View(name string) (interface{}, error) {
+	t, err := pgstats.ViewType(name)
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.New(t)
+	if err := s.Decode(name, v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface(), nil
+}
+
+// Info describes the snapshot
+func (s *Snapshot) Info() Info {
+	views := make([]string, 0, len(s.Views))
+	for name := range s.Views {
+		views = append(views, name)
+	}
+	sort.Strings(views)
+	return Info{ID: s.ID, Time: s.Time, Database: s.Database, Views: views}
+}
+
+// LoadPair loads two snapshots of given IDs, e.g. to compute deltas of counters between them.
+// Snapshots are returned in the order they have been saved in.
+func LoadPair(store Store, first int64, second int64) (*Snapshot, *Snapshot, error) {
+	a, err := store.Load(first)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := store.Load(second)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b.ID < a.ID {
+		a, b = b, a
+	}
+	return a, b, nil
+}
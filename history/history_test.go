@@ -0,0 +1,153 @@
+package history
+
+import (
+	"database/sql"
+	"github.com/vynaloze/pgstats"
+	"github.com/vynaloze/pgstats/nullable"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func snapshot(t *testing.T, minutes int, commits int64) *Snapshot {
+	snap := &Snapshot{Time: start.Add(time.Duration(minutes) * time.Minute), Database: "app"}
+	view := pgstats.PgStatDatabaseView{
+		{Datid: 1, Datname: "app", XactCommit: nullable.Int64{NullInt64: sql.NullInt64{Int64: commits, Valid: true}}},
+	}
+	if err := snap.Set("pg_stat_database", view); err != nil {
+		t.Fatal(err)
+	}
+	return snap
+}
+
+func openStore(t *testing.T, dir string) *FileStore {
+	store, err := OpenFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.now = func() time.Time { return start.Add(time.Hour) }
+	return store
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store := openStore(t, dir)
+	for i := 0; i < 3; i++ {
+		if err := store.Save(snapshot(t, i, int64(10*i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// same time as the last snapshot
+	if err := store.Save(snapshot(t, 2, 25)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store = openStore(t, dir)
+	defer store.Close()
+	if err := store.Save(snapshot(t, 1, 30)); err != nil {
+		t.Fatal(err)
+	}
+	infos, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 5 {
+		t.Fatalf("Expected 5 snapshots; actual %d", len(infos))
+	}
+	for i := 1; i < len(infos); i++ {
+		if infos[i].ID <= infos[i-1].ID {
+			t.Errorf("Expected increasing IDs; actual %d after %d", infos[i].ID, infos[i-1].ID)
+		}
+	}
+	if !reflect.DeepEqual(infos[0].Views, []string{"pg_stat_database"}) || infos[0].Database != "app" {
+		t.Errorf("Unexpected info: %+v", infos[0])
+	}
+
+	first, last, err := LoadPair(store, infos[4].ID, infos[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var before pgstats.PgStatDatabaseView
+	if err := first.Decode("pg_stat_database", &before); err != nil {
+		t.Fatal(err)
+	}
+	after, err := last.View("pg_stat_database")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before[0].XactCommit.Int64 != 10 || after.(pgstats.PgStatDatabaseView)[0].XactCommit.Int64 != 30 {
+		t.Errorf("Expected 10 and 30 commits; actual %v and %v", before, after)
+	}
+	if _, err := first.View("pg_stat_bgwriter"); err == nil {
+		t.Error("Expected error for missing view")
+	}
+	if _, err := store.Load(infos[0].ID - 1); err == nil {
+		t.Error("Expected error for unknown snapshot")
+	}
+}
+
+func TestFileStoreRetention(t *testing.T) {
+	dir := t.TempDir()
+	store := openStore(t, dir)
+	defer store.Close()
+	// every snapshot goes to a new file
+	store.MaxFileSize = 1
+	store.MaxFiles = 4
+	store.MaxAge = 45 * time.Minute
+	for i := 0; i < 60; i += 10 {
+		if err := store.Save(snapshot(t, i, int64(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Errorf("Expected 4 files; actual %d", len(entries))
+	}
+
+	// files followed by one started more than 45 minutes ago are removed
+	store.now = func() time.Time { return start.Add(90 * time.Minute) }
+	if err := store.Prune(); err != nil {
+		t.Fatal(err)
+	}
+	infos, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 || infos[0].Time != start.Add(40*time.Minute) {
+		t.Errorf("Expected snapshots since 40 minutes; actual %+v", infos)
+	}
+}
+
+func TestTruncatedLine(t *testing.T) {
+	dir := t.TempDir()
+	store := openStore(t, dir)
+	if err := store.Save(snapshot(t, 0, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.file.WriteString(`{"id":1,"time":`); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	store = openStore(t, dir)
+	defer store.Close()
+	if err := store.Save(snapshot(t, 1, 2)); err != nil {
+		t.Fatal(err)
+	}
+	infos, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 || infos[1].Time != start.Add(time.Minute) {
+		t.Errorf("Expected the truncated line to be skipped and both snapshots listed; actual %+v", infos)
+	}
+}
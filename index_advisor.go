@@ -0,0 +1,242 @@
+package pgstats
+
+import (
+	"github.com/vynaloze/pgstats/nullable"
+	"sort"
+	"strings"
+)
+
+// Reasons for which an index is reported by the index advisor
+const (
+	// IndexInvalid marks indexes left invalid by a failed CREATE INDEX CONCURRENTLY or REINDEX
+	IndexInvalid = "invalid"
+	// IndexDuplicate marks indexes identical to another index of the same table
+	IndexDuplicate = "duplicate"
+	// IndexRedundant marks indexes whose columns are a left prefix of another index of the same table
+	IndexRedundant = "redundant"
+	// IndexUnused marks indexes never scanned since statistics were last reset
+	IndexUnused = "unused"
+)
+
+// IndexAdviceView represents indexes in the current database which are candidates for removal
+type IndexAdviceView struct {
+	// Time at which statistics of the current database were last reset, i.e. since when indexes are unused
+	StatsReset nullable.Time `json:"stats_reset"`
+	// Total size of all reported indexes, in bytes
+	TotalSize int64 `json:"total_size"`
+	// Reported indexes, ordered by size (largest first)
+	Indexes []IndexAdviceRow `json:"indexes"`
+}
+
+// IndexAdviceRow represents a single index which is a candidate for removal
+type IndexAdviceRow struct {
+	// OID of the table for this index
+	Relid int64 `json:"relid"`
+	// OID of this index
+	Indexrelid int64 `json:"indexrelid"`
+	// Name of the schema this index is in
+	Schemaname string `json:"schemaname"`
+	// Name of the table for this index
+	Relname string `json:"relname"`
+	// Name of this index
+	Indexrelname string `json:"indexrelname"`
+	// Reason for which the index is reported: invalid, duplicate, redundant or unused
+	Reason string `json:"reason"`
+	// Name of the index which makes this index duplicate or redundant
+	CoveringIndex nullable.String `json:"covering_index"`
+	// Number of index scans initiated on this index
+	IdxScan int64 `json:"idx_scan"`
+	// Size of this index, in bytes
+	Size int64 `json:"size"`
+	// Definition of this index (CREATE INDEX command)
+	Definition string `json:"definition"`
+}
+
+// indexDefinition holds everything needed to compare an index with other indexes of the same table
+type indexDefinition struct {
+	row IndexAdviceRow
+	// access method, e.g. btree
+	am        string
+	unique    bool
+	valid     bool
+	backsCons bool
+	// key columns only, not INCLUDE-d ones
+	keyColumns []string
+	// all columns, including INCLUDE-d ones
+	columns     []string
+	opclasses   []string
+	collations  []string
+	options     []string
+	expressions string
+	predicate   string
+}
+
+func (d indexDefinition) signature() string {
+	return strings.Join([]string{d.am, strings.Join(d.columns, " "), strings.Join(d.opclasses, " "),
+		strings.Join(d.collations, " "), strings.Join(d.options, " "), d.expressions, d.predicate}, "|")
+}
+
+// isLeftPrefixOf checks whether all key columns of d are a strict left prefix of key columns of other,
+// so that other can serve every lookup d can
+func (d indexDefinition) isLeftPrefixOf(other indexDefinition) bool {
+	if d.am != "btree" || other.am != "btree" || d.unique || d.expressions != "" || other.expressions != "" ||
+		d.predicate != other.predicate || len(d.columns) != len(d.keyColumns) || len(d.keyColumns) >= len(other.keyColumns) {
+		return false
+	}
+	for i := range d.keyColumns {
+		if d.keyColumns[i] != other.keyColumns[i] || d.opclasses[i] != other.opclasses[i] ||
+			d.collations[i] != other.collations[i] || d.options[i] != other.options[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *PgStats) fetchIndexAdvice() (IndexAdviceView, error) {
+	version, err := s.getPgVersion()
+	if err != nil {
+		return IndexAdviceView{}, err
+	}
+	keyAtts := "i.indnatts"
+	if version >= 11 {
+		keyAtts = "i.indnkeyatts"
+	}
+
+	db := s.db()
+	res := IndexAdviceView{}
+	row := db.QueryRow("select stats_reset from pg_stat_database where datname=current_database()")
+	if err := row.Scan(&res.StatsReset); err != nil {
+		return IndexAdviceView{}, err
+	}
+
+	query := "select i.indrelid,i.indexrelid,n.nspname,t.relname,c.relname," +
+		"coalesce(st.idx_scan,0),pg_relation_size(i.indexrelid),pg_get_indexdef(i.indexrelid)," +
+		"am.amname,i.indisunique,i.indisvalid," +
+		"exists(select 1 from pg_constraint where conindid=i.indexrelid)," +
+		keyAtts + ",i.indkey::text,i.indclass::text,i.indcollation::text,i.indoption::text," +
+		"coalesce(pg_get_expr(i.indexprs,i.indrelid),''),coalesce(pg_get_expr(i.indpred,i.indrelid),'') " +
+		"from pg_index i join pg_class c on c.oid=i.indexrelid join pg_class t on t.oid=i.indrelid " +
+		"join pg_namespace n on n.oid=c.relnamespace join pg_am am on am.oid=c.relam " +
+		"left join pg_stat_all_indexes st on st.indexrelid=i.indexrelid " +
+		"where " + userSchemas("n")
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return IndexAdviceView{}, err
+	}
+	defer rows.Close()
+
+	defs := make([]indexDefinition, 0)
+	for rows.Next() {
+		d := indexDefinition{}
+		var keyAtts int
+		var indkey, indclass, indcollation, indoption string
+		err := rows.Scan(&d.row.Relid, &d.row.Indexrelid, &d.row.Schemaname, &d.row.Relname, &d.row.Indexrelname,
+			&d.row.IdxScan, &d.row.Size, &d.row.Definition,
+			&d.am, &d.unique, &d.valid,
+			&d.backsCons,
+			&keyAtts, &indkey, &indclass, &indcollation, &indoption,
+			&d.expressions, &d.predicate)
+		if err != nil {
+			return IndexAdviceView{}, err
+		}
+		d.columns = strings.Fields(indkey)
+		if keyAtts <= len(d.columns) {
+			d.keyColumns = d.columns[:keyAtts]
+		}
+		d.opclasses = strings.Fields(indclass)
+		d.collations = strings.Fields(indcollation)
+		d.options = strings.Fields(indoption)
+		defs = append(defs, d)
+	}
+	if err := rows.Err(); err != nil {
+		return IndexAdviceView{}, err
+	}
+
+	res.Indexes = adviseIndexes(defs)
+	for _, i := range res.Indexes {
+		res.TotalSize += i.Size
+	}
+	return res, nil
+}
+
+// adviseIndexes reports each index at most once, with the most significant reason:
+// invalid, duplicate, redundant and unused, in that order.
+// Indexes backing constraints are never reported as duplicate, redundant nor unused.
+func adviseIndexes(defs []indexDefinition) []IndexAdviceRow {
+	byTable := make(map[int64][]indexDefinition)
+	for _, d := range defs {
+		byTable[d.row.Relid] = append(byTable[d.row.Relid], d)
+	}
+
+	res := make([]IndexAdviceRow, 0)
+	for _, d := range defs {
+		row := d.row
+		switch {
+		case !d.valid:
+			row.Reason = IndexInvalid
+		case d.backsCons:
+			continue
+		default:
+			if covering, ok := duplicateOf(d, byTable[d.row.Relid]); ok {
+				row.Reason = IndexDuplicate
+				row.CoveringIndex.Valid, row.CoveringIndex.String = true, covering
+			} else if covering, ok := redundantTo(d, byTable[d.row.Relid]); ok {
+				row.Reason = IndexRedundant
+				row.CoveringIndex.Valid, row.CoveringIndex.String = true, covering
+			} else if d.row.IdxScan == 0 && !d.unique {
+				row.Reason = IndexUnused
+			} else {
+				continue
+			}
+		}
+		res = append(res, row)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Size > res[j].Size
+	})
+	return res
+}
+
+// duplicateOf returns the name of the index which should be kept instead of d, if d has any identical twin.
+// Of identical indexes, the one backing a constraint is kept, then a unique one,
+// then the one scanned most often, then the oldest.
+func duplicateOf(d indexDefinition, siblings []indexDefinition) (string, bool) {
+	keep := d
+	for _, o := range siblings {
+		if !o.valid || o.signature() != d.signature() {
+			continue
+		}
+		if preferredIndex(o, keep) {
+			keep = o
+		}
+	}
+	if keep.row.Indexrelid == d.row.Indexrelid {
+		return "", false
+	}
+	return keep.row.Indexrelname, true
+}
+
+func preferredIndex(a indexDefinition, b indexDefinition) bool {
+	if a.backsCons != b.backsCons {
+		return a.backsCons
+	}
+	if a.unique != b.unique {
+		return a.unique
+	}
+	if a.row.IdxScan != b.row.IdxScan {
+		return a.row.IdxScan > b.row.IdxScan
+	}
+	return a.row.Indexrelid < b.row.Indexrelid
+}
+
+// redundantTo returns the name of an index whose key columns start with all key columns of d
+func redundantTo(d indexDefinition, siblings []indexDefinition) (string, bool) {
+	for _, o := range siblings {
+		if o.valid && d.isLeftPrefixOf(o) {
+			return o.row.Indexrelname, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,84 @@
+package pgstats
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAdviseIndexes(t *testing.T) {
+	pkey := btreeIndex(1, "t_pkey", "1", 10, 100)
+	pkey.unique, pkey.backsCons = true, true
+	dupOfPkey := btreeIndex(2, "t_id_idx", "1", 5, 200)
+	prefix := btreeIndex(3, "t_a_idx", "2", 7, 300)
+	wider := btreeIndex(4, "t_a_b_idx", "2 3", 3, 400)
+	unused := btreeIndex(5, "t_c_idx", "4", 0, 500)
+	invalid := btreeIndex(6, "t_d_idx", "5", 0, 600)
+	invalid.valid = false
+	partial := btreeIndex(7, "t_a_partial_idx", "2", 2, 50)
+	partial.predicate = "(c > 0)"
+	uniqueUnused := btreeIndex(8, "t_e_key", "6", 0, 700)
+	uniqueUnused.unique = true
+	desc := btreeIndex(9, "t_a_desc_idx", "2", 1, 60)
+	desc.options = []string{"3"}
+
+	res := adviseIndexes([]indexDefinition{pkey, dupOfPkey, prefix, wider, unused, invalid, partial, uniqueUnused, desc})
+
+	expected := map[string]string{
+		"t_d_idx":  IndexInvalid,
+		"t_c_idx":  IndexUnused,
+		"t_id_idx": IndexDuplicate,
+		"t_a_idx":  IndexRedundant,
+	}
+	if len(res) != len(expected) {
+		t.Fatalf("Expected %d indexes; actual %+v", len(expected), res)
+	}
+	for _, r := range res {
+		if expected[r.Indexrelname] != r.Reason {
+			t.Errorf("Expected %s to be %s; actual %s", r.Indexrelname, expected[r.Indexrelname], r.Reason)
+		}
+	}
+	if res[0].Indexrelname != "t_d_idx" || res[3].Indexrelname != "t_id_idx" {
+		t.Errorf("Expected indexes ordered by size; actual %+v", res)
+	}
+	if res[2].CoveringIndex.String != "t_a_b_idx" {
+		t.Errorf("Expected t_a_idx to be covered by t_a_b_idx; actual %+v", res[2].CoveringIndex)
+	}
+	if res[3].CoveringIndex.String != "t_pkey" {
+		t.Errorf("Expected t_id_idx to be covered by t_pkey; actual %+v", res[3].CoveringIndex)
+	}
+}
+
+func TestAdviseIndexesDuplicates(t *testing.T) {
+	a := btreeIndex(1, "a", "1 2", 0, 100)
+	b := btreeIndex(2, "b", "1 2", 5, 100)
+	c := btreeIndex(3, "c", "1 2", 5, 100)
+	included := btreeIndex(4, "d", "1 2 3", 5, 100)
+	included.keyColumns = included.columns[:2]
+
+	res := adviseIndexes([]indexDefinition{a, b, c, included})
+	if len(res) != 2 {
+		t.Fatalf("Expected 2 duplicates; actual %+v", res)
+	}
+	for _, r := range res {
+		if r.Reason != IndexDuplicate || r.CoveringIndex.String != "b" {
+			t.Errorf("Expected %s to be a duplicate of b; actual %+v", r.Indexrelname, r)
+		}
+	}
+}
+
+func btreeIndex(oid int64, name string, columns string, scans int64, size int64) indexDefinition {
+	d := indexDefinition{
+		row: IndexAdviceRow{Relid: 100, Indexrelid: oid, Schemaname: "public", Relname: "t",
+			Indexrelname: name, IdxScan: scans, Size: size},
+		am:      "btree",
+		valid:   true,
+		columns: strings.Fields(columns),
+	}
+	d.keyColumns = d.columns
+	for range d.columns {
+		d.opclasses = append(d.opclasses, "1978")
+		d.collations = append(d.collations, "0")
+		d.options = append(d.options, "0")
+	}
+	return d
+}
@@ -0,0 +1,174 @@
+// Command schemagen generates help texts and kinds (counter or gauge) of metrics
+// from doc comments of struct fields in pgstats packages.
+//
+// Usage:
+//
+//	schemagen -out docs.go dir...
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// counterPrefixes are beginnings of doc comments of cumulative statistics
+var counterPrefixes = []string{"number of", "total", "time spent", "amount of"}
+
+// gaugeMarkers are words which make a doc comment describe current state, even if it starts with a counter prefix
+var gaugeMarkers = []string{"current", "estimated", "approximate", "size"}
+
+// overrides force the kind of metrics the heuristics get wrong
+var overrides = map[string]string{
+	"pgstats.PgStatSslRow.Bits": "Gauge",
+}
+
+type field struct {
+	key  string
+	help string
+	kind string
+}
+
+func main() {
+	out := flag.String("out", "docs.go", "output file")
+	flag.Parse()
+
+	fields := make([]field, 0)
+	for _, dir := range flag.Args() {
+		f, err := parseDir(dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fields = append(fields, f...)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].key < fields[j].key
+	})
+
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Code generated by schemagen. DO NOT EDIT.")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "package schema")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "var docs = map[string]doc{")
+	for _, f := range fields {
+		fmt.Fprintf(&buf, "%q: {%q, %s},\n", f.key, f.help, f.kind)
+	}
+	fmt.Fprintln(&buf, "}")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func parseDir(dir string) ([]field, error) {
+	fset := token.NewFileSet()
+	notTest := func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}
+	pkgs, err := parser.ParseDir(fset, dir, notTest, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]field, 0)
+	for name, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok || !ts.Name.IsExported() {
+						continue
+					}
+					fields = append(fields, structFields(name, ts.Name.Name, st)...)
+				}
+			}
+		}
+	}
+	return fields, nil
+}
+
+func structFields(pkg string, typeName string, st *ast.StructType) []field {
+	fields := make([]field, 0)
+	for _, f := range st.Fields.List {
+		if f.Doc == nil || isString(f.Type) {
+			continue
+		}
+		help := docText(f.Doc)
+		for _, name := range f.Names {
+			// unexported fields are never flattened into metrics
+			if !name.IsExported() {
+				continue
+			}
+			key := pkg + "." + typeName + "." + name.Name
+			fields = append(fields, field{key: key, help: help, kind: kind(key, typeName, help)})
+		}
+	}
+	return fields
+}
+
+func isString(expr ast.Expr) bool {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name == "string"
+	case *ast.SelectorExpr:
+		return t.Sel.Name == "String"
+	}
+	return false
+}
+
+// docText joins lines of the comment into a single line,
+// ending the sentences which are not terminated before the next one starts
+func docText(doc *ast.CommentGroup) string {
+	lines := strings.Split(strings.TrimSpace(doc.Text()), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+		if i > 0 && lines[i] != "" && lines[i-1] != "" && unicode.IsUpper(rune(lines[i][0])) && !strings.ContainsAny(lines[i-1][len(lines[i-1])-1:], ".,;:-") {
+			lines[i-1] += "."
+		}
+	}
+	return strings.Join(lines, " ")
+}
+
+// kind recognizes cumulative statistics of pg_stat_* views by their doc comments.
+// Progress reports and deltas between snapshots are never cumulative.
+func kind(key string, typeName string, help string) string {
+	if k, ok := overrides[key]; ok {
+		return k
+	}
+	if !strings.HasPrefix(typeName, "PgStat") || strings.HasPrefix(typeName, "PgStatProgress") ||
+		strings.Contains(typeName, "Delta") {
+		return "Gauge"
+	}
+	lower := strings.ToLower(help)
+	for _, marker := range gaugeMarkers {
+		if strings.Contains(lower, marker) {
+			return "Gauge"
+		}
+	}
+	for _, prefix := range counterPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return "Counter"
+		}
+	}
+	return "Gauge"
+}
@@ -0,0 +1,387 @@
+// Code generated by schemagen. DO NOT EDIT.
+
+package schema
+
+var docs = map[string]doc{
+	"ash.Load.ActiveTime":                                        {"Estimated time spent active, i.e. number of samples multiplied by the sampling interval", Gauge},
+	"ash.Load.AverageActiveSessions":                             {"Average number of active sessions attributed to the key within the window", Gauge},
+	"ash.Load.Samples":                                           {"Number of samples attributed to the key", Gauge},
+	"ash.QueryLoad.QueryId":                                      {"Identifier of the query (zero if query identifiers are not available)", Gauge},
+	"ash.Sample.Pid":                                             {"Process ID of the backend", Gauge},
+	"ash.Sample.QueryId":                                         {"Identifier of the query being executed (zero before PostgreSQL 14 or if compute_query_id is disabled)", Gauge},
+	"ash.Sample.Time":                                            {"Time at which the sample was taken", Gauge},
+	"ash.Sampler.Filter":                                         {"Filter decides which backends are recorded. Defaults to DefaultFilter.", Gauge},
+	"health.CacheHitRatio.Critical":                              {"Ratio below which a critical finding is reported", Gauge},
+	"health.CacheHitRatio.MinBlocks":                             {"Databases with fewer accessed blocks are skipped, as their ratio is not meaningful", Gauge},
+	"health.CacheHitRatio.Warning":                               {"Ratio below which a warning is reported", Gauge},
+	"health.DeadTuples.Critical":                                 {"Fraction of dead rows above which a critical finding is reported", Gauge},
+	"health.DeadTuples.MinDeadTuples":                            {"Tables with fewer dead rows are skipped", Gauge},
+	"health.DeadTuples.Warning":                                  {"Fraction of dead rows above which a warning is reported", Gauge},
+	"health.Finding.Objects":                                     {"Names of the offending objects (databases, tables, indexes, backends, ...)", Gauge},
+	"health.Finding.Severity":                                    {"Severity of the finding", Gauge},
+	"health.IdleInTransaction.Critical":                          {"Duration above which a critical finding is reported", Gauge},
+	"health.IdleInTransaction.Warning":                           {"Duration above which a warning is reported", Gauge},
+	"health.RequestedCheckpoints.Critical":                       {"Fraction of requested checkpoints above which a critical finding is reported", Gauge},
+	"health.RequestedCheckpoints.MinCheckpoints":                 {"The rule is skipped if fewer checkpoints have been performed in total", Gauge},
+	"health.RequestedCheckpoints.Warning":                        {"Fraction of requested checkpoints above which a warning is reported", Gauge},
+	"health.Snapshot.Time":                                       {"Time at which the snapshot was taken", Gauge},
+	"pgstats.AutovacuumQueueRow.AnalyzeRatio":                    {"NModSinceAnalyze as a fraction of AnalyzeThreshold. Above 1, the table is due to be analyzed. Always zero for TOAST tables.", Gauge},
+	"pgstats.AutovacuumQueueRow.AnalyzeThreshold":                {"Number of modified rows above which autovacuum analyzes this table: autovacuum_analyze_threshold + autovacuum_analyze_scale_factor * reltuples, taking table storage parameters into account", Gauge},
+	"pgstats.AutovacuumQueueRow.Enabled":                         {"True if autovacuum is enabled both globally and for this table", Gauge},
+	"pgstats.AutovacuumQueueRow.InsertRatio":                     {"NInsSinceVacuum as a fraction of InsertThreshold. Above 1, the table is due to be vacuumed. Supported since PostgreSQL 13", Gauge},
+	"pgstats.AutovacuumQueueRow.InsertThreshold":                 {"Number of inserted rows above which autovacuum vacuums this table: autovacuum_vacuum_insert_threshold + autovacuum_vacuum_insert_scale_factor * reltuples, taking table storage parameters into account. Null if insert-driven vacuum is disabled (threshold -1). Supported since PostgreSQL 13", Gauge},
+	"pgstats.AutovacuumQueueRow.LastAutoanalyze":                 {"Last time at which this table was analyzed by the autovacuum daemon", Gauge},
+	"pgstats.AutovacuumQueueRow.LastAutovacuum":                  {"Last time at which this table was vacuumed by the autovacuum daemon", Gauge},
+	"pgstats.AutovacuumQueueRow.NDeadTup":                        {"Estimated number of dead rows", Gauge},
+	"pgstats.AutovacuumQueueRow.NInsSinceVacuum":                 {"Estimated number of rows inserted since this table was last vacuumed. Supported since PostgreSQL 13", Gauge},
+	"pgstats.AutovacuumQueueRow.NModSinceAnalyze":                {"Estimated number of rows modified since this table was last analyzed", Gauge},
+	"pgstats.AutovacuumQueueRow.Relid":                           {"OID of a table", Gauge},
+	"pgstats.AutovacuumQueueRow.Reltuples":                       {"Estimated number of live rows, as recorded by the last vacuum or analyze (pg_class.reltuples)", Gauge},
+	"pgstats.AutovacuumQueueRow.VacuumPid":                       {"Process ID of the backend currently vacuuming this table, if any. Supported since PostgreSQL 9.6", Gauge},
+	"pgstats.AutovacuumQueueRow.VacuumRatio":                     {"NDeadTup as a fraction of VacuumThreshold. Above 1, the table is due to be vacuumed.", Gauge},
+	"pgstats.AutovacuumQueueRow.VacuumThreshold":                 {"Number of dead rows above which autovacuum vacuums this table: autovacuum_vacuum_threshold + autovacuum_vacuum_scale_factor * reltuples, taking table storage parameters into account", Gauge},
+	"pgstats.ConnectionGroup.Active":                             {"Number of active connections in this group", Gauge},
+	"pgstats.ConnectionGroup.ConnLimit":                          {"Connection limit of the database or role, if set (datconnlimit or rolconnlimit)", Gauge},
+	"pgstats.ConnectionGroup.Count":                              {"Number of connections in this group", Gauge},
+	"pgstats.ConnectionGroup.Idle":                               {"Number of idle connections in this group", Gauge},
+	"pgstats.ConnectionGroup.IdleInTransaction":                  {"Number of connections idle in transaction (including aborted ones) in this group", Gauge},
+	"pgstats.ConnectionGroup.Usage":                              {"Count as a fraction of ConnLimit, if set", Gauge},
+	"pgstats.ConnectionThresholds.Idle":                          {"Duration after which an idle connection is reported", Gauge},
+	"pgstats.ConnectionThresholds.IdleInTransaction":             {"Duration after which a connection idle in transaction is reported", Gauge},
+	"pgstats.ConnectionsReportView.Available":                    {"Number of connection slots still available to non-superusers", Gauge},
+	"pgstats.ConnectionsReportView.ByApplication":                {"Connections grouped by application name", Gauge},
+	"pgstats.ConnectionsReportView.ByClientAddr":                 {"Connections grouped by client address: \"local\" for Unix socket connections, \"unknown\" for connections of other users whose details are hidden from the current user (lacking pg_read_all_stats privileges)", Gauge},
+	"pgstats.ConnectionsReportView.ByDatabase":                   {"Connections grouped by database, with per-database connection limits", Gauge},
+	"pgstats.ConnectionsReportView.ByState":                      {"Connections grouped by state", Gauge},
+	"pgstats.ConnectionsReportView.ByUser":                       {"Connections grouped by user, with per-role connection limits", Gauge},
+	"pgstats.ConnectionsReportView.Idle":                         {"Connections idle for longer than the threshold, longest first", Gauge},
+	"pgstats.ConnectionsReportView.IdleInTransaction":            {"Connections idle in transaction for longer than the threshold, longest first", Gauge},
+	"pgstats.ConnectionsReportView.MaxConnections":               {"Maximum number of concurrent connections to the server (max_connections)", Gauge},
+	"pgstats.ConnectionsReportView.SuperuserReservedConnections": {"Number of connection slots reserved for superusers (superuser_reserved_connections)", Gauge},
+	"pgstats.ConnectionsReportView.Time":                         {"Server time at which the report was made", Gauge},
+	"pgstats.ConnectionsReportView.Total":                        {"Number of client connections", Gauge},
+	"pgstats.ConnectionsReportView.Usage":                        {"Total as a fraction of connection slots available to non-superusers", Gauge},
+	"pgstats.DatabaseGrowthRow.Datid":                            {"OID of a database", Gauge},
+	"pgstats.DatabaseGrowthRow.Growth":                           {"Change in disk space used by this database between the snapshots, in bytes", Gauge},
+	"pgstats.DatabaseGrowthRow.Rate":                             {"Average growth rate between the snapshots, in bytes per second", Gauge},
+	"pgstats.DatabaseGrowthRow.Size":                             {"Disk space used by this database at the time of the later snapshot, in bytes", Gauge},
+	"pgstats.DatabaseSizeRow.Datid":                              {"OID of a database", Gauge},
+	"pgstats.DatabaseSizeRow.Size":                               {"Disk space used by this database, in bytes. Null if the user is not allowed to connect to this database.", Gauge},
+	"pgstats.DatabaseSizeRow.Time":                               {"Server time at which the size was measured", Gauge},
+	"pgstats.DatabaseXidAgeRow.Datallowconn":                     {"False if connections to this database are not allowed, e.g. template0. Such a database can only be vacuumed by autovacuum, unless connections are allowed first.", Gauge},
+	"pgstats.DatabaseXidAgeRow.Datid":                            {"OID of a database", Gauge},
+	"pgstats.DatabaseXidAgeRow.FreezeMaxAgeRatio":                {"XidAge as a fraction of autovacuum_freeze_max_age. Above 1, anti-wraparound autovacuum is forced on the oldest tables.", Gauge},
+	"pgstats.DatabaseXidAgeRow.MultixactFreezeMaxAgeRatio":       {"MxidAge as a fraction of autovacuum_multixact_freeze_max_age. Supported since PostgreSQL 9.5", Gauge},
+	"pgstats.DatabaseXidAgeRow.MxidAge":                          {"Age of the oldest unfrozen multixact ID in this database - mxid_age(datminmxid). Supported since PostgreSQL 9.5", Gauge},
+	"pgstats.DatabaseXidAgeRow.WraparoundRatio":                  {"XidAge as a fraction of transaction IDs available before wraparound. The server stops accepting commands shortly before it reaches 1.", Gauge},
+	"pgstats.DatabaseXidAgeRow.XidAge":                           {"Age of the oldest unfrozen transaction ID in this database - age(datfrozenxid)", Gauge},
+	"pgstats.EnforcementAction.Age":                              {"Age of the backend relevant for the exceeded threshold", Gauge},
+	"pgstats.EnforcementAction.BackendStart":                     {"Time when the backend was started", Gauge},
+	"pgstats.EnforcementAction.DryRun":                           {"True if the action was only planned", Gauge},
+	"pgstats.EnforcementAction.Pid":                              {"Process ID of the backend", Gauge},
+	"pgstats.EnforcementAction.Success":                          {"True if the signal was successfully sent to the backend", Gauge},
+	"pgstats.EnforcementAction.Time":                             {"Time at which the action was taken", Gauge},
+	"pgstats.EnforcementAction.XactStart":                        {"Time when the backend's transaction was started, or null if no transaction was active", Gauge},
+	"pgstats.EnforcementPolicy.AuditLog":                         {"If set, every action is written to it as a line of JSON", Gauge},
+	"pgstats.EnforcementPolicy.DryRun":                           {"If set, actions are only planned and logged, but not taken", Gauge},
+	"pgstats.EnforcementPolicy.ExcludeApplications":              {"Names of applications whose backends are never touched", Gauge},
+	"pgstats.EnforcementPolicy.ExcludeUsers":                     {"Names of users whose backends are never touched", Gauge},
+	"pgstats.EnforcementPolicy.Rules":                            {"Rules determining the actions. If several rules match a backend, terminate wins over cancel.", Gauge},
+	"pgstats.EnforcementPolicy.Thresholds":                       {"Thresholds above which backends are considered long running", Gauge},
+	"pgstats.EnforcementRule.After":                              {"Minimum age of the backend for the action to be taken. If zero, the action is taken as soon as the threshold is exceeded.", Gauge},
+	"pgstats.IdleConnection.Duration":                            {"Time since the state was last changed", Gauge},
+	"pgstats.IdleConnection.Pid":                                 {"Process ID of the backend", Gauge},
+	"pgstats.IndexAdviceRow.IdxScan":                             {"Number of index scans initiated on this index", Gauge},
+	"pgstats.IndexAdviceRow.Indexrelid":                          {"OID of this index", Gauge},
+	"pgstats.IndexAdviceRow.Relid":                               {"OID of the table for this index", Gauge},
+	"pgstats.IndexAdviceRow.Size":                                {"Size of this index, in bytes", Gauge},
+	"pgstats.IndexAdviceView.Indexes":                            {"Reported indexes, ordered by size (largest first)", Gauge},
+	"pgstats.IndexAdviceView.StatsReset":                         {"Time at which statistics of the current database were last reset, i.e. since when indexes are unused", Gauge},
+	"pgstats.IndexAdviceView.TotalSize":                          {"Total size of all reported indexes, in bytes", Gauge},
+	"pgstats.IndexBloatRow.BloatRatio":                           {"BloatSize as a fraction of Size", Gauge},
+	"pgstats.IndexBloatRow.BloatSize":                            {"Estimated space wasted by leaf pages filled below the fillfactor, in bytes", Gauge},
+	"pgstats.IndexBloatRow.Indexrelid":                           {"OID of this index", Gauge},
+	"pgstats.IndexBloatRow.Precise":                              {"True if the value was measured with pgstattuple rather than estimated from statistics", Gauge},
+	"pgstats.IndexBloatRow.Reliable":                             {"False if the estimate is known to be inaccurate (the index has columns of type name)", Gauge},
+	"pgstats.IndexBloatRow.Relid":                                {"OID of the table for this index", Gauge},
+	"pgstats.IndexBloatRow.Size":                                 {"Size of this index, in bytes", Gauge},
+	"pgstats.LongRunningRow.BackendStart":                        {"Time when this process was started, identifying the backend together with its process ID", Gauge},
+	"pgstats.LongRunningRow.Exceeded":                            {"Thresholds exceeded by this backend: query, transaction and/or idle_in_transaction", Gauge},
+	"pgstats.LongRunningRow.IdleInTransactionAge":                {"Time since the backend became idle in transaction; zero if it is not idle in transaction", Gauge},
+	"pgstats.LongRunningRow.Pid":                                 {"Process ID of this backend", Gauge},
+	"pgstats.LongRunningRow.QueryAge":                            {"Time since the currently active query was started; zero if no query is active", Gauge},
+	"pgstats.LongRunningRow.XactAge":                             {"Time since the current transaction was started; zero if no transaction is open", Gauge},
+	"pgstats.LongRunningRow.XactStart":                           {"Time when this process' current transaction was started, or null if no transaction is active", Gauge},
+	"pgstats.LongRunningThresholds.IdleInTransaction":            {"Maximum duration of staying idle in transaction", Gauge},
+	"pgstats.LongRunningThresholds.Query":                        {"Maximum duration of an active query", Gauge},
+	"pgstats.LongRunningThresholds.Transaction":                  {"Maximum duration of a transaction", Gauge},
+	"pgstats.PgStatActivityRow.BackendStart":                     {"Time when this process was started. For client backends, this is the time the client connected to the server.", Gauge},
+	"pgstats.PgStatActivityRow.BackendXid":                       {"Top-level transaction identifier of this backend, if any.", Gauge},
+	"pgstats.PgStatActivityRow.BackendXmin":                      {"The current backend's xmin horizon.", Gauge},
+	"pgstats.PgStatActivityRow.ClientPort":                       {"TCP port number that the client is using for communication with this backend, or -1 if a Unix socket is used", Gauge},
+	"pgstats.PgStatActivityRow.Datid":                            {"OID of the database this backend is connected to", Gauge},
+	"pgstats.PgStatActivityRow.Pid":                              {"Process ID of this backend", Gauge},
+	"pgstats.PgStatActivityRow.QueryId":                          {"Identifier of this backend's most recent query. Available only if compute_query_id is enabled or a third-party module that computes query identifiers is configured. Supported since PostgreSQL 14", Gauge},
+	"pgstats.PgStatActivityRow.QueryStart":                       {"Time when the currently active query was started, or if state is not active, when the last query was started", Gauge},
+	"pgstats.PgStatActivityRow.StateChange":                      {"ime when the state was last changed", Gauge},
+	"pgstats.PgStatActivityRow.Usesysid":                         {"OID of the user logged into this backend", Gauge},
+	"pgstats.PgStatActivityRow.Waiting":                          {"True if this backend is currently waiting on a lock. Supported until PostgreSQL 9.5 (inclusive).", Gauge},
+	"pgstats.PgStatActivityRow.XactStart":                        {"Time when this process' current transaction was started, or null if no transaction is active. If the current query is the first of its transaction, this column is equal to the query_start column.", Gauge},
+	"pgstats.PgStatArchiverView.ArchivedCount":                   {"Number of WAL files that have been successfully archived", Counter},
+	"pgstats.PgStatArchiverView.FailedCount":                     {"Number of failed attempts for archiving WAL files", Counter},
+	"pgstats.PgStatArchiverView.LastArchivedTime":                {"Time of the last successful archive operation", Gauge},
+	"pgstats.PgStatArchiverView.LastFailedTime":                  {"Time of the last failed archival operation", Gauge},
+	"pgstats.PgStatArchiverView.StatsReset":                      {"Time at which these statistics were last reset", Gauge},
+	"pgstats.PgStatBgWriterView.BuffersAlloc":                    {"Number of buffers allocated", Counter},
+	"pgstats.PgStatBgWriterView.BuffersBackend":                  {"Number of buffers written directly by a backend", Counter},
+	"pgstats.PgStatBgWriterView.BuffersBackendFsync":             {"Number of times a backend had to execute its own fsync call (normally the background writer handles those even when the backend does its own write)", Counter},
+	"pgstats.PgStatBgWriterView.BuffersCheckpoint":               {"Number of buffers written during checkpoints", Counter},
+	"pgstats.PgStatBgWriterView.BuffersClean":                    {"Number of buffers written by the background writer", Counter},
+	"pgstats.PgStatBgWriterView.CheckpointSyncTime":              {"Total amount of time that has been spent in the portion of checkpoint processing where files are synchronized to disk, in milliseconds", Counter},
+	"pgstats.PgStatBgWriterView.CheckpointWriteTime":             {"Total amount of time that has been spent in the portion of checkpoint processing where files are written to disk, in milliseconds", Counter},
+	"pgstats.PgStatBgWriterView.CheckpointsReq":                  {"Number of requested checkpoints that have been performed", Counter},
+	"pgstats.PgStatBgWriterView.CheckpointsTimed":                {"Number of scheduled checkpoints that have been performed", Counter},
+	"pgstats.PgStatBgWriterView.MaxWrittenClean":                 {"Number of times the background writer stopped a cleaning scan because it had written too many buffers", Counter},
+	"pgstats.PgStatBgWriterView.StatsReset":                      {"Time at which these statistics were last reset", Gauge},
+	"pgstats.PgStatDatabaseConflictsRow.ConflBufferpin":          {"Number of queries in this database that have been canceled due to pinned buffers", Counter},
+	"pgstats.PgStatDatabaseConflictsRow.ConflDeadlock":           {"Number of queries in this database that have been canceled due to deadlocks", Counter},
+	"pgstats.PgStatDatabaseConflictsRow.ConflLock":               {"Number of queries in this database that have been canceled due to lock timeouts", Counter},
+	"pgstats.PgStatDatabaseConflictsRow.ConflSnapshot":           {"Number of queries in this database that have been canceled due to old snapshots", Counter},
+	"pgstats.PgStatDatabaseConflictsRow.ConflTablespace":         {"Number of queries in this database that have been canceled due to dropped tablespaces", Counter},
+	"pgstats.PgStatDatabaseConflictsRow.Datid":                   {"OID of a database", Gauge},
+	"pgstats.PgStatDatabaseRow.BlkReadTime":                      {"Time spent reading data file blocks by backends in this database, in milliseconds", Counter},
+	"pgstats.PgStatDatabaseRow.BlkWriteTime":                     {"Time spent writing data file blocks by backends in this database, in milliseconds", Counter},
+	"pgstats.PgStatDatabaseRow.BlksHit":                          {"Number of times disk blocks were found already in the buffer cache, so that a read was not necessary (this only includes hits in the PostgreSQL buffer cache, not the operating system's file system cache)", Counter},
+	"pgstats.PgStatDatabaseRow.BlksRead":                         {"Number of disk blocks read in this database", Counter},
+	"pgstats.PgStatDatabaseRow.Conflicts":                        {"Number of queries canceled due to conflicts with recovery in this database. (Conflicts occur only on standby servers; see pg_stat_database_conflicts for details.)", Counter},
+	"pgstats.PgStatDatabaseRow.Datid":                            {"OID of a database", Gauge},
+	"pgstats.PgStatDatabaseRow.Deadlocks":                        {"Number of deadlocks detected in this database", Counter},
+	"pgstats.PgStatDatabaseRow.NumBackends":                      {"Number of backends currently connected to this database. This is the only column in this view that returns a value reflecting current state; all other columns return the accumulated values since the last reset.", Gauge},
+	"pgstats.PgStatDatabaseRow.StatsReset":                       {"Time at which these statistics were last reset", Gauge},
+	"pgstats.PgStatDatabaseRow.TempBytes":                        {"Total amount of data written to temporary files by queries in this database. All temporary files are counted, regardless of why the temporary file was created, and regardless of the log_temp_files setting.", Counter},
+	"pgstats.PgStatDatabaseRow.TempFiles":                        {"Number of temporary files created by queries in this database. All temporary files are counted, regardless of why the temporary file was created (e.g., sorting or hashing), and regardless of the log_temp_files setting.", Counter},
+	"pgstats.PgStatDatabaseRow.TupDeleted":                       {"Number of rows deleted by queries in this database", Counter},
+	"pgstats.PgStatDatabaseRow.TupFetched":                       {"Number of rows fetched by queries in this database", Counter},
+	"pgstats.PgStatDatabaseRow.TupInserted":                      {"Number of rows inserted by queries in this database", Counter},
+	"pgstats.PgStatDatabaseRow.TupReturned":                      {"Number of rows returned by queries in this database", Counter},
+	"pgstats.PgStatDatabaseRow.TupUpdated":                       {"Number of rows updated by queries in this database", Counter},
+	"pgstats.PgStatDatabaseRow.XactCommit":                       {"Number of transactions in this database that have been committed", Counter},
+	"pgstats.PgStatDatabaseRow.XactRollback":                     {"Number of transactions in this database that have been rolled back", Counter},
+	"pgstats.PgStatFunctionsRow.Calls":                           {"Number of times this function has been called", Counter},
+	"pgstats.PgStatFunctionsRow.Funcid":                          {"OID of a function", Gauge},
+	"pgstats.PgStatFunctionsRow.SelfTime":                        {"Total time spent in this function itself, not including other functions called by it, in milliseconds", Counter},
+	"pgstats.PgStatFunctionsRow.TotalTime":                       {"Total time spent in this function and all other functions called by it, in milliseconds", Counter},
+	"pgstats.PgStatIndexesRow.IdxScan":                           {"Number of index scans initiated on this index", Counter},
+	"pgstats.PgStatIndexesRow.IdxTupFetch":                       {"Number of live table rows fetched by simple index scans using this index", Counter},
+	"pgstats.PgStatIndexesRow.IdxTupRead":                        {"Number of index entries returned by scans on this index", Counter},
+	"pgstats.PgStatIndexesRow.Indexrelid":                        {"OID of this index", Gauge},
+	"pgstats.PgStatIndexesRow.Relid":                             {"OID of the table for this index", Gauge},
+	"pgstats.PgStatIndexesRow.Size":                              {"Size of this index, in bytes. Null unless sizes have been joined with JoinIndexSizes", Gauge},
+	"pgstats.PgStatIoIndexesRow.IdxBlksHit":                      {"Number of buffer hits in this index", Counter},
+	"pgstats.PgStatIoIndexesRow.IdxBlksRead":                     {"Number of disk blocks read from this index", Counter},
+	"pgstats.PgStatIoIndexesRow.Indexrelid":                      {"OID of this index", Gauge},
+	"pgstats.PgStatIoIndexesRow.Relid":                           {"OID of the table for this index", Gauge},
+	"pgstats.PgStatIoSequencesRow.BlksHit":                       {"Number of buffer hits in this sequence", Counter},
+	"pgstats.PgStatIoSequencesRow.BlksRead":                      {"Number of disk blocks read from this sequence", Counter},
+	"pgstats.PgStatIoSequencesRow.Relid":                         {"OID of a sequence", Gauge},
+	"pgstats.PgStatIoTablesRow.HeapBlksHit":                      {"Number of buffer hits in this table", Counter},
+	"pgstats.PgStatIoTablesRow.HeapBlksRead":                     {"Number of disk blocks read from this table", Counter},
+	"pgstats.PgStatIoTablesRow.IdxBlksHit":                       {"Number of buffer hits in all indexes on this table", Counter},
+	"pgstats.PgStatIoTablesRow.IdxBlksRead":                      {"Number of disk blocks read from all indexes on this table", Counter},
+	"pgstats.PgStatIoTablesRow.Relid":                            {"OID of a table", Gauge},
+	"pgstats.PgStatIoTablesRow.TidxBlksHit":                      {"Number of buffer hits in this table's TOAST table indexes (if any)", Counter},
+	"pgstats.PgStatIoTablesRow.TidxBlksRead":                     {"Number of disk blocks read from this table's TOAST table indexes (if any)", Counter},
+	"pgstats.PgStatIoTablesRow.ToastBlksHit":                     {"Number of buffer hits in this table's TOAST table (if any)", Counter},
+	"pgstats.PgStatIoTablesRow.ToastBlksRead":                    {"Number of disk blocks read from this table's TOAST table (if any)", Counter},
+	"pgstats.PgStatProgressVacuumRow.Datid":                      {"OID of the database to which this backend is connected.", Gauge},
+	"pgstats.PgStatProgressVacuumRow.HeapBlksScanned":            {"Number of heap blocks scanned. Because the visibility map is used to optimize scans, some blocks will be skipped without inspection; skipped blocks are included in this total, so that this number will eventually become equal to heap_blks_total when the vacuum is complete. This counter only advances when the phase is scanning heap.", Gauge},
+	"pgstats.PgStatProgressVacuumRow.HeapBlksTotal":              {"Total number of heap blocks in the table. This number is reported as of the beginning of the scan; blocks added later will not be (and need not be) visited by this VACUUM.", Gauge},
+	"pgstats.PgStatProgressVacuumRow.HeapBlksVacuumed":           {"Number of heap blocks vacuumed. Unless the table has no indexes, this counter only advances when the phase is vacuuming heap. Blocks that contain no dead tuples are skipped, so the counter may sometimes skip forward in large increments.", Gauge},
+	"pgstats.PgStatProgressVacuumRow.IndexVacuumCount":           {"Number of completed index vacuum cycles.", Gauge},
+	"pgstats.PgStatProgressVacuumRow.MaxDeadTuples":              {"Number of dead tuples that we can store before needing to perform an index vacuum cycle, based on maintenance_work_mem.", Gauge},
+	"pgstats.PgStatProgressVacuumRow.NumDeadTuples":              {"Number of dead tuples collected since the last index vacuum cycle.", Gauge},
+	"pgstats.PgStatProgressVacuumRow.Pid":                        {"Process ID of backend.", Gauge},
+	"pgstats.PgStatProgressVacuumRow.Relid":                      {"OID of the table being vacuumed.", Gauge},
+	"pgstats.PgStatReplicationRow.BackendStart":                  {"Time when this process was started, i.e., when the client connected to this WAL sender", Gauge},
+	"pgstats.PgStatReplicationRow.BackendXmin":                   {"This standby's xmin horizon reported by hot_standby_feedback - see: https://www.postgresql.org/docs/current/runtime-config-replication.html#GUC-HOT-STANDBY-FEEDBACK", Gauge},
+	"pgstats.PgStatReplicationRow.ClientPort":                    {"TCP port number that the client is using for communication with this WAL sender, or -1 if a Unix socket is used", Gauge},
+	"pgstats.PgStatReplicationRow.FlushLag":                      {"Time elapsed between flushing recent WAL locally and receiving notification that this standby server has written \t// and flushed it (but not yet applied it). This can be used to gauge the delay that synchronous_commit level on incurred while committing if this server was configured as a synchronous standby. Supported since PostgreSQL 10", Gauge},
+	"pgstats.PgStatReplicationRow.FlushLsn":                      {"Last write-ahead log location flushed to disk by this standby server", Gauge},
+	"pgstats.PgStatReplicationRow.Pid":                           {"Process ID of a WAL sender process", Gauge},
+	"pgstats.PgStatReplicationRow.ReplayLag":                     {"Time elapsed between flushing recent WAL locally and receiving notification that this standby server has written, flushed and applied it. This can be used to gauge the delay that synchronous_commit level remote_apply incurred while committing if this server was configured as a synchronous standby. Supported since PostgreSQL 10", Gauge},
+	"pgstats.PgStatReplicationRow.ReplayLsn":                     {"Last write-ahead log location replayed into the database on this standby server", Gauge},
+	"pgstats.PgStatReplicationRow.SentLsn":                       {"Last write-ahead log location sent on this connection", Gauge},
+	"pgstats.PgStatReplicationRow.SyncPriority":                  {"Priority of this standby server for being chosen as the synchronous standby in a priority-based synchronous replication. This has no effect in a quorum-based synchronous replication.", Gauge},
+	"pgstats.PgStatReplicationRow.Usesysid":                      {"OID of the user logged into this WAL sender process", Gauge},
+	"pgstats.PgStatReplicationRow.WriteLag":                      {"Time elapsed between flushing recent WAL locally and receiving notification that this standby server has written it (but not yet flushed it or applied it). This can be used to gauge the delay that synchronous_commit level remote_write incurred while committing if this server was configured as a synchronous standby. Supported since PostgreSQL 10", Gauge},
+	"pgstats.PgStatReplicationRow.WriteLsn":                      {"Last write-ahead log location written to disk by this standby server", Gauge},
+	"pgstats.PgStatSslRow.Bits":                                  {"Number of bits in the encryption algorithm used, or NULL if SSL is not used on this connection", Gauge},
+	"pgstats.PgStatSslRow.Compression":                           {"True if SSL compression is in use, false if not, or NULL if SSL is not in use on this connection", Gauge},
+	"pgstats.PgStatSslRow.Pid":                                   {"Process ID of a backend or WAL sender process", Gauge},
+	"pgstats.PgStatSslRow.Ssl":                                   {"True if SSL is used on this connection", Gauge},
+	"pgstats.PgStatStatementsDelta.Calls":                        {"Total number of calls of all statements within the window", Gauge},
+	"pgstats.PgStatStatementsDelta.Evicted":                      {"Number of entries deallocated between the snapshots because more distinct statements than pg_stat_statements.max were observed. Always zero if pg_stat_statements_info was not provided.", Gauge},
+	"pgstats.PgStatStatementsDelta.Reset":                        {"True if pg_stat_statements_reset() was called between the snapshots. In such case, values counted since the reset are reported for every statement.", Gauge},
+	"pgstats.PgStatStatementsDelta.Rows":                         {"Per-statement deltas, ordered by share of total time (descending)", Gauge},
+	"pgstats.PgStatStatementsDelta.TotalTime":                    {"Total time spent in all statements within the window, in milliseconds", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.BlkReadTime":               {"Time the statement spent reading blocks within the window, in milliseconds", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.BlkWriteTime":              {"Time the statement spent writing blocks within the window, in milliseconds", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.Calls":                     {"Number of times executed within the window", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.Dbid":                      {"OID of database in which the statement was executed", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.LocalBlksDirtied":          {"Number of local blocks dirtied by the statement within the window", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.LocalBlksHit":              {"Number of local block cache hits by the statement within the window", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.LocalBlksRead":             {"Number of local blocks read by the statement within the window", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.LocalBlksWritten":          {"Number of local blocks written by the statement within the window", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.MeanTime":                  {"Mean time spent in the statement within the window, in milliseconds", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.New":                       {"True if the statement was not present in the earlier snapshot (it is new, was evicted and re-added, or the statistics were reset), so its values are counted from zero", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.Queryid":                   {"Internal hash code, computed from the statement's parse tree", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.Rows":                      {"Number of rows retrieved or affected by the statement within the window", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.SharedBlksDirtied":         {"Number of shared blocks dirtied by the statement within the window", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.SharedBlksHit":             {"Number of shared block cache hits by the statement within the window", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.SharedBlksRead":            {"Number of shared blocks read by the statement within the window", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.SharedBlksWritten":         {"Number of shared blocks written by the statement within the window", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.StddevTime":                {"Population standard deviation of time spent in the statement within the window, in milliseconds", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.TempBlksRead":              {"Number of temp blocks read by the statement within the window", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.TempBlksWritten":           {"Number of temp blocks written by the statement within the window", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.TimeShare":                 {"Share of this statement in total time spent in all statements within the window, between 0 and 1", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.TotalTime":                 {"Time spent in the statement within the window, in milliseconds", Gauge},
+	"pgstats.PgStatStatementsDeltaRow.Userid":                    {"OID of user who executed the statement", Gauge},
+	"pgstats.PgStatStatementsInfoView.Dealloc":                   {"Total number of times pg_stat_statements entries about the least-executed statements were deallocated because more distinct statements than pg_stat_statements.max were observed", Counter},
+	"pgstats.PgStatStatementsInfoView.StatsReset":                {"Time at which all statistics in the pg_stat_statements view were last reset", Gauge},
+	"pgstats.PgStatStatementsRow.BlkReadTime":                    {"Total time the statement spent reading blocks, in milliseconds (if track_io_timing is enabled, otherwise zero) (shared_blk_read_time since PostgreSQL 17)", Counter},
+	"pgstats.PgStatStatementsRow.BlkWriteTime":                   {"Total time the statement spent writing blocks, in milliseconds (if track_io_timing is enabled, otherwise zero) (shared_blk_write_time since PostgreSQL 17)", Counter},
+	"pgstats.PgStatStatementsRow.Calls":                          {"Number of times executed", Counter},
+	"pgstats.PgStatStatementsRow.Dbid":                           {"OID of database in which the statement was executed", Gauge},
+	"pgstats.PgStatStatementsRow.LocalBlksDirtied":               {"Total number of local blocks dirtied by the statement", Counter},
+	"pgstats.PgStatStatementsRow.LocalBlksHit":                   {"Total number of local block cache hits by the statement", Counter},
+	"pgstats.PgStatStatementsRow.LocalBlksRead":                  {"Total number of local blocks read by the statement", Counter},
+	"pgstats.PgStatStatementsRow.LocalBlksWritten":               {"Total number of local blocks written by the statement", Counter},
+	"pgstats.PgStatStatementsRow.MaxTime":                        {"Maximum time spent in the statement, in milliseconds. Supported since PostgreSQL 9.5", Gauge},
+	"pgstats.PgStatStatementsRow.MeanTime":                       {"Mean time spent in the statement, in milliseconds. Supported since PostgreSQL 9.5", Gauge},
+	"pgstats.PgStatStatementsRow.MinTime":                        {"Minimum time spent in the statement, in milliseconds. Supported since PostgreSQL 9.5", Gauge},
+	"pgstats.PgStatStatementsRow.Queryid":                        {"Internal hash code, computed from the statement's parse tree", Gauge},
+	"pgstats.PgStatStatementsRow.Rows":                           {"Total number of rows retrieved or affected by the statement", Counter},
+	"pgstats.PgStatStatementsRow.SharedBlksDirtied":              {"Total number of shared blocks dirtied by the statement", Counter},
+	"pgstats.PgStatStatementsRow.SharedBlksHit":                  {"Total number of shared block cache hits by the statement", Counter},
+	"pgstats.PgStatStatementsRow.SharedBlksRead":                 {"Total number of shared blocks read by the statement", Counter},
+	"pgstats.PgStatStatementsRow.SharedBlksWritten":              {"Total number of shared blocks written by the statement", Counter},
+	"pgstats.PgStatStatementsRow.StddevTime":                     {"Population standard deviation of time spent in the statement, in milliseconds. Supported since PostgreSQL 9.5", Gauge},
+	"pgstats.PgStatStatementsRow.TempBlksRead":                   {"Total number of temp blocks read by the statement", Counter},
+	"pgstats.PgStatStatementsRow.TempBlksWritten":                {"Total number of temp blocks written by the statement", Counter},
+	"pgstats.PgStatStatementsRow.TotalTime":                      {"Total time spent in the statement, in milliseconds (total_exec_time since PostgreSQL 13). Supported since PostgreSQL 9.5", Counter},
+	"pgstats.PgStatStatementsRow.Userid":                         {"OID of user who executed the statement", Gauge},
+	"pgstats.PgStatSubscriptionRow.LastMsgReceiptTime":           {"Receipt time of last message received from origin WAL sender", Gauge},
+	"pgstats.PgStatSubscriptionRow.LastMsgSendTime":              {"Send time of last message received from origin WAL sender", Gauge},
+	"pgstats.PgStatSubscriptionRow.LatestEndLsn":                 {"Last write-ahead log location reported to origin WAL sender", Gauge},
+	"pgstats.PgStatSubscriptionRow.LatestEndTime":                {"Time of last write-ahead log location reported to origin WAL sender", Gauge},
+	"pgstats.PgStatSubscriptionRow.Pid":                          {"Process ID of the subscription worker process", Gauge},
+	"pgstats.PgStatSubscriptionRow.ReceivedLsn":                  {"Last write-ahead log location received, the initial value of this field being 0", Gauge},
+	"pgstats.PgStatSubscriptionRow.Relid":                        {"OID of the relation that the worker is synchronizing; null for the main apply worker", Gauge},
+	"pgstats.PgStatSubscriptionRow.Subid":                        {"OID of the subscription", Gauge},
+	"pgstats.PgStatTablesRow.AnalyzeCount":                       {"Number of times this table has been manually analyzed", Counter},
+	"pgstats.PgStatTablesRow.AutoanalyzeCount":                   {"Number of times this table has been analyzed by the autovacuum daemon", Counter},
+	"pgstats.PgStatTablesRow.AutovacuumCount":                    {"Number of times this table has been vacuumed by the autovacuum daemon", Counter},
+	"pgstats.PgStatTablesRow.HeapSize":                           {"Size of the main data fork of this table, in bytes. Null unless sizes have been joined with JoinTableSizes", Gauge},
+	"pgstats.PgStatTablesRow.IdxScan":                            {"Number of index scans initiated on this table", Counter},
+	"pgstats.PgStatTablesRow.IdxTupFetch":                        {"Number of live rows fetched by index scans", Counter},
+	"pgstats.PgStatTablesRow.IndexesSize":                        {"Total size of all indexes of this table, in bytes. Null unless sizes have been joined with JoinTableSizes", Gauge},
+	"pgstats.PgStatTablesRow.LastAnalyze":                        {"Last time at which this table was manually analyzed", Gauge},
+	"pgstats.PgStatTablesRow.LastAutoanalyze":                    {"Last time at which this table was analyzed by the autovacuum daemon", Gauge},
+	"pgstats.PgStatTablesRow.LastAutovacuum":                     {"Last time at which this table was vacuumed by the autovacuum daemon", Gauge},
+	"pgstats.PgStatTablesRow.LastVacuum":                         {"Last time at which this table was manually vacuumed (not counting VACUUM FULL)", Gauge},
+	"pgstats.PgStatTablesRow.NDeadTup":                           {"Estimated number of dead rows", Gauge},
+	"pgstats.PgStatTablesRow.NLiveTup":                           {"Estimated number of live rows", Gauge},
+	"pgstats.PgStatTablesRow.NModSinceAnalyze":                   {"Estimated number of rows modified since this table was last analyzed", Gauge},
+	"pgstats.PgStatTablesRow.NTupDel":                            {"Number of rows deleted", Counter},
+	"pgstats.PgStatTablesRow.NTupHotUpd":                         {"Number of rows HOT updated (i.e., with no separate index update required)", Counter},
+	"pgstats.PgStatTablesRow.NTupIns":                            {"Number of rows inserted", Counter},
+	"pgstats.PgStatTablesRow.NTupUpd":                            {"Number of rows updated (includes HOT updated rows)", Counter},
+	"pgstats.PgStatTablesRow.Relid":                              {"OID of a table", Gauge},
+	"pgstats.PgStatTablesRow.SeqScan":                            {"Number of sequential scans initiated on this table", Counter},
+	"pgstats.PgStatTablesRow.SeqTupRead":                         {"Number of live rows fetched by sequential scans", Counter},
+	"pgstats.PgStatTablesRow.ToastSize":                          {"Size of the TOAST table of this table (including its index), in bytes. Null unless sizes have been joined with JoinTableSizes", Gauge},
+	"pgstats.PgStatTablesRow.TotalSize":                          {"Total size of this table, including TOAST and indexes, in bytes. Null unless sizes have been joined with JoinTableSizes", Gauge},
+	"pgstats.PgStatTablesRow.VacuumCount":                        {"Number of times this table has been manually vacuumed (not counting VACUUM FULL)", Counter},
+	"pgstats.PgStatWalReceiverView.LastMsgReceiptTime":           {"Receipt time of last message received from origin WAL sender", Gauge},
+	"pgstats.PgStatWalReceiverView.LastMsgSendTime":              {"Send time of last message received from origin WAL sender", Gauge},
+	"pgstats.PgStatWalReceiverView.LatestEndLsn":                 {"Last write-ahead log location reported to origin WAL sender", Gauge},
+	"pgstats.PgStatWalReceiverView.LatestEndTime":                {"Time of last write-ahead log location reported to origin WAL sender", Gauge},
+	"pgstats.PgStatWalReceiverView.Pid":                          {"Process ID of the WAL receiver process", Gauge},
+	"pgstats.PgStatWalReceiverView.ReceiveStartLsn":              {"First write-ahead log location used when WAL receiver is started", Gauge},
+	"pgstats.PgStatWalReceiverView.ReceiveStartTli":              {"First timeline number used when WAL receiver is started", Gauge},
+	"pgstats.PgStatWalReceiverView.ReceivedLsn":                  {"Last write-ahead log location already received and flushed to disk, the initial value of this field being the first log location used when WAL receiver is started", Gauge},
+	"pgstats.PgStatWalReceiverView.ReceivedTli":                  {"Timeline number of last write-ahead log location received and flushed to disk, the initial value of this field being the timeline number of the first log location used when WAL receiver is started", Gauge},
+	"pgstats.PgStatWalReceiverView.SenderPort":                   {"Port number of the PostgreSQL instance this WAL receiver is connected to. Supported since PostgreSQL 11", Gauge},
+	"pgstats.PgStatXactTablesRow.IdxScan":                        {"Number of index scans initiated on this table", Counter},
+	"pgstats.PgStatXactTablesRow.IdxTupFetch":                    {"Number of live rows fetched by index scans", Counter},
+	"pgstats.PgStatXactTablesRow.NTupDel":                        {"Number of rows deleted", Counter},
+	"pgstats.PgStatXactTablesRow.NTupHotUpd":                     {"Number of rows HOT updated (i.e., with no separate index update required)", Counter},
+	"pgstats.PgStatXactTablesRow.NTupIns":                        {"Number of rows inserted", Counter},
+	"pgstats.PgStatXactTablesRow.NTupUpd":                        {"Number of rows updated (includes HOT updated rows)", Counter},
+	"pgstats.PgStatXactTablesRow.Relid":                          {"OID of a table", Gauge},
+	"pgstats.PgStatXactTablesRow.SeqScan":                        {"Number of sequential scans initiated on this table", Counter},
+	"pgstats.PgStatXactTablesRow.SeqTupRead":                     {"Number of live rows fetched by sequential scans", Counter},
+	"pgstats.ReplicationLagRow.FlushLag":                         {"Time elapsed between flushing recent WAL locally and receiving notification that this standby has flushed it, in seconds. Supported since PostgreSQL 10", Gauge},
+	"pgstats.ReplicationLagRow.FlushLagBytes":                    {"Amount of WAL not yet flushed to disk by this standby, in bytes", Gauge},
+	"pgstats.ReplicationLagRow.Pid":                              {"Process ID of the WAL sender process", Gauge},
+	"pgstats.ReplicationLagRow.ReplayLag":                        {"Time elapsed between flushing recent WAL locally and receiving notification that this standby has applied it, in seconds. Supported since PostgreSQL 10", Gauge},
+	"pgstats.ReplicationLagRow.ReplayLagBytes":                   {"Amount of WAL not yet replayed by this standby, in bytes", Gauge},
+	"pgstats.ReplicationLagRow.SentLagBytes":                     {"Amount of WAL not yet sent to this standby, in bytes", Gauge},
+	"pgstats.ReplicationLagRow.SlotRetainedBytes":                {"Amount of WAL retained by the replication slot, in bytes. Supported since PostgreSQL 9.5", Gauge},
+	"pgstats.ReplicationLagRow.SyncPriority":                     {"Priority of this standby server for being chosen as the synchronous standby", Gauge},
+	"pgstats.ReplicationLagRow.WriteLag":                         {"Time elapsed between flushing recent WAL locally and receiving notification that this standby has written it, in seconds. Supported since PostgreSQL 10", Gauge},
+	"pgstats.ReplicationLagRow.WriteLagBytes":                    {"Amount of WAL not yet written to disk by this standby, in bytes", Gauge},
+	"pgstats.SequenceUsageRow.Cycle":                             {"Whether the sequence cycles", Gauge},
+	"pgstats.SequenceUsageRow.IncrementBy":                       {"Increment value of the sequence", Gauge},
+	"pgstats.SequenceUsageRow.LastValue":                         {"The last sequence value written to disk. Null if the sequence has not been read from yet, or if the current user does not have USAGE or SELECT privilege on the sequence.", Gauge},
+	"pgstats.SequenceUsageRow.Limit":                             {"The last value the sequence can generate before it fails (or cycles), taking the range of the owning column type into account: the maximum value for ascending sequences, the minimum value for descending ones", Gauge},
+	"pgstats.SequenceUsageRow.MaxValue":                          {"Maximum value of the sequence", Gauge},
+	"pgstats.SequenceUsageRow.MinValue":                          {"Minimum value of the sequence", Gauge},
+	"pgstats.SequenceUsageRow.PercentUsed":                       {"Percentage of the range of the sequence already used: the distance of LastValue from MinValue (MaxValue for descending sequences), as a fraction of the distance of Limit from it. StartValue is not taken into account, as a cycling sequence restarts from MinValue (or MaxValue)", Gauge},
+	"pgstats.SequenceUsageRow.StartValue":                        {"Start value of the sequence", Gauge},
+	"pgstats.SequenceUsageRow.TypeMismatch":                      {"True if the sequence can generate values which do not fit into the column owning it, e.g. bigint sequence for an integer column", Gauge},
+	"pgstats.SnapshotView.BlockSize":                             {"Size of a disk block of the server (block_size), in bytes, e.g. to convert block counts of the views to bytes", Gauge},
+	"pgstats.SnapshotView.Errors":                                {"Errors of views which could not be fetched, by name", Gauge},
+	"pgstats.SnapshotView.StatsTime":                             {"Time at which the cumulative statistics have been cached for the transaction, as returned by pg_stat_get_snapshot_timestamp(). Null if none of the views read cumulative statistics.", Gauge},
+	"pgstats.SnapshotView.Time":                                  {"Start time of the transaction the views have been fetched in, as returned by now() on the server", Gauge},
+	"pgstats.SnapshotView.Views":                                 {"Views by name, as returned by Fetch", Gauge},
+	"pgstats.StandbyLagView.InRecovery":                          {"True if the server is in recovery, i.e. it is a standby", Gauge},
+	"pgstats.StandbyLagView.LastMsgReceiptTime":                  {"Time of receipt of the last message received from the sending server. Supported since PostgreSQL 9.6", Gauge},
+	"pgstats.StandbyLagView.LastXactReplayTimestamp":             {"Time stamp of the last transaction replayed during recovery", Gauge},
+	"pgstats.StandbyLagView.ReplayDelay":                         {"Time elapsed since the last replayed transaction was committed on the primary, in seconds. Note that it grows also when there is no write activity on the primary.", Gauge},
+	"pgstats.StandbyLagView.ReplayLagBytes":                      {"Amount of WAL received but not yet replayed, in bytes", Gauge},
+	"pgstats.StandbyLagView.SenderPort":                          {"Port number of the server this standby is connected to. Supported since PostgreSQL 11", Gauge},
+	"pgstats.SubscriptionLagRow.ConfirmedFlushLagBytes":          {"Amount of WAL generated on the publisher but not yet confirmed by the subscriber, in bytes. This is also the amount of WAL retained by the slot for decoding.", Gauge},
+	"pgstats.SubscriptionLagRow.Enabled":                         {"True if the subscription is enabled", Gauge},
+	"pgstats.SubscriptionLagRow.LastMsgReceiptTime":              {"Receipt time of the last message received from the publisher", Gauge},
+	"pgstats.SubscriptionLagRow.Pid":                             {"Process ID of the apply worker, if running", Gauge},
+	"pgstats.SubscriptionLagRow.ReceiveLagBytes":                 {"Amount of WAL generated on the publisher but not yet received by the subscriber, in bytes", Gauge},
+	"pgstats.SubscriptionLagRow.SlotActive":                      {"True if the replication slot is currently in use on the publisher", Gauge},
+	"pgstats.SubscriptionLagRow.SlotFound":                       {"True if the replication slot has been found on the publisher", Gauge},
+	"pgstats.SubscriptionLagRow.Subid":                           {"OID of the subscription", Gauge},
+	"pgstats.SubscriptionRelRow.Relid":                           {"OID of the table", Gauge},
+	"pgstats.SubscriptionRelRow.Subid":                           {"OID of the subscription", Gauge},
+	"pgstats.SubscriptionStatsRow.ApplyErrorCount":               {"Number of times an error occurred while applying changes", Gauge},
+	"pgstats.SubscriptionStatsRow.StatsReset":                    {"Time at which these statistics were last reset", Gauge},
+	"pgstats.SubscriptionStatsRow.Subid":                         {"OID of the subscription", Gauge},
+	"pgstats.SubscriptionStatsRow.SyncErrorCount":                {"Number of times an error occurred during the initial table synchronization", Gauge},
+	"pgstats.TableBloatRow.BloatRatio":                           {"BloatSize as a fraction of Size", Gauge},
+	"pgstats.TableBloatRow.BloatSize":                            {"Estimated space wasted by dead rows and free space exceeding the fillfactor reserve, in bytes", Gauge},
+	"pgstats.TableBloatRow.Precise":                              {"True if the value was measured with pgstattuple rather than estimated from statistics", Gauge},
+	"pgstats.TableBloatRow.Reliable":                             {"False if the estimate is known to be inaccurate (the table has columns of type name or columns without statistics)", Gauge},
+	"pgstats.TableBloatRow.Relid":                                {"OID of a table", Gauge},
+	"pgstats.TableBloatRow.Size":                                 {"Size of this table (including its TOAST table), in bytes", Gauge},
+	"pgstats.TableXidAgeRow.FreezeMaxAgeRatio":                   {"XidAge as a fraction of autovacuum_freeze_max_age. Above 1, anti-wraparound autovacuum is forced on this table.", Gauge},
+	"pgstats.TableXidAgeRow.MxidAge":                             {"Age of the oldest unfrozen multixact ID in this table (including its TOAST table) - mxid_age(relminmxid). Supported since PostgreSQL 9.5", Gauge},
+	"pgstats.TableXidAgeRow.Relid":                               {"OID of a table", Gauge},
+	"pgstats.TableXidAgeRow.WraparoundRatio":                     {"XidAge as a fraction of transaction IDs available before wraparound", Gauge},
+	"pgstats.TableXidAgeRow.XidAge":                              {"Age of the oldest unfrozen transaction ID in this table (including its TOAST table) - age(relfrozenxid)", Gauge},
+	"pgstats.TopologyEdge.ReplayLag":                             {"Time elapsed between flushing recent WAL on the sending server and receiving notification that the receiving server has applied it, in seconds. Supported since PostgreSQL 10", Gauge},
+	"pgstats.TopologyEdge.ReplayLagBytes":                        {"Amount of WAL not yet replayed by the receiving server, in bytes", Gauge},
+	"pgstats.TopologyNode.Port":                                  {"Port used to connect to the server", Gauge},
+	"pgstats.TopologyNode.Reachable":                             {"True if the server has been connected to", Gauge},
+	"pgstats.TopologyView.Edges":                                 {"Replication connections, directed from the sending server to the receiving one", Gauge},
+	"pgstats.TopologyView.Nodes":                                 {"Servers found in the topology, in the order of discovery", Gauge},
+	"pgstats.XminHorizonRow.Since":                               {"Time since when the holder exists (start of the transaction, time of preparation, or start of the WAL sender process), if known", Gauge},
+	"pgstats.XminHorizonRow.XminAge":                             {"Age of the oldest transaction ID held back", Gauge},
+}
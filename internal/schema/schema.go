@@ -0,0 +1,383 @@
+// Package schema flattens pgstats views and snapshots into metric families,
+// so that every exposition format and metrics bridge names and describes them the same way.
+package schema
+
+//go:generate go run ../cmd/schemagen -out docs.go ../.. ../../ash ../../health
+
+import (
+	"database/sql/driver"
+	"github.com/vynaloze/pgstats/nullable"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind represents the kind of a metric
+type Kind int
+
+const (
+	// Gauge is a value which can go up and down
+	Gauge Kind = iota
+	// Counter is a cumulative value which only goes up, unless reset
+	Counter
+)
+
+// String returns the name of the kind
+func (k Kind) String() string {
+	if k == Counter {
+		return "counter"
+	}
+	return "gauge"
+}
+
+// Units of values, as UCUM codes (as used by OpenTelemetry)
+const (
+	// Seconds is the unit of durations, including times reported by PostgreSQL in milliseconds
+	Seconds = "s"
+	// Bytes is the unit of sizes and amounts of data
+	Bytes = "By"
+	// BytesPerSecond is the unit of rates of growth
+	BytesPerSecond = "By/s"
+)
+
+// Label represents a single dimension of a sample
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample represents a single value of a metric
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+// Family represents all samples of a single metric
+type Family struct {
+	// Name of the metric: json tags of the fields on the path to the value, joined with underscores
+	Name string
+	// Help text taken from the doc comment of the field
+	Help string
+	// Counter for cumulative statistics, gauge otherwise
+	Kind Kind
+	// Unit of the values: Seconds, Bytes, BytesPerSecond, or empty if unknown or dimensionless
+	Unit string
+	// Samples in the order of rows of the view
+	Samples []Sample
+}
+
+type doc struct {
+	help string
+	kind Kind
+}
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	durationType       = reflect.TypeOf(time.Duration(0))
+	nullableStringType = reflect.TypeOf(nullable.String{})
+	valuerType         = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// Flatten converts a view (a slice of rows, a single row or a pointer to any of them),
+// or a snapshot built from views, into metric families.
+//
+// Fields are mapped by their json tags:
+// strings and identifiers (pid and fields ending with "id") become labels of all values in the same row
+// and in nested rows; numbers, booleans (0 or 1), times (seconds since epoch) and durations (seconds)
+// become values; nested structs and slices of structs are flattened with the tag as a name prefix.
+// Null values and zero times are skipped.
+// Values documented in milliseconds are converted to seconds, so that all durations share the same unit.
+func Flatten(v interface{}) []Family {
+	f := &flattener{index: make(map[string]int)}
+	f.walk(reflect.ValueOf(v), "", nil)
+	return f.families
+}
+
+// Describe returns metric families of all values of the view type, without samples,
+// so that metrics can be declared before the view is fetched.
+// Names, help texts and kinds are the same as returned by Flatten.
+func Describe(t reflect.Type) []Family {
+	f := &flattener{index: make(map[string]int)}
+	f.describe(t, "")
+	return f.families
+}
+
+type flattener struct {
+	families []Family
+	index    map[string]int
+	// scales of values of the families, by index
+	scales []float64
+}
+
+type structField struct {
+	field reflect.StructField
+	value reflect.Value
+	tag   string
+	owner reflect.Type
+	index []int
+}
+
+func (f *flattener) walk(v reflect.Value, prefix string, labels []Label) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			f.walk(v.Index(i), prefix, labels)
+		}
+	case reflect.Struct:
+		fields := exportedFields(v)
+		rowLabels := append([]Label(nil), labels...)
+		for _, sf := range fields {
+			if value, ok := label(sf); ok {
+				rowLabels = setLabel(rowLabels, Sanitize(sf.tag), value)
+			}
+		}
+		for _, sf := range fields {
+			if _, ok := label(sf); ok {
+				continue
+			}
+			name := join(prefix, sf.tag)
+			if value, valid, ok := scalar(sf.value); ok {
+				if valid {
+					f.add(name, docKey(sf), sf.field.Type, value, rowLabels)
+				}
+			} else if isNested(sf.value.Type()) {
+				f.walk(sf.value, name, rowLabels)
+			}
+		}
+	}
+}
+
+func (f *flattener) describe(t reflect.Type, prefix string) {
+	t = elemType(t)
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for _, sf := range typeFields(t, nil) {
+		if isLabel(sf.field.Type, sf.tag) {
+			continue
+		}
+		name := join(prefix, sf.tag)
+		if isScalar(sf.field.Type) {
+			f.family(name, docKey(sf), sf.field.Type)
+		} else if isNested(sf.field.Type) {
+			f.describe(sf.field.Type, name)
+		}
+	}
+}
+
+func (f *flattener) add(name string, key string, t reflect.Type, value float64, labels []Label) {
+	i := f.family(name, key, t)
+	f.families[i].Samples = append(f.families[i].Samples, Sample{Labels: labels, Value: value * f.scales[i]})
+}
+
+// family returns the index of the family of given name, adding it if needed
+func (f *flattener) family(name string, key string, t reflect.Type) int {
+	i, ok := f.index[name]
+	if !ok {
+		d := docs[key]
+		unit, scale, help := unitOf(t, name, d.help)
+		i = len(f.families)
+		f.index[name] = i
+		f.families = append(f.families, Family{Name: name, Help: help, Kind: d.kind, Unit: unit})
+		f.scales = append(f.scales, scale)
+	}
+	return i
+}
+
+// unitOf derives the unit of values from their type, name and help text.
+// Milliseconds are converted to seconds: it returns the scale of values and the help text to match.
+func unitOf(t reflect.Type, name string, help string) (string, float64, string) {
+	switch {
+	case t == durationType || strings.Contains(help, "in seconds"):
+		return Seconds, 1, help
+	case strings.Contains(help, "in milliseconds"):
+		return Seconds, 0.001, strings.Replace(help, "in milliseconds", "in seconds", 1)
+	case strings.Contains(help, "in bytes per second"):
+		return BytesPerSecond, 1, help
+	case strings.Contains(help, "in bytes") || strings.HasSuffix(name, "_bytes"):
+		return Bytes, 1, help
+	}
+	return "", 1, help
+}
+
+// exportedFields returns tagged exported fields of the struct, with fields of embedded structs inlined
+func exportedFields(v reflect.Value) []structField {
+	fields := typeFields(v.Type(), nil)
+	for i := range fields {
+		fields[i].value = v.FieldByIndex(fields[i].index)
+	}
+	return fields
+}
+
+func typeFields(t reflect.Type, index []int) []structField {
+	fields := make([]structField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct && !field.Type.Implements(valuerType) {
+			fields = append(fields, typeFields(field.Type, fieldIndex)...)
+			continue
+		}
+		if field.PkgPath != "" || tag == "" || tag == "-" {
+			continue
+		}
+		fields = append(fields, structField{field: field, tag: tag, owner: t, index: fieldIndex})
+	}
+	return fields
+}
+
+func docKey(sf structField) string {
+	return path.Base(sf.owner.PkgPath()) + "." + sf.owner.Name() + "." + sf.field.Name
+}
+
+// label returns the value of the field if it is a label
+func label(sf structField) (string, bool) {
+	v := sf.value
+	if !isLabel(v.Type(), sf.tag) {
+		return "", false
+	}
+	switch {
+	case v.Kind() == reflect.String:
+		return v.String(), true
+	case v.Type() == nullableStringType:
+		return v.Interface().(nullable.String).String, true
+	}
+	value, valid, _ := scalar(v)
+	if !valid {
+		return "", true
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64), true
+}
+
+// freeText holds json tags of strings with free text, e.g. query texts. They would make labels of unbounded
+// length and cardinality, so they are neither labels nor values; statements are identified by queryid instead.
+var freeText = map[string]bool{"query": true, "conninfo": true, "definition": true, "message": true}
+
+// isLabel checks whether fields of given type and json tag are labels: strings (except free text) and scalar identifiers
+func isLabel(t reflect.Type, tag string) bool {
+	if freeText[tag] {
+		return false
+	}
+	return t.Kind() == reflect.String || t == nullableStringType || isIdentifier(tag) && isScalar(t)
+}
+
+func isIdentifier(tag string) bool {
+	return tag == "pid" || strings.HasSuffix(tag, "_pid") || strings.HasSuffix(tag, "id")
+}
+
+// scalar converts the value to a float, if it is a scalar. valid is false for null values.
+func scalar(v reflect.Value) (value float64, valid bool, ok bool) {
+	t := v.Type()
+	switch {
+	case t == timeType:
+		tm := v.Interface().(time.Time)
+		return float64(tm.UnixNano()) / 1e9, !tm.IsZero(), true
+	case t == durationType:
+		return time.Duration(v.Int()).Seconds(), true, true
+	case t.Implements(valuerType):
+		dv, err := v.Interface().(driver.Valuer).Value()
+		if err != nil {
+			return 0, false, false
+		}
+		switch x := dv.(type) {
+		case nil:
+			return 0, false, true
+		case int64:
+			return float64(x), true, true
+		case float64:
+			return x, true, true
+		case bool:
+			return boolValue(x), true, true
+		case time.Time:
+			return float64(x.UnixNano()) / 1e9, true, true
+		}
+		return 0, false, false
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return boolValue(v.Bool()), true, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true, true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true, true
+	}
+	return 0, false, false
+}
+
+// isScalar checks whether values of the type are converted to floats by scalar
+func isScalar(t reflect.Type) bool {
+	if t == timeType || t == durationType {
+		return true
+	}
+	if t.Implements(valuerType) {
+		return t != nullableStringType
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// isNested checks whether the type is a struct, or a slice of structs, possibly behind pointers
+func isNested(t reflect.Type) bool {
+	return elemType(t).Kind() == reflect.Struct
+}
+
+// elemType strips pointers, slices and arrays from the type
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t
+}
+
+func setLabel(labels []Label, name string, value string) []Label {
+	for i := range labels {
+		if labels[i].Name == name {
+			labels[i].Value = value
+			return labels
+		}
+	}
+	return append(labels, Label{Name: name, Value: value})
+}
+
+func join(prefix string, name string) string {
+	if prefix == "" {
+		return Sanitize(name)
+	}
+	return prefix + "_" + Sanitize(name)
+}
+
+// Sanitize replaces characters not allowed in metric and label names with underscores
+func Sanitize(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r >= '0' && r <= '9' && i > 0:
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
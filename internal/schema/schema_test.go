@@ -0,0 +1,154 @@
+package schema
+
+import (
+	"database/sql"
+	"github.com/vynaloze/pgstats"
+	"github.com/vynaloze/pgstats/nullable"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFlatten(t *testing.T) {
+	view := pgstats.PgStatDatabaseView{
+		{Datid: 1, Datname: "app", NumBackends: 3, XactCommit: nullable.Int64{NullInt64: sql.NullInt64{Int64: 10, Valid: true}}},
+		{Datid: 2, Datname: "other", NumBackends: 0},
+	}
+	families := Flatten(&view)
+
+	numbackends := find(families, "numbackends")
+	if numbackends == nil || numbackends.Kind != Gauge || len(numbackends.Samples) != 2 {
+		t.Fatalf("Expected gauge numbackends with 2 samples; actual %+v", numbackends)
+	}
+	expected := []Label{{Name: "datid", Value: "1"}, {Name: "datname", Value: "app"}}
+	if !reflect.DeepEqual(numbackends.Samples[0].Labels, expected) || numbackends.Samples[0].Value != 3 {
+		t.Errorf("Expected sample with labels %v and value 3; actual %+v", expected, numbackends.Samples[0])
+	}
+
+	commits := find(families, "xact_commit")
+	if commits == nil || commits.Kind != Counter || len(commits.Samples) != 1 {
+		t.Fatalf("Expected counter xact_commit with a single sample (nulls skipped); actual %+v", commits)
+	}
+	if commits.Help != "Number of transactions in this database that have been committed" {
+		t.Errorf("Expected help from the doc comment; actual %s", commits.Help)
+	}
+	if find(families, "datid") != nil || find(families, "datname") != nil {
+		t.Errorf("Expected identifiers and strings to be labels only")
+	}
+}
+
+func TestFlattenNested(t *testing.T) {
+	type inner struct {
+		Name  string        `json:"name"`
+		Age   time.Duration `json:"age"`
+		Since time.Time     `json:"since"`
+	}
+	type outer struct {
+		Host   string  `json:"host"`
+		OK     bool    `json:"ok"`
+		Inner  []inner `json:"inner"`
+		hidden int
+	}
+	families := Flatten(outer{
+		Host: "db1",
+		OK:   true,
+		Inner: []inner{
+			{Name: "a", Age: 1500 * time.Millisecond, Since: time.Unix(100, 0)},
+			{Name: "b"},
+		},
+	})
+
+	if len(families) != 3 {
+		t.Fatalf("Expected 3 families; actual %+v", families)
+	}
+	if f := find(families, "ok"); f == nil || f.Samples[0].Value != 1 {
+		t.Errorf("Expected ok=1; actual %+v", f)
+	}
+	age := find(families, "inner_age")
+	if age == nil || len(age.Samples) != 2 || age.Samples[0].Value != 1.5 {
+		t.Fatalf("Expected inner_age in seconds for both rows; actual %+v", age)
+	}
+	expected := []Label{{Name: "host", Value: "db1"}, {Name: "name", Value: "a"}}
+	if !reflect.DeepEqual(age.Samples[0].Labels, expected) {
+		t.Errorf("Expected labels %v; actual %v", expected, age.Samples[0].Labels)
+	}
+	if since := find(families, "inner_since"); since == nil || len(since.Samples) != 1 || since.Samples[0].Value != 100 {
+		t.Errorf("Expected a single inner_since sample (zero time skipped); actual %+v", since)
+	}
+}
+
+func TestFlattenFreeText(t *testing.T) {
+	families := Flatten(pgstats.PgStatStatementsView{{Queryid: 42, Query: "select *\nfrom orders", Calls: 3}})
+	calls := find(families, "calls")
+	if calls == nil || len(calls.Samples) != 1 {
+		t.Fatalf("Expected calls with a single sample; actual %+v", calls)
+	}
+	for _, l := range calls.Samples[0].Labels {
+		if l.Name == "query" {
+			t.Errorf("Expected the query text not to be a label; actual %v", calls.Samples[0].Labels)
+		}
+	}
+	if !reflect.DeepEqual(calls.Samples[0].Labels[2], Label{Name: "queryid", Value: "42"}) {
+		t.Errorf("Expected statements identified by queryid; actual %v", calls.Samples[0].Labels)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	families := Describe(reflect.TypeOf(pgstats.PgStatDatabaseView{}))
+	commits := find(families, "xact_commit")
+	if commits == nil || commits.Kind != Counter || len(commits.Samples) != 0 {
+		t.Errorf("Expected counter xact_commit without samples; actual %+v", commits)
+	}
+	if find(families, "datname") != nil {
+		t.Errorf("Expected labels not to be described")
+	}
+	if f := find(Describe(reflect.TypeOf(&pgstats.ConnectionsReportView{})), "by_database_count"); f == nil {
+		t.Errorf("Expected nested families to be described")
+	}
+}
+
+func TestUnits(t *testing.T) {
+	statements := pgstats.PgStatStatementsView{{Userid: 10, Dbid: 1, Queryid: 42, TotalTime: 1500, MeanTime: 250}}
+	families := Flatten(statements)
+	total := find(families, "total_time")
+	if total == nil || total.Unit != Seconds || total.Samples[0].Value != 1.5 || strings.Contains(total.Help, "milliseconds") {
+		t.Errorf("Expected total_time converted to seconds; actual %+v", total)
+	}
+	if mean := find(families, "mean_time"); mean == nil || mean.Unit != Seconds || mean.Samples[0].Value != 0.25 {
+		t.Errorf("Expected mean_time converted to seconds; actual %+v", mean)
+	}
+	if calls := find(families, "calls"); calls == nil || calls.Unit != "" {
+		t.Errorf("Expected calls without unit; actual %+v", calls)
+	}
+
+	lag := Describe(reflect.TypeOf(pgstats.ReplicationLagView{}))
+	if f := find(lag, "replay_lag_bytes"); f == nil || f.Unit != Bytes {
+		t.Errorf("Expected replay_lag_bytes in bytes; actual %+v", f)
+	}
+	if f := find(Describe(reflect.TypeOf(pgstats.PgStatDatabaseView{})), "temp_bytes"); f == nil || f.Unit != Bytes {
+		t.Errorf("Expected temp_bytes in bytes; actual %+v", f)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	for in, expected := range map[string]string{
+		"xact_commit": "xact_commit",
+		"pg-stat.io":  "pg_stat_io",
+		"9lives":      "_lives",
+		"a9":          "a9",
+	} {
+		if actual := Sanitize(in); actual != expected {
+			t.Errorf("Expected %s; actual %s", expected, actual)
+		}
+	}
+}
+
+func find(families []Family, name string) *Family {
+	for i := range families {
+		if families[i].Name == name {
+			return &families[i]
+		}
+	}
+	return nil
+}
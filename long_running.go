@@ -0,0 +1,354 @@
+package pgstats
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/vynaloze/pgstats/nullable"
+	"io"
+	"sort"
+	"time"
+)
+
+// Kinds of thresholds a backend can exceed
+const (
+	// LongRunningQuery marks backends running the current query for too long
+	LongRunningQuery = "query"
+	// LongRunningTransaction marks backends running the current transaction for too long
+	LongRunningTransaction = "transaction"
+	// LongRunningIdleInTransaction marks backends idle inside an open transaction for too long
+	LongRunningIdleInTransaction = "idle_in_transaction"
+)
+
+// Actions which can be taken on long running backends
+const (
+	// ActionCancel cancels the current query of the backend (pg_cancel_backend)
+	ActionCancel = "cancel"
+	// ActionTerminate terminates the backend (pg_terminate_backend)
+	ActionTerminate = "terminate"
+)
+
+// LongRunningThresholds defines durations above which backends are reported.
+// Zero value disables the given threshold.
+type LongRunningThresholds struct {
+	// Maximum duration of an active query
+	Query time.Duration
+	// Maximum duration of a transaction
+	Transaction time.Duration
+	// Maximum duration of staying idle in transaction
+	IdleInTransaction time.Duration
+}
+
+// LongRunningView represents client backends exceeding at least one of the thresholds,
+// ordered by the age of their transaction (oldest first)
+type LongRunningView []LongRunningRow
+
+// LongRunningRow represents a single client backend exceeding at least one of the thresholds
+type LongRunningRow struct {
+	// Process ID of this backend
+	Pid int64 `json:"pid"`
+	// Name of the database this backend is connected to
+	Datname nullable.String `json:"datname"`
+	// Name of the user logged into this backend
+	Usename nullable.String `json:"usename"`
+	// Name of the application that is connected to this backend
+	ApplicationName nullable.String `json:"application_name"`
+	// IP address of the client connected to this backend
+	ClientAddr nullable.String `json:"client_addr"`
+	// Current overall state of this backend
+	State nullable.String `json:"state"`
+	// Text of this backend's most recent query
+	Query nullable.String `json:"query"`
+	// Time when this process was started, identifying the backend together with its process ID
+	BackendStart nullable.Time `json:"backend_start"`
+	// Time when this process' current transaction was started, or null if no transaction is active
+	XactStart nullable.Time `json:"xact_start"`
+	// Time since the currently active query was started; zero if no query is active
+	QueryAge time.Duration `json:"query_age"`
+	// Time since the current transaction was started; zero if no transaction is open
+	XactAge time.Duration `json:"xact_age"`
+	// Time since the backend became idle in transaction; zero if it is not idle in transaction
+	IdleInTransactionAge time.Duration `json:"idle_in_transaction_age"`
+	// Thresholds exceeded by this backend: query, transaction and/or idle_in_transaction
+	Exceeded []string `json:"exceeded"`
+}
+
+// age returns the age of the backend relevant for given kind of threshold
+func (r LongRunningRow) age(kind string) time.Duration {
+	switch kind {
+	case LongRunningQuery:
+		return r.QueryAge
+	case LongRunningTransaction:
+		return r.XactAge
+	case LongRunningIdleInTransaction:
+		return r.IdleInTransactionAge
+	}
+	return 0
+}
+
+// EnforcementRule defines an action taken on backends exceeding given kind of threshold
+type EnforcementRule struct {
+	// Kind of threshold the rule applies to: query, transaction or idle_in_transaction
+	Exceeded string
+	// Action to take: cancel or terminate.
+	// Note that cancelling has no effect on backends idle in transaction.
+	Action string
+	// Minimum age of the backend for the action to be taken.
+	// If zero, the action is taken as soon as the threshold is exceeded.
+	After time.Duration
+}
+
+// EnforcementPolicy defines which long running backends are cancelled or terminated
+type EnforcementPolicy struct {
+	// Thresholds above which backends are considered long running
+	Thresholds LongRunningThresholds
+	// Rules determining the actions. If several rules match a backend, terminate wins over cancel.
+	Rules []EnforcementRule
+	// Names of users whose backends are never touched
+	ExcludeUsers []string
+	// Names of applications whose backends are never touched
+	ExcludeApplications []string
+	// If set, actions are only planned and logged, but not taken
+	DryRun bool
+	// If set, every action is written to it as a line of JSON
+	AuditLog io.Writer
+}
+
+// EnforcementAction represents an action taken (or planned, in dry-run mode) on a single backend
+type EnforcementAction struct {
+	// Time at which the action was taken
+	Time time.Time `json:"time"`
+	// Process ID of the backend
+	Pid int64 `json:"pid"`
+	// Name of the database the backend was connected to
+	Datname nullable.String `json:"datname"`
+	// Name of the user logged into the backend
+	Usename nullable.String `json:"usename"`
+	// Name of the application connected to the backend
+	ApplicationName nullable.String `json:"application_name"`
+	// Text of the backend's most recent query
+	Query nullable.String `json:"query"`
+	// Time when the backend was started
+	BackendStart nullable.Time `json:"backend_start"`
+	// Time when the backend's transaction was started, or null if no transaction was active
+	XactStart nullable.Time `json:"xact_start"`
+	// Kind of threshold exceeded by the backend which triggered the action
+	Exceeded string `json:"exceeded"`
+	// Age of the backend relevant for the exceeded threshold
+	Age time.Duration `json:"age"`
+	// Action taken: cancel or terminate
+	Action string `json:"action"`
+	// True if the action was only planned
+	DryRun bool `json:"dry_run"`
+	// True if the signal was successfully sent to the backend
+	Success bool `json:"success"`
+	// Error which occurred while taking the action, if any.
+	// "backend changed" means the process ID has been reused or the transaction has ended, so no signal was sent.
+	Error string `json:"error,omitempty"`
+}
+
+func (s *PgStats) fetchLongRunning(thresholds LongRunningThresholds) (LongRunningView, error) {
+	activity, err := s.fetchActivity()
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.db()
+	row := db.QueryRow("select now(),pg_backend_pid()")
+	var now time.Time
+	var ownPid int64
+	if err := row.Scan(&now, &ownPid); err != nil {
+		return nil, err
+	}
+	return longRunning(activity, now, ownPid, thresholds), nil
+}
+
+// longRunning computes ages of client backends (except the one with ownPid) and returns these exceeding thresholds
+func longRunning(activity []PgStatActivityRow, now time.Time, ownPid int64, thresholds LongRunningThresholds) LongRunningView {
+	data := make(LongRunningView, 0)
+	for _, a := range activity {
+		if a.Pid == ownPid || a.BackendType.Valid && a.BackendType.String != "client backend" || !a.Datname.Valid {
+			continue
+		}
+		row := LongRunningRow{
+			Pid:             a.Pid,
+			Datname:         a.Datname,
+			Usename:         a.Usename,
+			ApplicationName: a.ApplicationName,
+			ClientAddr:      a.ClientAddr,
+			State:           a.State,
+			Query:           a.Query,
+			BackendStart:    a.BackendStart,
+			XactStart:       a.XactStart,
+			Exceeded:        make([]string, 0),
+		}
+		if a.State.String == "active" && a.QueryStart.Valid {
+			row.QueryAge = now.Sub(a.QueryStart.Time)
+		}
+		if a.XactStart.Valid {
+			row.XactAge = now.Sub(a.XactStart.Time)
+		}
+		if isIdleInTransaction(a.State.String) && a.StateChange.Valid {
+			row.IdleInTransactionAge = now.Sub(a.StateChange.Time)
+		}
+
+		if thresholds.Query > 0 && row.QueryAge > thresholds.Query {
+			row.Exceeded = append(row.Exceeded, LongRunningQuery)
+		}
+		if thresholds.Transaction > 0 && row.XactAge > thresholds.Transaction {
+			row.Exceeded = append(row.Exceeded, LongRunningTransaction)
+		}
+		if thresholds.IdleInTransaction > 0 && row.IdleInTransactionAge > thresholds.IdleInTransaction {
+			row.Exceeded = append(row.Exceeded, LongRunningIdleInTransaction)
+		}
+		if len(row.Exceeded) > 0 {
+			data = append(data, row)
+		}
+	}
+
+	sort.SliceStable(data, func(i, j int) bool {
+		return data[i].XactAge > data[j].XactAge
+	})
+	return data
+}
+
+func (s *PgStats) enforce(policy EnforcementPolicy) ([]EnforcementAction, error) {
+	for _, rule := range policy.Rules {
+		if rule.Action != ActionCancel && rule.Action != ActionTerminate {
+			return nil, errors.Errorf("Unknown action: %s", rule.Action)
+		}
+	}
+	backends, err := s.fetchLongRunning(policy.Thresholds)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := plannedActions(backends, policy)
+	for i := range actions {
+		a := &actions[i]
+		a.Time = time.Now()
+		a.DryRun = policy.DryRun
+		if !policy.DryRun {
+			function := "pg_cancel_backend"
+			if a.Action == ActionTerminate {
+				function = "pg_terminate_backend"
+			}
+			var err error
+			a.Success, err = s.signalBackend(function, "pid=$1 and backend_start=$2 and xact_start is not distinct from $3",
+				a.Pid, a.BackendStart, a.XactStart)
+			if err != nil {
+				a.Error = err.Error()
+			}
+		}
+		if policy.AuditLog != nil {
+			if err := json.NewEncoder(policy.AuditLog).Encode(a); err != nil {
+				return actions, err
+			}
+		}
+	}
+	return actions, nil
+}
+
+// plannedActions matches long running backends with the rules of the policy.
+// At most one action is planned per backend - termination, if any rule requires it.
+func plannedActions(backends LongRunningView, policy EnforcementPolicy) []EnforcementAction {
+	excludedUsers := make(map[string]bool, len(policy.ExcludeUsers))
+	for _, u := range policy.ExcludeUsers {
+		excludedUsers[u] = true
+	}
+	excludedApplications := make(map[string]bool, len(policy.ExcludeApplications))
+	for _, a := range policy.ExcludeApplications {
+		excludedApplications[a] = true
+	}
+
+	actions := make([]EnforcementAction, 0)
+	for _, b := range backends {
+		if excludedUsers[b.Usename.String] || excludedApplications[b.ApplicationName.String] {
+			continue
+		}
+		var action *EnforcementAction
+		for _, rule := range policy.Rules {
+			if !contains(b.Exceeded, rule.Exceeded) || b.age(rule.Exceeded) < rule.After {
+				continue
+			}
+			if action != nil && (action.Action == ActionTerminate || rule.Action != ActionTerminate) {
+				continue
+			}
+			action = &EnforcementAction{
+				Pid:             b.Pid,
+				Datname:         b.Datname,
+				Usename:         b.Usename,
+				ApplicationName: b.ApplicationName,
+				Query:           b.Query,
+				BackendStart:    b.BackendStart,
+				XactStart:       b.XactStart,
+				Exceeded:        rule.Exceeded,
+				Age:             b.age(rule.Exceeded),
+				Action:          rule.Action,
+			}
+		}
+		if action != nil {
+			actions = append(actions, *action)
+		}
+	}
+	return actions
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// errBackendChanged is returned by signalBackend if the backend is not the one which has been found
+var errBackendChanged = errors.New("backend changed")
+
+// signalBackend calls given signalling function for the backend of pg_stat_activity matching the condition,
+// e.g. started at a given time, so that a process reusing the ID is never signalled.
+// The check and the signal are a single statement.
+func (s *PgStats) signalBackend(function string, condition string, args ...interface{}) (bool, error) {
+	db := s.db()
+	row := db.QueryRow("select "+function+"(pid) from pg_stat_activity where "+condition, args...)
+	var ok bool
+	err := row.Scan(&ok)
+	if err == sql.ErrNoRows {
+		return false, errBackendChanged
+	}
+	return ok, err
+}
+
+// signalBackendStartedAt signals the backend with given process ID only if it has been started at given time
+func (s *PgStats) signalBackendStartedAt(function string, pid int64, backendStart time.Time) (bool, error) {
+	ok, err := s.signalBackend(function, "pid=$1 and backend_start=$2", pid, backendStart)
+	if err == errBackendChanged {
+		return false, nil
+	}
+	return ok, err
+}
+
+func (s *PgStats) cancelBackend(pid int64) (bool, error) {
+	db := s.db()
+	row := db.QueryRow("select pg_cancel_backend($1)", pid)
+	var ok bool
+	err := row.Scan(&ok)
+	return ok, err
+}
+
+func (s *PgStats) terminateBackend(pid int64) (bool, error) {
+	db := s.db()
+	row := db.QueryRow("select pg_terminate_backend($1)", pid)
+	var ok bool
+	err := row.Scan(&ok)
+	return ok, err
+}
+
+func (s *PgStats) cancelBackendStartedAt(pid int64, backendStart time.Time) (bool, error) {
+	return s.signalBackendStartedAt("pg_cancel_backend", pid, backendStart)
+}
+
+func (s *PgStats) terminateBackendStartedAt(pid int64, backendStart time.Time) (bool, error) {
+	return s.signalBackendStartedAt("pg_terminate_backend", pid, backendStart)
+}
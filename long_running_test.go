@@ -0,0 +1,78 @@
+package pgstats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLongRunning(t *testing.T) {
+	now := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	longQuery := backend(1, "app", "alice", "web", "", "active", now.Add(-20*time.Minute))
+	longQuery.QueryStart = longQuery.StateChange
+	longQuery.XactStart = longQuery.StateChange
+	idleInXact := backend(2, "app", "bob", "batch", "", "idle in transaction", now.Add(-10*time.Minute))
+	idleInXact.XactStart.Valid, idleInXact.XactStart.Time = true, now.Add(-time.Hour)
+	idleInXact.BackendStart.Valid, idleInXact.BackendStart.Time = true, now.Add(-2*time.Hour)
+	short := backend(3, "app", "alice", "web", "", "active", now.Add(-time.Second))
+	short.QueryStart = short.StateChange
+	own := backend(4, "app", "monitor", "pgstats", "", "active", now.Add(-time.Hour))
+	own.QueryStart = own.StateChange
+
+	thresholds := LongRunningThresholds{Query: 5 * time.Minute, Transaction: 30 * time.Minute, IdleInTransaction: time.Minute}
+	res := longRunning([]PgStatActivityRow{longQuery, idleInXact, short, own}, now, 4, thresholds)
+
+	if len(res) != 2 {
+		t.Fatalf("Expected 2 backends; actual %+v", res)
+	}
+	if res[0].Pid != 2 || res[0].XactAge != time.Hour || res[0].IdleInTransactionAge != 10*time.Minute || res[0].QueryAge != 0 ||
+		len(res[0].Exceeded) != 2 || res[0].Exceeded[0] != LongRunningTransaction || res[0].Exceeded[1] != LongRunningIdleInTransaction {
+		t.Errorf("Unexpected backend idle in transaction: %+v", res[0])
+	}
+	if res[0].BackendStart != idleInXact.BackendStart || res[0].XactStart != idleInXact.XactStart {
+		t.Errorf("Expected backend and transaction start of the backend; actual %v, %v", res[0].BackendStart, res[0].XactStart)
+	}
+	if res[1].Pid != 1 || res[1].QueryAge != 20*time.Minute || len(res[1].Exceeded) != 1 || res[1].Exceeded[0] != LongRunningQuery {
+		t.Errorf("Unexpected backend running long query: %+v", res[1])
+	}
+}
+
+func TestPlannedActions(t *testing.T) {
+	backends := LongRunningView{
+		longRunningBackend(1, "alice", "web", 20*time.Minute, 0, LongRunningQuery),
+		longRunningBackend(2, "bob", "batch", 0, 10*time.Minute, LongRunningIdleInTransaction),
+		longRunningBackend(3, "alice", "web", 0, 2*time.Minute, LongRunningIdleInTransaction),
+		longRunningBackend(4, "postgres", "psql", time.Hour, 0, LongRunningQuery),
+		longRunningBackend(5, "carol", "pg_dump", time.Hour, 0, LongRunningQuery),
+	}
+	policy := EnforcementPolicy{
+		Rules: []EnforcementRule{
+			{Exceeded: LongRunningQuery, Action: ActionCancel},
+			{Exceeded: LongRunningIdleInTransaction, Action: ActionCancel},
+			{Exceeded: LongRunningIdleInTransaction, Action: ActionTerminate, After: 5 * time.Minute},
+		},
+		ExcludeUsers:        []string{"postgres"},
+		ExcludeApplications: []string{"pg_dump"},
+	}
+
+	actions := plannedActions(backends, policy)
+	expected := map[int64]string{1: ActionCancel, 2: ActionTerminate, 3: ActionCancel}
+	if len(actions) != len(expected) {
+		t.Fatalf("Expected %d actions; actual %+v", len(expected), actions)
+	}
+	for _, a := range actions {
+		if expected[a.Pid] != a.Action {
+			t.Errorf("Expected %s of pid %d; actual %s", expected[a.Pid], a.Pid, a.Action)
+		}
+	}
+	if actions[1].Age != 10*time.Minute || actions[1].Exceeded != LongRunningIdleInTransaction || actions[1].BackendStart != backends[1].BackendStart {
+		t.Errorf("Unexpected action: %+v", actions[1])
+	}
+}
+
+func longRunningBackend(pid int64, usename string, app string, queryAge time.Duration, idleAge time.Duration, exceeded string) LongRunningRow {
+	row := LongRunningRow{Pid: pid, QueryAge: queryAge, IdleInTransactionAge: idleAge, Exceeded: []string{exceeded}}
+	row.Usename.Valid, row.Usename.String = true, usename
+	row.ApplicationName.Valid, row.ApplicationName.String = true, app
+	row.BackendStart.Valid, row.BackendStart.Time = true, time.Date(2019, 6, 1, 0, 0, 0, int(pid), time.UTC)
+	return row
+}
@@ -0,0 +1,138 @@
+// Package otel bridges pgstats views to OpenTelemetry metrics.
+// Every numeric value of the registered views is reported by an asynchronous instrument -
+// an observable counter for cumulative statistics, an observable gauge otherwise -
+// named after the view and the json tag of the field, e.g. pgstats.pg_stat_database.xact_commit.
+// Instruments carry the unit of their values: "s" for times (counted by PostgreSQL in milliseconds,
+// they are reported in seconds), "By" for sizes and amounts of data.
+//
+// The package requires go.opentelemetry.io/otel/metric v1.44.0 or later. OpenTelemetry is published
+// as Go modules only, so it is not managed by dep along with the other dependencies of pgstats
+// and has to be fetched with the go command.
+package otel
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/vynaloze/pgstats"
+	"github.com/vynaloze/pgstats/internal/schema"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Prefix is the first segment of names of all instruments
+const Prefix = "pgstats"
+
+// DefaultViews are registered if no views are given. All of them are cheap to collect.
+var DefaultViews = []string{
+	"pg_stat_archiver",
+	"pg_stat_bgwriter",
+	"pg_stat_database",
+	"pg_stat_database_conflicts",
+	"pg_stat_user_tables",
+	"pg_stat_user_indexes",
+	"pg_statio_user_tables",
+	"connections_report",
+	"replication_lag",
+}
+
+// attributeNames maps labels of views to semantic attribute names.
+// Remaining labels are prefixed with "postgresql.".
+var attributeNames = map[string]string{
+	"datname": "db.name",
+	"usename": "db.user",
+}
+
+type fetcher func(view string) (interface{}, error)
+
+type bridge struct {
+	fetch       fetcher
+	views       []string
+	attributes  []attribute.KeyValue
+	instruments map[string]map[string]metric.Float64Observable
+}
+
+// Register creates instruments for given views (or DefaultViews, if none are given) of the database
+// connected to by stats, and registers a callback which fetches the views on every collection.
+// See pgstats.Views for the names of all views.
+//
+// All measurements carry db.system=postgresql and db.name attributes, as well as the labels of the rows they come from.
+// Rows describing other databases (e.g. of pg_stat_database) override db.name with their datname.
+// The registration should be unregistered before stats is closed.
+func Register(meter metric.Meter, stats *pgstats.PgStats, views ...string) (metric.Registration, error) {
+	return register(meter, stats.Fetch, stats.DatabaseName(), views)
+}
+
+func register(meter metric.Meter, fetch fetcher, dbname string, views []string) (metric.Registration, error) {
+	if len(views) == 0 {
+		views = DefaultViews
+	}
+	b := &bridge{
+		fetch:       fetch,
+		views:       views,
+		attributes:  []attribute.KeyValue{attribute.String("db.system", "postgresql"), attribute.String("db.name", dbname)},
+		instruments: make(map[string]map[string]metric.Float64Observable, len(views)),
+	}
+	observables := make([]metric.Observable, 0)
+	for _, view := range views {
+		t, err := pgstats.ViewType(view)
+		if err != nil {
+			return nil, err
+		}
+		b.instruments[view] = make(map[string]metric.Float64Observable)
+		for _, family := range schema.Describe(t) {
+			instrument, err := newInstrument(meter, Prefix+"."+view+"."+family.Name, family)
+			if err != nil {
+				return nil, err
+			}
+			b.instruments[view][family.Name] = instrument
+			observables = append(observables, instrument)
+		}
+	}
+	return meter.RegisterCallback(b.observe, observables...)
+}
+
+func newInstrument(meter metric.Meter, name string, family schema.Family) (metric.Float64Observable, error) {
+	description, unit := metric.WithDescription(family.Help), metric.WithUnit(family.Unit)
+	if family.Kind == schema.Counter {
+		return meter.Float64ObservableCounter(name, description, unit)
+	}
+	return meter.Float64ObservableGauge(name, description, unit)
+}
+
+// observe fetches all views and observes their values. Views which cannot be fetched are skipped,
+// so that a single failure (e.g. a view unsupported by the server version) does not hide the others;
+// the first error is returned.
+func (b *bridge) observe(_ context.Context, o metric.Observer) error {
+	var first error
+	for _, view := range b.views {
+		v, err := b.fetch(view)
+		if err != nil {
+			if first == nil {
+				first = errors.Wrapf(err, "Cannot fetch %s", view)
+			}
+			continue
+		}
+		for _, family := range schema.Flatten(v) {
+			instrument, ok := b.instruments[view][family.Name]
+			if !ok {
+				continue
+			}
+			for _, sample := range family.Samples {
+				o.ObserveFloat64(instrument, sample.Value, metric.WithAttributes(b.sampleAttributes(sample.Labels)...))
+			}
+		}
+	}
+	return first
+}
+
+func (b *bridge) sampleAttributes(labels []schema.Label) []attribute.KeyValue {
+	attributes := append(make([]attribute.KeyValue, 0, len(b.attributes)+len(labels)), b.attributes...)
+	for _, l := range labels {
+		name, ok := attributeNames[l.Name]
+		if !ok {
+			name = "postgresql." + l.Name
+		}
+		attributes = append(attributes, attribute.String(name, l.Value))
+	}
+	return attributes
+}
@@ -0,0 +1,108 @@
+package otel
+
+import (
+	"context"
+	"database/sql"
+	"github.com/pkg/errors"
+	"github.com/vynaloze/pgstats"
+	"github.com/vynaloze/pgstats/nullable"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"testing"
+)
+
+func fakeFetch(view string) (interface{}, error) {
+	switch view {
+	case "pg_stat_database":
+		return pgstats.PgStatDatabaseView{
+			{Datid: 1, Datname: "app", NumBackends: 3, XactCommit: nullable.Int64{NullInt64: sql.NullInt64{Int64: 10, Valid: true}}},
+		}, nil
+	case "pg_stat_statements":
+		return pgstats.PgStatStatementsView{{Userid: 10, Dbid: 1, Queryid: 42, Calls: 2, TotalTime: 1500}}, nil
+	case "pg_stat_bgwriter":
+		return nil, errors.New("Unsupported PostgreSQL version: 17.000000")
+	}
+	return nil, errors.Errorf("Unknown view: %s", view)
+}
+
+func TestRegister(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	reg, err := register(provider.Meter("test"), fakeFetch, "postgres", []string{"pg_stat_database", "pg_stat_bgwriter"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Unregister()
+
+	var rm metricdata.ResourceMetrics
+	err = reader.Collect(context.Background(), &rm)
+	if err == nil {
+		t.Error("Expected the error of pg_stat_bgwriter to be reported")
+	}
+	metrics := make(map[string]metricdata.Metrics)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m
+		}
+	}
+
+	commits, ok := metrics["pgstats.pg_stat_database.xact_commit"].Data.(metricdata.Sum[float64])
+	if !ok || !commits.IsMonotonic || len(commits.DataPoints) != 1 || commits.DataPoints[0].Value != 10 {
+		t.Fatalf("Expected monotonic sum xact_commit=10; actual %+v", metrics["pgstats.pg_stat_database.xact_commit"])
+	}
+	attrs := commits.DataPoints[0].Attributes
+	for key, expected := range map[attribute.Key]string{"db.system": "postgresql", "db.name": "app", "postgresql.datid": "1"} {
+		if actual, _ := attrs.Value(key); actual.AsString() != expected {
+			t.Errorf("Expected %s=%s; actual %s", key, expected, actual.AsString())
+		}
+	}
+
+	backends, ok := metrics["pgstats.pg_stat_database.numbackends"].Data.(metricdata.Gauge[float64])
+	if !ok || len(backends.DataPoints) != 1 || backends.DataPoints[0].Value != 3 {
+		t.Errorf("Expected gauge numbackends=3; actual %+v", metrics["pgstats.pg_stat_database.numbackends"])
+	}
+	if metrics["pgstats.pg_stat_database.numbackends"].Description == "" {
+		t.Error("Expected description from the doc comment")
+	}
+}
+
+func TestRegisterUnits(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	reg, err := register(provider.Meter("test"), fakeFetch, "postgres", []string{"pg_stat_statements"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Unregister()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "pgstats.pg_stat_statements.total_time" {
+				continue
+			}
+			sum, ok := m.Data.(metricdata.Sum[float64])
+			if m.Unit != "s" || !ok || len(sum.DataPoints) != 1 || sum.DataPoints[0].Value != 1.5 {
+				t.Errorf("Expected total_time of 1.5 s; actual %+v", m)
+			}
+			return
+		}
+	}
+	t.Error("Expected total_time to be reported")
+}
+
+func TestRegisterUnknownView(t *testing.T) {
+	provider := sdkmetric.NewMeterProvider()
+	_, err := register(provider.Meter("test"), fakeFetch, "postgres", []string{"pg_stat_nothing"})
+	if err == nil {
+		t.Error("Expected unknown view error")
+	}
+}
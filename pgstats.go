@@ -4,10 +4,18 @@
 // For details, see: https://github.com/vynaloze/pgstats/blob/master/README.md
 package pgstats
 
+import (
+	"database/sql"
+	"reflect"
+	"time"
+)
+
 // PgStats holds a single connection to the database
 // and provides a convenient access to all postgres monitoring statistics.
 type PgStats struct {
 	conn *connection
+	// transaction the views are fetched in, if any (see Snapshot)
+	tx *sql.Tx
 }
 
 // Connect opens a connection using provided parameters and returns a pointer to newly created PgStats struct.
@@ -311,3 +319,294 @@ func (s *PgStats) PgStatXactUserFunctions() (PgStatXactUserFunctionsView, error)
 func (s *PgStats) PgStatStatements() (PgStatStatementsView, error) {
 	return s.fetchStatements()
 }
+
+// PgStatStatementsInfo returns a single struct, containing statistics
+// of the pg_stat_statements module itself.
+//
+// Supported since PostgreSQL 14.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/pgstatstatements.html#PGSTATSTATEMENTS-PG-STAT-STATEMENTS-INFO
+func (s *PgStats) PgStatStatementsInfo() (PgStatStatementsInfoView, error) {
+	return s.fetchStatementsInfo()
+}
+
+// DatabaseXidAges returns a slice containing the age of the oldest unfrozen transaction ID and multixact ID
+// of each database in the cluster, related to autovacuum_freeze_max_age and to the wraparound limit.
+// Databases are ordered by transaction ID age, oldest first.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/routine-vacuuming.html#VACUUM-FOR-WRAPAROUND
+func (s *PgStats) DatabaseXidAges() (DatabaseXidAgeView, error) {
+	return s.fetchDatabaseXidAges()
+}
+
+// TableXidAges returns a slice containing at most limit tables of the current database
+// with the oldest unfrozen transaction IDs, related to autovacuum_freeze_max_age and to the wraparound limit.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/routine-vacuuming.html#VACUUM-FOR-WRAPAROUND
+func (s *PgStats) TableXidAges(limit int) (TableXidAgeView, error) {
+	return s.fetchTableXidAges(limit)
+}
+
+// XminHorizon returns a slice containing everything that holds back the xmin horizon -
+// long-running transactions, prepared transactions, replication slots and standbys with hot_standby_feedback -
+// ordered by the age of the transaction ID held back, oldest first.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/routine-vacuuming.html#VACUUM-FOR-WRAPAROUND
+func (s *PgStats) XminHorizon() (XminHorizonView, error) {
+	return s.fetchXminHorizon()
+}
+
+// AutovacuumQueue returns a slice containing all user tables in the current database and their TOAST tables
+// together with their autovacuum and autoanalyze thresholds (computed from global settings
+// and table storage parameters, including the insert threshold since PostgreSQL 13)
+// and vacuums currently running on them,
+// ordered by how far past their thresholds they are, most urgent first.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/routine-vacuuming.html#AUTOVACUUM
+func (s *PgStats) AutovacuumQueue() (AutovacuumQueueView, error) {
+	return s.fetchAutovacuumQueue()
+}
+
+// TableBloat returns a slice containing estimated bloat (space wasted by dead rows and excessive free space)
+// of each user table in the current database.
+// Bloat is estimated from statistics in pg_stats, so the tables should be analyzed recently.
+// If precise is set and pgstattuple extension is installed, bloat is measured with pgstattuple instead,
+// which reads whole tables and can be expensive.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/pgstattuple.html
+func (s *PgStats) TableBloat(precise bool) (TableBloatView, error) {
+	return s.fetchTableBloat(precise)
+}
+
+// IndexBloat returns a slice containing estimated bloat of each user btree index in the current database.
+// Bloat is estimated from statistics in pg_stats, so the tables should be analyzed recently.
+// If precise is set and pgstattuple extension is installed, bloat is measured with pgstatindex instead,
+// which reads whole indexes and can be expensive.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/pgstattuple.html
+func (s *PgStats) IndexBloat(precise bool) (IndexBloatView, error) {
+	return s.fetchIndexBloat(precise)
+}
+
+// DatabaseSizes returns a slice containing disk space used by each database in the cluster,
+// together with the time of measurement, so that the growth between two snapshots can be computed with Growth.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-DBOBJECT
+func (s *PgStats) DatabaseSizes() (DatabaseSizeView, error) {
+	return s.fetchDatabaseSizes()
+}
+
+// JoinTableSizes fills size fields (heap, TOAST, indexes and total size) of given rows of pg_stat_*_tables views.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-DBOBJECT
+func (s *PgStats) JoinTableSizes(tables []PgStatTablesRow) error {
+	return s.joinTableSizes(tables)
+}
+
+// JoinIndexSizes fills size field of given rows of pg_stat_*_indexes views.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-DBOBJECT
+func (s *PgStats) JoinIndexSizes(indexes []PgStatIndexesRow) error {
+	return s.joinIndexSizes(indexes)
+}
+
+// IndexAdvice returns user indexes in the current database which are candidates for removal:
+// invalid indexes, exact duplicates, indexes whose columns are a left prefix of another index,
+// and indexes never scanned since statistics were last reset. Indexes backing constraints are skipped,
+// unless invalid. Sizes of the indexes are reported to estimate the savings.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/catalog-pg-index.html
+func (s *PgStats) IndexAdvice() (IndexAdviceView, error) {
+	return s.fetchIndexAdvice()
+}
+
+// SequenceUsage returns a slice containing current value and percentage of the range used of each sequence
+// in the current database. Serial and identity columns whose type is narrower than the type of their sequence
+// are flagged, and their range is limited to the range of the column type.
+// Supported since PostgreSQL 10.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/view-pg-sequences.html
+func (s *PgStats) SequenceUsage() (SequenceUsageView, error) {
+	return s.fetchSequenceUsage()
+}
+
+// ConnectionsReport returns client connections aggregated by state, database, user, application and client address,
+// compared to max_connections, superuser_reserved_connections and per-database and per-role connection limits.
+// Connections idle (or idle in transaction) for longer than the given thresholds are listed.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/runtime-config-connection.html
+func (s *PgStats) ConnectionsReport(thresholds ConnectionThresholds) (*ConnectionsReportView, error) {
+	return s.fetchConnectionsReport(thresholds)
+}
+
+// LongRunning returns a slice containing client backends whose current query, transaction
+// or idle in transaction state lasts longer than given thresholds, together with the ages computed
+// from the server clock.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/monitoring-stats.html#PG-STAT-ACTIVITY-VIEW
+func (s *PgStats) LongRunning(thresholds LongRunningThresholds) (LongRunningView, error) {
+	return s.fetchLongRunning(thresholds)
+}
+
+// Enforce cancels or terminates long running backends according to the rules of given policy,
+// skipping excluded users and applications. In dry-run mode the actions are only returned and logged.
+// Sending signals to backends of other users requires superuser or pg_signal_backend role.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-SIGNAL
+func (s *PgStats) Enforce(policy EnforcementPolicy) ([]EnforcementAction, error) {
+	return s.enforce(policy)
+}
+
+// CancelBackend cancels the current query of the backend with given process ID.
+// It returns false if the signal could not be sent.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-SIGNAL
+func (s *PgStats) CancelBackend(pid int64) (bool, error) {
+	return s.cancelBackend(pid)
+}
+
+// TerminateBackend terminates the backend with given process ID.
+// It returns false if the signal could not be sent.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-SIGNAL
+func (s *PgStats) TerminateBackend(pid int64) (bool, error) {
+	return s.terminateBackend(pid)
+}
+
+// CancelBackendStartedAt cancels the current query of the backend with given process ID,
+// provided that the backend has been started at given time (see PgStatActivityRow.BackendStart),
+// so that a process reusing the ID is never signalled.
+// It returns false if there is no such backend or the signal could not be sent.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-SIGNAL
+func (s *PgStats) CancelBackendStartedAt(pid int64, backendStart time.Time) (bool, error) {
+	return s.cancelBackendStartedAt(pid, backendStart)
+}
+
+// TerminateBackendStartedAt terminates the backend with given process ID,
+// provided that it has been started at given time (see PgStatActivityRow.BackendStart),
+// so that a process reusing the ID is never signalled.
+// It returns false if there is no such backend or the signal could not be sent.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-SIGNAL
+func (s *PgStats) TerminateBackendStartedAt(pid int64, backendStart time.Time) (bool, error) {
+	return s.terminateBackendStartedAt(pid, backendStart)
+}
+
+// ReplicationLag returns a slice containing replication lag of each standby connected to the current server:
+// amount of WAL not yet sent, written, flushed and replayed by the standby, time lags and associated replication slot.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/monitoring-stats.html#PG-STAT-REPLICATION-VIEW
+func (s *PgStats) ReplicationLag() (ReplicationLagView, error) {
+	return s.fetchReplicationLag()
+}
+
+// StandbyLag returns replication lag of the current server as seen by itself, if it is a standby:
+// state of the WAL receiver, amount of WAL received but not yet replayed and time since the last replayed transaction.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-RECOVERY-INFO-TABLE
+func (s *PgStats) StandbyLag() (StandbyLagView, error) {
+	return s.fetchStandbyLag()
+}
+
+// Topology discovers the replication topology the current server is part of: its primary, standbys,
+// cascading standbys and logical subscribers, with replication lag on each connection.
+// Neighbouring servers are connected to with the same connection parameters except for host and port.
+// Standbys are assumed to listen on the same port as the server they replicate from, and upstream servers
+// can be discovered only since PostgreSQL 11.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/warm-standby.html#CASCADING-REPLICATION
+func (s *PgStats) Topology() (*TopologyView, error) {
+	return s.fetchTopology()
+}
+
+// SubscriptionStats returns a slice containing error statistics of each logical replication subscription.
+// Supported since PostgreSQL 15.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-SUBSCRIPTION-STATS
+func (s *PgStats) SubscriptionStats() (SubscriptionStatsView, error) {
+	return s.fetchSubscriptionStats()
+}
+
+// SubscriptionRels returns a slice containing synchronization state of each table
+// of logical replication subscriptions in the current database.
+// Supported since PostgreSQL 10.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/catalog-pg-subscription-rel.html
+func (s *PgStats) SubscriptionRels() (SubscriptionRelView, error) {
+	return s.fetchSubscriptionRels()
+}
+
+// SubscriptionLag returns a slice containing lag of each logical replication subscription in the current database
+// behind the publisher, which is computed by pairing the subscriptions with their replication slots on the publisher.
+// Subscriptions without a matching slot on the given publisher have no lag computed.
+// Supported since PostgreSQL 10.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/logical-replication-monitoring.html
+func (s *PgStats) SubscriptionLag(publisher *PgStats) (SubscriptionLagView, error) {
+	return s.fetchSubscriptionLag(publisher)
+}
+
+// Views returns names of all views which can be collected with Fetch, in alphabetical order.
+// pg_stat_* views are named as in PostgreSQL; the remaining ones are named after their methods,
+// e.g. "database_xid_ages" for DatabaseXidAges.
+func Views() []string {
+	return viewNames()
+}
+
+// Fetch returns the view of given name, as returned by its method (e.g. PgStatDatabaseView for "pg_stat_database").
+// Views which need arguments are fetched with defaults: bloat is estimated, xid ages are limited to 100 tables
+// and idle connections are not reported.
+// See Views for all supported names.
+func (s *PgStats) Fetch(view string) (interface{}, error) {
+	return s.fetchView(view)
+}
+
+// ViewType returns the type of the view of given name, as returned by Fetch,
+// so that the view can be described before it is fetched.
+func ViewType(view string) (reflect.Type, error) {
+	return viewType(view)
+}
+
+// DatabaseName returns the name of the database the connection has been opened to.
+func (s *PgStats) DatabaseName() string {
+	return s.conn.config["dbname"]
+}
+
+// Snapshot fetches given views (all views, if none given) in a single read-only transaction,
+// so that they reflect the same moment. On PostgreSQL 15 and later, stats_fetch_consistency is set to snapshot
+// for the transaction, so that all views see the same cached statistics; in earlier versions,
+// statistics are cached for the whole transaction anyway.
+// Views which cannot be fetched, e.g. pg_stat_statements without the extension, are reported in Errors
+// of the snapshot without affecting the remaining ones.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-STATS-VIEWS
+func (s *PgStats) Snapshot(views ...string) (*SnapshotView, error) {
+	return s.takeSnapshot(views)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/vynaloze/pgstats"
 	"strings"
 	"testing"
+	"time"
 )
 
 var dbname = flag.String("dbname", "", "Test database name")
@@ -245,6 +246,269 @@ func TestPgStatStatements(t *testing.T) {
 	validate(t, len(ss), err)
 }
 
+func TestPgStatStatementsInfo(t *testing.T) {
+	t.Parallel()
+	s, err := pgstats.Connect(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = s.PgStatStatementsInfo()
+	if err != nil && !strings.Contains(err.Error(), "Unsupported PostgreSQL version") {
+		t.Error(err)
+	}
+}
+
+func TestXidWraparound(t *testing.T) {
+	t.Parallel()
+	s, err := pgstats.Connect(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	d, err := s.DatabaseXidAges()
+	validate(t, len(d), err)
+	for _, row := range d {
+		if row.Datname == "template0" && row.Datallowconn {
+			t.Error("Expected connections to template0 not to be allowed")
+		}
+	}
+	tbl, err := s.TableXidAges(10)
+	validate(t, len(tbl), err)
+	_, err = s.XminHorizon()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAutovacuumQueue(t *testing.T) {
+	t.Parallel()
+	s, err := pgstats.Connect(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	q, err := s.AutovacuumQueue()
+	validate(t, len(q), err)
+}
+
+func TestBloat(t *testing.T) {
+	t.Parallel()
+	s, err := pgstats.Connect(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	for _, precise := range []bool{false, true} {
+		tbl, err := s.TableBloat(precise)
+		validate(t, len(tbl), err)
+		_, err = s.IndexBloat(precise)
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestSizes(t *testing.T) {
+	t.Parallel()
+	s, err := pgstats.Connect(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	d, err := s.DatabaseSizes()
+	validate(t, len(d), err)
+	tbl, err := s.PgStatUserTables()
+	validate(t, len(tbl), err)
+	err = s.JoinTableSizes(tbl)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(tbl) > 0 && !tbl[0].TotalSize.Valid {
+		t.Error("Table sizes have not been joined")
+	}
+	idx, err := s.PgStatUserIndexes()
+	validate(t, len(idx), err)
+	err = s.JoinIndexSizes(idx)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(idx) > 0 && !idx[0].Size.Valid {
+		t.Error("Index sizes have not been joined")
+	}
+}
+
+func TestIndexAdvice(t *testing.T) {
+	t.Parallel()
+	s, err := pgstats.Connect(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = s.IndexAdvice()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSequenceUsage(t *testing.T) {
+	t.Parallel()
+	s, err := pgstats.Connect(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = s.SequenceUsage()
+	if err != nil && !strings.Contains(err.Error(), "Unsupported PostgreSQL version: 9.") {
+		t.Error(err)
+	}
+}
+
+func TestConnectionsReport(t *testing.T) {
+	t.Parallel()
+	s, err := pgstats.Connect(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	r, err := s.ConnectionsReport(pgstats.ConnectionThresholds{Idle: time.Minute, IdleInTransaction: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	validate(t, int(r.Total), err)
+	if r.MaxConnections == 0 {
+		t.Error("Expected max_connections to be set")
+	}
+}
+
+func TestLongRunning(t *testing.T) {
+	t.Parallel()
+	s, err := pgstats.Connect(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = s.LongRunning(pgstats.LongRunningThresholds{Query: time.Hour, Transaction: time.Hour, IdleInTransaction: time.Hour})
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = s.Enforce(pgstats.EnforcementPolicy{
+		Thresholds: pgstats.LongRunningThresholds{Transaction: time.Nanosecond},
+		Rules:      []pgstats.EnforcementRule{{Exceeded: pgstats.LongRunningTransaction, Action: pgstats.ActionTerminate}},
+		DryRun:     true,
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	ok, err := s.CancelBackend(0)
+	if err != nil {
+		t.Error(err)
+	}
+	if ok {
+		t.Error("Expected cancelling nonexistent backend to fail")
+	}
+	ok, err = s.TerminateBackendStartedAt(0, time.Now())
+	if err != nil {
+		t.Error(err)
+	}
+	if ok {
+		t.Error("Expected terminating nonexistent backend to fail")
+	}
+}
+
+func TestReplicationLag(t *testing.T) {
+	t.Parallel()
+	s, err := pgstats.Connect(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = s.ReplicationLag()
+	if err != nil {
+		t.Error(err)
+	}
+	standby, err := s.StandbyLag()
+	if err != nil {
+		t.Error(err)
+	}
+	if standby.InRecovery && !standby.ReplayLsn.Valid {
+		t.Error("Expected replay location on a standby")
+	}
+}
+
+func TestTopology(t *testing.T) {
+	t.Parallel()
+	s, err := pgstats.Connect(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	topology, err := s.Topology()
+	if err != nil {
+		t.Fatal(err)
+	}
+	validate(t, len(topology.Nodes), err)
+	if !topology.Nodes[0].Reachable {
+		t.Error("Expected the starting server to be reachable")
+	}
+}
+
+func TestSubscriptions(t *testing.T) {
+	t.Parallel()
+	s, err := pgstats.Connect(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = s.SubscriptionStats()
+	if err != nil && !strings.Contains(err.Error(), "Unsupported PostgreSQL version") {
+		t.Error(err)
+	}
+	_, err = s.SubscriptionRels()
+	if err != nil && !strings.Contains(err.Error(), "Unsupported PostgreSQL version: 9.") {
+		t.Error(err)
+	}
+	_, err = s.SubscriptionLag(s)
+	if err != nil && !strings.Contains(err.Error(), "Unsupported PostgreSQL version: 9.") {
+		t.Error(err)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+	s, err := pgstats.Connect(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	view, err := s.Fetch("pg_stat_database")
+	if err != nil {
+		t.Fatal(err)
+	}
+	databases, ok := view.(pgstats.PgStatDatabaseView)
+	if !ok {
+		t.Fatalf("Expected PgStatDatabaseView; actual %T", view)
+	}
+	validate(t, len(databases), err)
+	if s.DatabaseName() != *dbname {
+		t.Errorf("Expected %s; actual %s", *dbname, s.DatabaseName())
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+	s, err := pgstats.Connect(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	snap, err := s.Snapshot("pg_stat_database", "pg_stat_user_tables", "pg_stat_statements", "pg_stat_bgwriter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	databases, ok := snap.Views["pg_stat_database"].(pgstats.PgStatDatabaseView)
+	if !ok {
+		t.Fatalf("Expected PgStatDatabaseView; actual %T", snap.Views["pg_stat_database"])
+	}
+	validate(t, len(databases), nil)
+	// pg_stat_statements may be missing, but must not affect other views
+	if len(snap.Views)+len(snap.Errors) != 4 || len(snap.Views) < 3 {
+		t.Errorf("Expected 4 views, at least 3 of them fetched; actual %d views, errors: %v", len(snap.Views), snap.Errors)
+	}
+	if snap.Time.IsZero() || time.Since(snap.Time) > time.Minute {
+		t.Errorf("Expected current server time; actual %v", snap.Time)
+	}
+	if _, err := s.Snapshot("pg_stat_nothing"); err == nil {
+		t.Error("Expected error for unknown view")
+	}
+}
+
 func validate(t *testing.T, len int, err error) {
 	if err != nil {
 		t.Error(err)
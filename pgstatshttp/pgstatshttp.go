@@ -0,0 +1,331 @@
+// Package pgstatshttp serves pgstats views over HTTP: every view as JSON (with filtering, sorting and limits),
+// metrics in Prometheus text or OpenMetrics format, and results of health checks.
+//
+// Endpoints:
+//
+//	/                  names of all endpoints
+//	/<view>            a view as JSON, by its name (see pgstats.Views) or a short alias (e.g. /activity, /tables)
+//	/metrics           metrics of selected views (see MetricsViews)
+//	/health            findings of the default health rules
+//
+// Views returning many rows accept query parameters: <field>=<value> keeps rows with the field
+// (json tag) equal to the value (null for nulls; repeat the parameter to accept any of many values),
+// sort=<field>[,-<field>...] orders rows, descending if the field is prefixed with "-",
+// and limit=<n> returns at most n rows.
+package pgstatshttp
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/vynaloze/pgstats"
+	"github.com/vynaloze/pgstats/expfmt"
+	"github.com/vynaloze/pgstats/health"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Aliases maps short endpoint names to views
+var Aliases = map[string]string{
+	"activity":    "pg_stat_activity",
+	"archiver":    "pg_stat_archiver",
+	"bgwriter":    "pg_stat_bgwriter",
+	"connections": "connections_report",
+	"databases":   "pg_stat_database",
+	"functions":   "pg_stat_user_functions",
+	"indexes":     "pg_stat_user_indexes",
+	"replication": "pg_stat_replication",
+	"statements":  "pg_stat_statements",
+	"tables":      "pg_stat_user_tables",
+}
+
+// DefaultMetricsViews are exposed on /metrics, unless changed with MetricsViews
+var DefaultMetricsViews = []string{
+	"pg_stat_archiver",
+	"pg_stat_bgwriter",
+	"pg_stat_database",
+	"pg_stat_database_conflicts",
+	"pg_stat_user_tables",
+	"pg_stat_user_indexes",
+	"pg_statio_user_tables",
+	"connections_report",
+	"replication_lag",
+}
+
+// DefaultCacheTTL is the time for which fetched views are reused, unless changed with CacheTTL
+const DefaultCacheTTL = 5 * time.Second
+
+// Option represents an optional setting of the handler
+type Option func(*server)
+
+// BasicAuth requires HTTP basic authentication with given credentials
+func BasicAuth(user string, password string) Option {
+	return func(s *server) {
+		s.user, s.password = user, password
+	}
+}
+
+// BearerToken requires an "Authorization: Bearer <token>" header with given token.
+// If BasicAuth is also set, either of them is accepted.
+func BearerToken(token string) Option {
+	return func(s *server) {
+		s.token = token
+	}
+}
+
+// CacheTTL sets the time for which fetched views are reused by all requests,
+// protecting the database from frequently refreshed dashboards. Zero disables caching.
+func CacheTTL(ttl time.Duration) Option {
+	return func(s *server) {
+		s.ttl = ttl
+	}
+}
+
+// MetricsViews sets views exposed on /metrics
+func MetricsViews(views ...string) Option {
+	return func(s *server) {
+		s.metricsViews = views
+	}
+}
+
+type server struct {
+	fetch        func(view string) (interface{}, error)
+	collect      func() (*health.Snapshot, error)
+	views        map[string]bool
+	user         string
+	password     string
+	token        string
+	ttl          time.Duration
+	metricsViews []string
+
+	mu    sync.Mutex
+	cache map[string]*cacheEntry
+}
+
+// cacheEntry holds the most recent result of fetching a view.
+// Its mutex makes concurrent requests wait for a single fetch instead of querying the database each.
+type cacheEntry struct {
+	mu      sync.Mutex
+	fetched time.Time
+	value   interface{}
+	err     error
+}
+
+// viewStatus reports on /metrics whether a view has been fetched successfully
+type viewStatus struct {
+	View string `json:"view"`
+	Up   bool   `json:"up"`
+}
+
+// healthReport represents the response of /health
+type healthReport struct {
+	Status   string           `json:"status"`
+	Error    string           `json:"error,omitempty"`
+	Time     time.Time        `json:"time"`
+	Findings []health.Finding `json:"findings"`
+}
+
+// Handler returns a handler serving views of the database connected to by stats.
+func Handler(stats *pgstats.PgStats, options ...Option) http.Handler {
+	return newServer(stats.Fetch, func() (*health.Snapshot, error) { return health.Collect(stats) }, options...)
+}
+
+func newServer(fetch func(view string) (interface{}, error), collect func() (*health.Snapshot, error), options ...Option) *server {
+	s := &server{
+		fetch:        fetch,
+		collect:      collect,
+		views:        make(map[string]bool),
+		ttl:          DefaultCacheTTL,
+		metricsViews: DefaultMetricsViews,
+		cache:        make(map[string]*cacheEntry),
+	}
+	for _, view := range pgstats.Views() {
+		s.views[view] = true
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		if s.user != "" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="pgstats"`)
+		}
+		writeError(w, http.StatusUnauthorized, errors.New("Unauthorized"))
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, errors.Errorf("Method not allowed: %s", r.Method))
+		return
+	}
+
+	name := strings.Trim(r.URL.Path, "/")
+	switch name {
+	case "":
+		s.serveIndex(w)
+	case "metrics":
+		s.serveMetrics(w, r)
+	case "health":
+		s.serveHealth(w)
+	default:
+		if view, ok := Aliases[name]; ok {
+			name = view
+		}
+		if !s.views[name] {
+			writeError(w, http.StatusNotFound, errors.Errorf("Unknown view: %s", name))
+			return
+		}
+		s.serveView(w, r, name)
+	}
+}
+
+func (s *server) authorized(r *http.Request) bool {
+	if s.user == "" && s.token == "" {
+		return true
+	}
+	if s.user != "" {
+		if user, password, ok := r.BasicAuth(); ok && equal(user, s.user) && equal(password, s.password) {
+			return true
+		}
+	}
+	if s.token != "" {
+		header := r.Header.Get("Authorization")
+		if strings.HasPrefix(header, "Bearer ") && equal(strings.TrimPrefix(header, "Bearer "), s.token) {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a string, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func (s *server) serveIndex(w http.ResponseWriter) {
+	endpoints := []string{"/metrics", "/health"}
+	for alias := range Aliases {
+		endpoints = append(endpoints, "/"+alias)
+	}
+	for view := range s.views {
+		endpoints = append(endpoints, "/"+view)
+	}
+	sort.Strings(endpoints[2:])
+	writeJSON(w, http.StatusOK, endpoints)
+}
+
+func (s *server) serveView(w http.ResponseWriter, r *http.Request, name string) {
+	v, err := s.cached(name, func() (interface{}, error) { return s.fetch(name) })
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	q, err := parseQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if q.empty() {
+		writeJSON(w, http.StatusOK, v)
+		return
+	}
+	rows, err := toRows(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	rows, err = q.apply(rows)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rows)
+}
+
+func (s *server) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	format := expfmt.TextFormat
+	if strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text") {
+		format = expfmt.OpenMetricsFormat
+	}
+	var buf bytes.Buffer
+	e := expfmt.NewEncoder(&buf, format)
+	statuses := make([]viewStatus, 0, len(s.metricsViews))
+	for _, name := range s.metricsViews {
+		view := name
+		v, err := s.cached(view, func() (interface{}, error) { return s.fetch(view) })
+		statuses = append(statuses, viewStatus{View: view, Up: err == nil})
+		if err != nil {
+			continue
+		}
+		if err := e.Encode(view, v); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if err := e.Encode("scrape", statuses); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := e.Close(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Write(buf.Bytes())
+}
+
+func (s *server) serveHealth(w http.ResponseWriter) {
+	v, err := s.cached("health", func() (interface{}, error) { return s.collect() })
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, healthReport{Status: "error", Error: err.Error(), Time: time.Now(), Findings: make([]health.Finding, 0)})
+		return
+	}
+	snapshot := v.(*health.Snapshot)
+	writeJSON(w, http.StatusOK, healthReport{Status: "ok", Time: snapshot.Time, Findings: health.Check(snapshot)})
+}
+
+// cached returns the value fetched by given function within the cache TTL, or fetches it again.
+// Errors are cached as well, so that a failing database is not queried by every request.
+func (s *server) cached(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	if s.ttl <= 0 {
+		return fetch()
+	}
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	if !ok {
+		entry = &cacheEntry{}
+		s.cache[key] = entry
+	}
+	s.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.fetched.IsZero() || time.Since(entry.fetched) >= s.ttl {
+		entry.value, entry.err = fetch()
+		entry.fetched = time.Now()
+	}
+	return entry.value, entry.err
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		body, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
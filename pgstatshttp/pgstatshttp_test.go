@@ -0,0 +1,158 @@
+package pgstatshttp
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/vynaloze/pgstats"
+	"github.com/vynaloze/pgstats/health"
+	"github.com/vynaloze/pgstats/nullable"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeDatabase struct {
+	fetches int
+}
+
+func (f *fakeDatabase) fetch(view string) (interface{}, error) {
+	f.fetches++
+	switch view {
+	case "pg_stat_database":
+		return pgstats.PgStatDatabaseView{
+			{Datid: 1, Datname: "app", NumBackends: 3, XactCommit: nullable.Int64{NullInt64: sql.NullInt64{Int64: 10, Valid: true}}},
+			{Datid: 2, Datname: "other", NumBackends: 7},
+			{Datid: 3, Datname: "template1", NumBackends: 0, XactCommit: nullable.Int64{NullInt64: sql.NullInt64{Int64: 20, Valid: true}}},
+		}, nil
+	case "pg_stat_bgwriter":
+		return pgstats.PgStatBgWriterView{}, nil
+	}
+	return nil, errors.New("Unsupported PostgreSQL version: 9.400000")
+}
+
+func (f *fakeDatabase) collect() (*health.Snapshot, error) {
+	return &health.Snapshot{Time: time.Unix(1560000000, 0)}, nil
+}
+
+func get(t *testing.T, h http.Handler, target string, header ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func datnames(t *testing.T, w *httptest.ResponseRecorder) string {
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &rows); err != nil {
+		t.Fatalf("%v: %s", err, w.Body.String())
+	}
+	names := make([]string, 0, len(rows))
+	for _, r := range rows {
+		names = append(names, r["datname"].(string))
+	}
+	return strings.Join(names, ",")
+}
+
+func TestViews(t *testing.T) {
+	db := &fakeDatabase{}
+	h := newServer(db.fetch, db.collect)
+
+	for target, expected := range map[string]string{
+		"/databases":                           "app,other,template1",
+		"/pg_stat_database?sort=-numbackends":  "other,app,template1",
+		"/databases?sort=-xact_commit,datname": "template1,app,other",
+		"/databases?datname=app&datname=other": "app,other",
+		"/databases?xact_commit=null":          "other",
+		"/databases?sort=datid&limit=2":        "app,other",
+	} {
+		w := get(t, h, target)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected OK for %s; actual %d: %s", target, w.Code, w.Body.String())
+		}
+		if actual := datnames(t, w); actual != expected {
+			t.Errorf("Expected %s for %s; actual %s", expected, target, actual)
+		}
+	}
+	if db.fetches != 1 {
+		t.Errorf("Expected a single fetch thanks to caching; actual %d", db.fetches)
+	}
+
+	for target, code := range map[string]int{
+		"/nothing":               http.StatusNotFound,
+		"/databases?foo=bar":     http.StatusBadRequest,
+		"/databases?limit=-1":    http.StatusBadRequest,
+		"/bgwriter?sort=buffers": http.StatusBadRequest,
+		"/statements":            http.StatusInternalServerError,
+	} {
+		if w := get(t, h, target); w.Code != code {
+			t.Errorf("Expected %d for %s; actual %d", code, target, w.Code)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	db := &fakeDatabase{}
+	h := newServer(db.fetch, db.collect, MetricsViews("pg_stat_database", "pg_stat_statements"), CacheTTL(0))
+
+	w := get(t, h, "/metrics")
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("Unexpected response %d: %s", w.Code, body)
+	}
+	for _, expected := range []string{
+		`pgstats_pg_stat_database_xact_commit_total{datid="1",datname="app"} 10`,
+		`pgstats_scrape_up{view="pg_stat_database"} 1`,
+		`pgstats_scrape_up{view="pg_stat_statements"} 0`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected %s in:\n%s", expected, body)
+		}
+	}
+
+	w = get(t, h, "/metrics", "Accept", "application/openmetrics-text; version=1.0.0")
+	if !strings.HasSuffix(w.Body.String(), "# EOF\n") {
+		t.Errorf("Expected OpenMetrics output; actual:\n%s", w.Body.String())
+	}
+}
+
+func TestHealth(t *testing.T) {
+	db := &fakeDatabase{}
+	w := get(t, newServer(db.fetch, db.collect), "/health")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"ok"`) {
+		t.Errorf("Unexpected response %d: %s", w.Code, w.Body.String())
+	}
+
+	failing := func() (*health.Snapshot, error) { return nil, errors.New("connection refused") }
+	w = get(t, newServer(db.fetch, failing), "/health")
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "connection refused") {
+		t.Errorf("Unexpected response %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAuth(t *testing.T) {
+	db := &fakeDatabase{}
+	h := newServer(db.fetch, db.collect, BasicAuth("admin", "secret"), BearerToken("token"))
+
+	if w := get(t, h, "/databases"); w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("Expected unauthorized with a challenge; actual %d", w.Code)
+	}
+	if w := get(t, h, "/databases", "Authorization", "Bearer wrong"); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected unauthorized; actual %d", w.Code)
+	}
+	if w := get(t, h, "/databases", "Authorization", "Bearer token"); w.Code != http.StatusOK {
+		t.Errorf("Expected OK with bearer token; actual %d", w.Code)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/databases", nil)
+	r.SetBasicAuth("admin", "secret")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected OK with basic auth; actual %d", w.Code)
+	}
+}
@@ -0,0 +1,174 @@
+package pgstatshttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// row represents a single row of a view, as marshalled to JSON
+type row map[string]interface{}
+
+type sortKey struct {
+	field      string
+	descending bool
+}
+
+// query represents filters, ordering and limit requested in the query string
+type query struct {
+	filters map[string][]string
+	sort    []sortKey
+	limit   int
+}
+
+func parseQuery(values url.Values) (*query, error) {
+	q := &query{filters: make(map[string][]string), limit: -1}
+	for key, v := range values {
+		switch key {
+		case "sort":
+			for _, field := range strings.Split(strings.Join(v, ","), ",") {
+				if field == "" {
+					continue
+				}
+				q.sort = append(q.sort, sortKey{field: strings.TrimPrefix(field, "-"), descending: strings.HasPrefix(field, "-")})
+			}
+		case "limit":
+			limit, err := strconv.Atoi(v[len(v)-1])
+			if err != nil || limit < 0 {
+				return nil, errors.Errorf("Invalid limit: %s", v[len(v)-1])
+			}
+			q.limit = limit
+		default:
+			q.filters[key] = v
+		}
+	}
+	return q, nil
+}
+
+func (q *query) empty() bool {
+	return len(q.filters) == 0 && len(q.sort) == 0 && q.limit < 0
+}
+
+// toRows converts a view to rows, going through JSON so that rows look exactly as served
+func toRows(v interface{}) ([]row, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return nil, errors.New("Filters, sorting and limits apply only to views returning many rows")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	rows := make([]row, 0)
+	if err := decoder.Decode(&rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (q *query) apply(rows []row) ([]row, error) {
+	fields := make([]string, 0, len(q.filters)+len(q.sort))
+	for field := range q.filters {
+		fields = append(fields, field)
+	}
+	for _, key := range q.sort {
+		fields = append(fields, key.field)
+	}
+	if len(rows) > 0 {
+		for _, field := range fields {
+			if _, ok := rows[0][field]; !ok {
+				return nil, errors.Errorf("Unknown field: %s", field)
+			}
+		}
+	}
+
+	filtered := make([]row, 0, len(rows))
+	for _, r := range rows {
+		if q.matches(r) {
+			filtered = append(filtered, r)
+		}
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		for _, key := range q.sort {
+			a, b := filtered[i][key.field], filtered[j][key.field]
+			c := compare(a, b)
+			if c == 0 {
+				continue
+			}
+			if key.descending && a != nil && b != nil {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+	if q.limit >= 0 && q.limit < len(filtered) {
+		filtered = filtered[:q.limit]
+	}
+	return filtered, nil
+}
+
+func (q *query) matches(r row) bool {
+	for field, accepted := range q.filters {
+		value := format(r[field])
+		found := false
+		for _, a := range accepted {
+			if value == a {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// format returns the textual form of a JSON value, as compared with filters
+func format(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// compare orders JSON values: numbers numerically, everything else by textual form; nulls are last,
+// also in descending order
+func compare(a interface{}, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	if na, ok := a.(json.Number); ok {
+		if nb, ok := b.(json.Number); ok {
+			fa, _ := na.Float64()
+			fb, _ := nb.Float64()
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(format(a), format(b))
+}
@@ -0,0 +1,39 @@
+package push
+
+import (
+	"github.com/vynaloze/pgstats/internal/schema"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// graphiteTagEscaper replaces characters not allowed in Graphite tags
+var graphiteTagEscaper = strings.NewReplacer(`;`, `_`, `~`, `_`, ` `, `_`, `!`, `_`, `^`, `_`, `=`, `_`, "\n", `_`, "\r", `_`)
+
+// graphite renders a line per value, with labels as tags. Empty tags are omitted,
+// as well as NaN and infinite values.
+func (e *Emitter) graphite(prefix string, families []schema.Family) []string {
+	timestamp := strconv.FormatInt(e.Now().Unix(), 10)
+	lines := make([]string, 0)
+	for _, family := range families {
+		name := prefix + "." + family.Name
+		for _, sample := range family.Samples {
+			if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+				continue
+			}
+			lines = append(lines, name+graphiteTags(e.tags(sample.Labels))+" "+formatValue(sample.Value)+" "+timestamp)
+		}
+	}
+	return lines
+}
+
+func graphiteTags(tags []schema.Label) string {
+	var b strings.Builder
+	for _, t := range tags {
+		if t.Value == "" {
+			continue
+		}
+		b.WriteString(";" + graphiteTagEscaper.Replace(t.Name) + "=" + graphiteTagEscaper.Replace(t.Value))
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package push
+
+import (
+	"github.com/vynaloze/pgstats/internal/schema"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	measurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `)
+	// line breaks cannot be escaped in the line protocol, so they are replaced with escaped spaces
+	influxKeyEscaper = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `, "\n", `\ `, "\r", `\ `)
+)
+
+// influxPoint represents a single line: values of a single row sharing the same tags
+type influxPoint struct {
+	tags   string
+	fields []string
+}
+
+// influx renders a line per row of the view. Empty tags are omitted, as well as values
+// not representable in the line protocol (NaN and infinities).
+func (e *Emitter) influx(measurement string, families []schema.Family) []string {
+	points := make([]*influxPoint, 0)
+	index := make(map[string]*influxPoint)
+	for _, family := range families {
+		for _, sample := range family.Samples {
+			if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+				continue
+			}
+			tags := influxTags(e.sortedTags(sample.Labels))
+			p, ok := index[tags]
+			if !ok {
+				p = &influxPoint{tags: tags}
+				index[tags] = p
+				points = append(points, p)
+			}
+			p.fields = append(p.fields, influxKeyEscaper.Replace(family.Name)+"="+formatValue(sample.Value))
+		}
+	}
+
+	timestamp := strconv.FormatInt(e.Now().UnixNano(), 10)
+	lines := make([]string, 0, len(points))
+	for _, p := range points {
+		lines = append(lines, measurementEscaper.Replace(measurement)+p.tags+" "+strings.Join(p.fields, ",")+" "+timestamp)
+	}
+	return lines
+}
+
+func influxTags(tags []schema.Label) string {
+	var b strings.Builder
+	for _, t := range tags {
+		if t.Value == "" {
+			continue
+		}
+		b.WriteString("," + influxKeyEscaper.Replace(t.Name) + "=" + influxKeyEscaper.Replace(t.Value))
+	}
+	return b.String()
+}
@@ -0,0 +1,160 @@
+// Package push serializes pgstats views and snapshots for push-based pipelines:
+// InfluxDB line protocol, Graphite plaintext protocol and StatsD.
+// Output can be written to any io.Writer, or sent to a UDP or TCP endpoint.
+// Times, counted by PostgreSQL in milliseconds, are sent in seconds.
+package push
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"github.com/vynaloze/pgstats/internal/schema"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format represents a wire format
+type Format int
+
+const (
+	// Influx is the InfluxDB line protocol: a line per row, with labels as tags and values as fields
+	Influx Format = iota
+	// Graphite is the Graphite plaintext protocol with tags (Graphite 1.1 and later): a line per value
+	Graphite
+	// StatsD is the StatsD protocol with DogStatsD tags: counters are sent as deltas, gauges as values
+	StatsD
+)
+
+// DefaultPrefix is the prefix of all measurement and metric names, unless changed in the Emitter
+const DefaultPrefix = "pgstats"
+
+// maxDatagramSize keeps UDP datagrams within the usual MTU
+const maxDatagramSize = 1432
+
+// Emitter writes views in the chosen format. It is not safe for concurrent use.
+type Emitter struct {
+	// Prefix of all measurement and metric names. May be empty.
+	Prefix string
+	// Tags renames labels of rows: keys are label names (json tags), values are tag names.
+	// Labels renamed to an empty string are dropped. Labels missing in the map keep their names.
+	Tags map[string]string
+	// Now returns the timestamp of emitted values. Defaults to time.Now.
+	Now func() time.Time
+
+	w      io.Writer
+	format Format
+	// maximum size of a single write, if output is split into datagrams
+	maxWrite int
+	// last values of counters by emitted name, then by metric name and tags, used to compute StatsD deltas
+	counters map[string]map[string]float64
+}
+
+// NewEmitter returns a new emitter writing to w in given format
+func NewEmitter(w io.Writer, format Format) *Emitter {
+	return &Emitter{
+		Prefix:   DefaultPrefix,
+		Now:      time.Now,
+		w:        w,
+		format:   format,
+		counters: make(map[string]map[string]float64),
+	}
+}
+
+// Dial connects to the endpoint on given network ("udp" or "tcp") and address
+// and returns an emitter sending values there. Over UDP, output is split into datagrams
+// of at most 1432 bytes, never splitting a line.
+// The emitter should be closed once it is no longer needed.
+func Dial(network string, address string, format Format) (*Emitter, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	e := NewEmitter(conn, format)
+	if strings.HasPrefix(network, "udp") {
+		e.maxWrite = maxDatagramSize
+	}
+	return e, nil
+}
+
+// Close closes the underlying writer if it can be closed, e.g. the connection opened by Dial
+func (e *Emitter) Close() error {
+	if c, ok := e.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Emit writes all values of v - a view, a single row or a snapshot built from views -
+// under given name, e.g. "pg_stat_database".
+func (e *Emitter) Emit(name string, v interface{}) error {
+	families := schema.Flatten(v)
+	var lines []string
+	switch e.format {
+	case Influx:
+		lines = e.influx(e.join("_", name), families)
+	case Graphite:
+		lines = e.graphite(e.join(".", name), families)
+	case StatsD:
+		lines = e.statsd(e.join(".", name), families)
+	default:
+		return errors.Errorf("Unknown format: %d", e.format)
+	}
+	return e.write(lines)
+}
+
+func (e *Emitter) write(lines []string) error {
+	var buf bytes.Buffer
+	for _, line := range lines {
+		if e.maxWrite > 0 && buf.Len() > 0 && buf.Len()+len(line)+1 > e.maxWrite {
+			if _, err := e.w.Write(buf.Bytes()); err != nil {
+				return err
+			}
+			buf.Reset()
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	if buf.Len() == 0 {
+		return nil
+	}
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+func (e *Emitter) join(separator string, name string) string {
+	if e.Prefix == "" {
+		return schema.Sanitize(name)
+	}
+	return e.Prefix + separator + schema.Sanitize(name)
+}
+
+// tags renames labels according to the tag mapping, dropping the ones renamed to an empty string
+func (e *Emitter) tags(labels []schema.Label) []schema.Label {
+	tags := make([]schema.Label, 0, len(labels))
+	for _, l := range labels {
+		name, ok := e.Tags[l.Name]
+		if !ok {
+			name = l.Name
+		}
+		if name != "" {
+			tags = append(tags, schema.Label{Name: name, Value: l.Value})
+		}
+	}
+	return tags
+}
+
+// sortedTags returns renamed labels ordered by name, as recommended by InfluxDB
+func (e *Emitter) sortedTags(labels []schema.Label) []schema.Label {
+	tags := e.tags(labels)
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+	return tags
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
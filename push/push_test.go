@@ -0,0 +1,161 @@
+package push
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"github.com/vynaloze/pgstats"
+	"github.com/vynaloze/pgstats/internal/schema"
+	"github.com/vynaloze/pgstats/nullable"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func view(commits int64) pgstats.PgStatDatabaseView {
+	return pgstats.PgStatDatabaseView{
+		{Datid: 1, Datname: "my app", NumBackends: 3, XactCommit: nullable.Int64{NullInt64: sql.NullInt64{Int64: commits, Valid: true}}},
+		{Datid: 2, Datname: "", NumBackends: -1},
+	}
+}
+
+func emitter(w *bytes.Buffer, format Format) *Emitter {
+	e := NewEmitter(w, format)
+	e.Now = func() time.Time { return time.Unix(1560000000, 5) }
+	return e
+}
+
+func TestInflux(t *testing.T) {
+	var buf bytes.Buffer
+	e := emitter(&buf, Influx)
+	e.Tags = map[string]string{"datname": "db", "datid": ""}
+	if err := e.Emit("pg_stat_database", view(10)); err != nil {
+		t.Fatal(err)
+	}
+	if actual := influxTags([]schema.Label{{Name: "query", Value: "select 1\r\nfrom t"}}); actual != `,query=select\ 1\ \ from\ t` {
+		t.Errorf("Expected line breaks of the query escaped; actual %s", actual)
+	}
+	expected := `pgstats_pg_stat_database,db=my\ app numbackends=3,xact_commit=10 1560000000000000005
+pgstats_pg_stat_database numbackends=-1 1560000000000000005
+`
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Expected:\n%s\nactual:\n%s", expected, actual)
+	}
+}
+
+func TestGraphite(t *testing.T) {
+	var buf bytes.Buffer
+	e := emitter(&buf, Graphite)
+	e.Prefix = "pg"
+	if err := e.Emit("pg_stat_database", view(10)); err != nil {
+		t.Fatal(err)
+	}
+	if actual := graphiteTags([]schema.Label{{Name: "query", Value: "select 1\r\nfrom t"}}); actual != ";query=select_1__from_t" {
+		t.Errorf("Expected line breaks of the query replaced; actual %s", actual)
+	}
+	expected := `pg.pg_stat_database.numbackends;datid=1;datname=my_app 3 1560000000
+pg.pg_stat_database.numbackends;datid=2 -1 1560000000
+pg.pg_stat_database.xact_commit;datid=1;datname=my_app 10 1560000000
+`
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Expected:\n%s\nactual:\n%s", expected, actual)
+	}
+}
+
+func TestStatsD(t *testing.T) {
+	var buf bytes.Buffer
+	e := emitter(&buf, StatsD)
+	for _, commits := range []int64{10, 15, 4} {
+		if err := e.Emit("pg_stat_database", view(commits)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gauges := `pgstats.pg_stat_database.numbackends:3|g|#datid:1,datname:my_app
+pgstats.pg_stat_database.numbackends:0|g|#datid:2
+pgstats.pg_stat_database.numbackends:-1|g|#datid:2
+`
+	expected := gauges +
+		gauges + "pgstats.pg_stat_database.xact_commit:5|c|#datid:1,datname:my_app\n" +
+		gauges + "pgstats.pg_stat_database.xact_commit:4|c|#datid:1,datname:my_app\n"
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Expected:\n%s\nactual:\n%s", expected, actual)
+	}
+
+	// counters of rows gone from the view are forgotten
+	if err := e.Emit("pg_stat_database", pgstats.PgStatDatabaseView{}); err != nil {
+		t.Fatal(err)
+	}
+	if counters := e.counters["pgstats.pg_stat_database"]; len(counters) != 0 {
+		t.Errorf("Expected no counters remembered; actual %v", counters)
+	}
+}
+
+func TestDialUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	e, err := Dial("udp", conn.LocalAddr().String(), Graphite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	rows := make(pgstats.PgStatDatabaseView, 100)
+	for i := range rows {
+		rows[i].Datid = int64(i)
+	}
+	if err := e.Emit("pg_stat_database", rows); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := 0
+	buf := make([]byte, 65536)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for lines < len(rows) {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n > maxDatagramSize || buf[n-1] != '\n' {
+			t.Fatalf("Expected datagrams of whole lines within %d bytes; actual %d bytes", maxDatagramSize, n)
+		}
+		lines += strings.Count(string(buf[:n]), "\n")
+	}
+	if lines != len(rows) {
+		t.Errorf("Expected %d lines; actual %d", len(rows), lines)
+	}
+}
+
+func TestDialTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- err.Error()
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		received <- line
+	}()
+
+	e, err := Dial("tcp", listener.Addr().String(), Influx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	if err := e.Emit("pg_stat_database", view(10)[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if line := <-received; !strings.HasPrefix(line, "pgstats_pg_stat_database,datid=1,datname=my\\ app numbackends=3,xact_commit=10 ") {
+		t.Errorf("Unexpected line: %s", line)
+	}
+}
@@ -0,0 +1,64 @@
+package push
+
+import (
+	"github.com/vynaloze/pgstats/internal/schema"
+	"math"
+	"strings"
+)
+
+// statsdEscaper replaces characters with special meaning in StatsD lines and DogStatsD tags
+var statsdEscaper = strings.NewReplacer(`:`, `_`, `|`, `_`, `,`, `_`, `#`, `_`, "\n", `_`, "\r", `_`, ` `, `_`)
+
+// statsd renders a line per value. Counters are sent as the difference from the previous emit of the same
+// counter (nothing on the first emit, the whole value after a reset); gauges are sent as values.
+// Only counters of the current emit are remembered, so that rows which disappeared, e.g. exited backends,
+// do not accumulate. NaN and infinite values are omitted.
+func (e *Emitter) statsd(prefix string, families []schema.Family) []string {
+	lines := make([]string, 0)
+	previous := e.counters[prefix]
+	current := make(map[string]float64, len(previous))
+	for _, family := range families {
+		name := statsdEscaper.Replace(prefix + "." + family.Name)
+		for _, sample := range family.Samples {
+			value := sample.Value
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				continue
+			}
+			tags := statsdTags(e.tags(sample.Labels))
+			if family.Kind == schema.Counter {
+				key := name + tags
+				last, ok := previous[key]
+				current[key] = value
+				if !ok {
+					continue
+				}
+				if value >= last {
+					value -= last
+				}
+				lines = append(lines, name+":"+formatValue(value)+"|c"+tags)
+				continue
+			}
+			// a leading sign makes a gauge relative, so negative values have to be set from zero
+			if value < 0 {
+				lines = append(lines, name+":0|g"+tags)
+			}
+			lines = append(lines, name+":"+formatValue(value)+"|g"+tags)
+		}
+	}
+	e.counters[prefix] = current
+	return lines
+}
+
+func statsdTags(tags []schema.Label) string {
+	parts := make([]string, 0, len(tags))
+	for _, t := range tags {
+		if t.Value == "" {
+			continue
+		}
+		parts = append(parts, statsdEscaper.Replace(t.Name)+":"+statsdEscaper.Replace(t.Value))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "|#" + strings.Join(parts, ",")
+}
@@ -0,0 +1,199 @@
+package pgstats
+
+import (
+	"github.com/vynaloze/pgstats/nullable"
+)
+
+// ReplicationLagView represents replication lag of each standby connected to the current server
+type ReplicationLagView []ReplicationLagRow
+
+// ReplicationLagRow represents replication lag of a single standby, as seen by the sending server
+type ReplicationLagRow struct {
+	// Process ID of the WAL sender process
+	Pid int64 `json:"pid"`
+	// Name of the application that is connected to this WAL sender
+	ApplicationName nullable.String `json:"application_name"`
+	// IP address of the standby connected to this WAL sender
+	ClientAddr nullable.String `json:"client_addr"`
+	// Current WAL sender state
+	State nullable.String `json:"state"`
+	// Synchronous state of this standby server
+	SyncState nullable.String `json:"sync_state"`
+	// Priority of this standby server for being chosen as the synchronous standby
+	SyncPriority nullable.Int64 `json:"sync_priority"`
+	// Current write-ahead log location of the sending server the lag is computed against
+	// (pg_current_wal_lsn, or the last location received if the sending server is itself a standby)
+	CurrentLsn nullable.String `json:"current_lsn"`
+	// Last write-ahead log location replayed by this standby
+	ReplayLsn nullable.String `json:"replay_lsn"`
+	// Amount of WAL not yet sent to this standby, in bytes
+	SentLagBytes nullable.Int64 `json:"sent_lag_bytes"`
+	// Amount of WAL not yet written to disk by this standby, in bytes
+	WriteLagBytes nullable.Int64 `json:"write_lag_bytes"`
+	// Amount of WAL not yet flushed to disk by this standby, in bytes
+	FlushLagBytes nullable.Int64 `json:"flush_lag_bytes"`
+	// Amount of WAL not yet replayed by this standby, in bytes
+	ReplayLagBytes nullable.Int64 `json:"replay_lag_bytes"`
+	// Time elapsed between flushing recent WAL locally and receiving notification that this standby has written it,
+	// in seconds. Supported since PostgreSQL 10
+	WriteLag nullable.Float64 `json:"write_lag"`
+	// Time elapsed between flushing recent WAL locally and receiving notification that this standby has flushed it,
+	// in seconds. Supported since PostgreSQL 10
+	FlushLag nullable.Float64 `json:"flush_lag"`
+	// Time elapsed between flushing recent WAL locally and receiving notification that this standby has applied it,
+	// in seconds. Supported since PostgreSQL 10
+	ReplayLag nullable.Float64 `json:"replay_lag"`
+	// Name of the replication slot used by this standby, if any.
+	// Supported since PostgreSQL 9.5
+	SlotName nullable.String `json:"slot_name"`
+	// Type of the replication slot used by this standby, if any: physical or logical.
+	// Supported since PostgreSQL 9.5
+	SlotType nullable.String `json:"slot_type"`
+	// Amount of WAL retained by the replication slot, in bytes.
+	// Supported since PostgreSQL 9.5
+	SlotRetainedBytes nullable.Int64 `json:"slot_retained_bytes"`
+}
+
+// StandbyLagView represents replication lag of the current server, as seen by itself if it is a standby
+type StandbyLagView struct {
+	// True if the server is in recovery, i.e. it is a standby
+	InRecovery bool `json:"in_recovery"`
+	// Activity status of the WAL receiver process, if running.
+	// Supported since PostgreSQL 9.6
+	Status nullable.String `json:"status"`
+	// Host of the server this standby is connected to.
+	// Supported since PostgreSQL 11
+	SenderHost nullable.String `json:"sender_host"`
+	// Port number of the server this standby is connected to.
+	// Supported since PostgreSQL 11
+	SenderPort nullable.Int64 `json:"sender_port"`
+	// Replication slot name used by the WAL receiver.
+	// Supported since PostgreSQL 9.6
+	SlotName nullable.String `json:"slot_name"`
+	// Time of receipt of the last message received from the sending server.
+	// Supported since PostgreSQL 9.6
+	LastMsgReceiptTime nullable.Time `json:"last_msg_receipt_time"`
+	// Last write-ahead log location received and synced to disk by streaming replication
+	ReceiveLsn nullable.String `json:"receive_lsn"`
+	// Last write-ahead log location replayed during recovery
+	ReplayLsn nullable.String `json:"replay_lsn"`
+	// Amount of WAL received but not yet replayed, in bytes
+	ReplayLagBytes nullable.Int64 `json:"replay_lag_bytes"`
+	// Time stamp of the last transaction replayed during recovery
+	LastXactReplayTimestamp nullable.Time `json:"last_xact_replay_timestamp"`
+	// Time elapsed since the last replayed transaction was committed on the primary, in seconds.
+	// Note that it grows also when there is no write activity on the primary.
+	ReplayDelay nullable.Float64 `json:"replay_delay"`
+}
+
+// walFunctions holds names of WAL related functions and columns,
+// renamed in PostgreSQL 10 from "xlog" and "location" to "wal" and "lsn"
+type walFunctions struct {
+	lsnDiff    string
+	currentLsn string
+	receiveLsn string
+	replayLsn  string
+	lsnColumn  string
+}
+
+func walFunctionsFor(version float64) walFunctions {
+	if version >= 10 {
+		return walFunctions{
+			lsnDiff:    "pg_wal_lsn_diff",
+			currentLsn: "pg_current_wal_lsn",
+			receiveLsn: "pg_last_wal_receive_lsn",
+			replayLsn:  "pg_last_wal_replay_lsn",
+			lsnColumn:  "lsn",
+		}
+	}
+	return walFunctions{
+		lsnDiff:    "pg_xlog_location_diff",
+		currentLsn: "pg_current_xlog_location",
+		receiveLsn: "pg_last_xlog_receive_location",
+		replayLsn:  "pg_last_xlog_replay_location",
+		lsnColumn:  "location",
+	}
+}
+
+// currentLsnExpr returns an expression evaluating to the current WAL location of the server,
+// which is the last received location on a standby
+func (f walFunctions) currentLsnExpr() string {
+	return "case when pg_is_in_recovery() then coalesce(" + f.receiveLsn + "()," + f.replayLsn + "()) " +
+		"else " + f.currentLsn + "() end"
+}
+
+func (s *PgStats) fetchReplicationLag() (ReplicationLagView, error) {
+	version, err := s.getPgVersion()
+	if err != nil {
+		return nil, err
+	}
+	f := walFunctionsFor(version)
+	lagTimes := "null::float8,null::float8,null::float8"
+	if version >= 10 {
+		lagTimes = "extract(epoch from r.write_lag),extract(epoch from r.flush_lag),extract(epoch from r.replay_lag)"
+	}
+	slot := "null::text,null::text,null::bigint "
+	slotJoin := ""
+	if version > 9.4 {
+		slot = "sl.slot_name::text,sl.slot_type," + f.lsnDiff + "(c.lsn,sl.restart_lsn)::bigint "
+		slotJoin = "left join pg_replication_slots sl on sl.active_pid=r.pid"
+	}
+
+	db := s.db()
+	query := "select r.pid,r.application_name,r.client_addr::text,r.state,r.sync_state,r.sync_priority," +
+		"c.lsn::text,r.replay_" + f.lsnColumn + "::text," +
+		f.lsnDiff + "(c.lsn,r.sent_" + f.lsnColumn + ")::bigint," +
+		f.lsnDiff + "(c.lsn,r.write_" + f.lsnColumn + ")::bigint," +
+		f.lsnDiff + "(c.lsn,r.flush_" + f.lsnColumn + ")::bigint," +
+		f.lsnDiff + "(c.lsn,r.replay_" + f.lsnColumn + ")::bigint," +
+		lagTimes + "," + slot +
+		"from pg_stat_replication r cross join (select " + f.currentLsnExpr() + " lsn) c " + slotJoin
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make(ReplicationLagView, 0)
+	for rows.Next() {
+		row := new(ReplicationLagRow)
+		err := rows.Scan(&row.Pid, &row.ApplicationName, &row.ClientAddr, &row.State, &row.SyncState, &row.SyncPriority,
+			&row.CurrentLsn, &row.ReplayLsn, &row.SentLagBytes, &row.WriteLagBytes, &row.FlushLagBytes, &row.ReplayLagBytes,
+			&row.WriteLag, &row.FlushLag, &row.ReplayLag, &row.SlotName, &row.SlotType, &row.SlotRetainedBytes)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, *row)
+	}
+	return data, rows.Err()
+}
+
+func (s *PgStats) fetchStandbyLag() (StandbyLagView, error) {
+	version, err := s.getPgVersion()
+	if err != nil {
+		return StandbyLagView{}, err
+	}
+	f := walFunctionsFor(version)
+	receiver := "null::text,null::text,null::int,null::text,null::timestamptz"
+	receiverJoin := ""
+	if version >= 9.6 {
+		receiver = "w.status,null::text,null::int,w.slot_name,w.last_msg_receipt_time"
+		receiverJoin = "left join pg_stat_wal_receiver w on true"
+	}
+	if version >= 11 {
+		receiver = "w.status,w.sender_host,w.sender_port,w.slot_name,w.last_msg_receipt_time"
+	}
+
+	db := s.db()
+	query := "select pg_is_in_recovery()," + receiver + "," +
+		f.receiveLsn + "()::text," + f.replayLsn + "()::text," +
+		f.lsnDiff + "(" + f.receiveLsn + "()," + f.replayLsn + "())::bigint," +
+		"pg_last_xact_replay_timestamp(),extract(epoch from now()-pg_last_xact_replay_timestamp()) " +
+		"from (select 1) d " + receiverJoin
+	row := db.QueryRow(query)
+	res := new(StandbyLagView)
+	err = row.Scan(&res.InRecovery, &res.Status, &res.SenderHost, &res.SenderPort, &res.SlotName, &res.LastMsgReceiptTime,
+		&res.ReceiveLsn, &res.ReplayLsn, &res.ReplayLagBytes, &res.LastXactReplayTimestamp, &res.ReplayDelay)
+	return *res, err
+}
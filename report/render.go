@@ -0,0 +1,89 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+const timeLayout = "2006-01-02 15:04:05 MST"
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"time": func(t time.Time) string { return t.Format(timeLayout) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Workload report: {{.Database}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+th { background: #eee; }
+p.note { color: #555; }
+</style>
+</head>
+<body>
+<h1>Workload report: {{.Database}}</h1>
+<table>
+<tr><th>Begin</th><td>{{time .Begin}} (snapshot {{.BeginID}})</td></tr>
+<tr><th>End</th><td>{{time .End}} (snapshot {{.EndID}})</td></tr>
+<tr><th>Duration</th><td>{{.Duration}}</td></tr>
+</table>
+{{range .Sections}}
+<h2>{{.Title}}</h2>
+<p class="note">{{.Note}}</p>
+{{if .Rows}}<table>
+<tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{else}}<p>No data.</p>
+{{end}}{{end}}</body>
+</html>
+`))
+
+// WriteHTML writes the report as a standalone HTML page
+func (r *Report) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, r)
+}
+
+// markdownEscaper keeps cells, e.g. query texts, from breaking the table or being rendered as markup or HTML
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "`", "\\`", "*", `\*`, "_", `\_`,
+	"<", "&lt;", ">", "&gt;", "&", "&amp;", "\n", " ", "\r", "")
+
+// WriteMarkdown writes the report in Markdown, with sections as tables
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Workload report: %s\n\n", r.Database)
+	fmt.Fprintf(&b, "- Begin: %s (snapshot %d)\n", r.Begin.Format(timeLayout), r.BeginID)
+	fmt.Fprintf(&b, "- End: %s (snapshot %d)\n", r.End.Format(timeLayout), r.EndID)
+	fmt.Fprintf(&b, "- Duration: %v\n", r.Duration())
+	for _, s := range r.Sections {
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n\n", s.Title, s.Note)
+		if len(s.Rows) == 0 {
+			b.WriteString("No data.\n")
+			continue
+		}
+		writeMarkdownRow(&b, s.Columns)
+		separators := make([]string, len(s.Columns))
+		for i := range separators {
+			separators[i] = "---"
+		}
+		b.WriteString("| " + strings.Join(separators, " | ") + " |\n")
+		for _, row := range s.Rows {
+			writeMarkdownRow(&b, row)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = markdownEscaper.Replace(cell)
+	}
+	b.WriteString("| " + strings.Join(escaped, " | ") + " |\n")
+}
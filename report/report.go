@@ -0,0 +1,542 @@
+// Package report generates workload reports between two stored snapshots, in the spirit of Oracle AWR:
+// database throughput, top SQL, hottest tables and indexes, checkpoints, wait events and temporary files.
+// Reports are rendered as HTML or Markdown.
+//
+// Snapshots should hold the views listed in Views, e.g. taken with history.Take(stats).
+// Sections whose views are missing in any of the snapshots are reported as not available.
+package report
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/vynaloze/pgstats"
+	"github.com/vynaloze/pgstats/history"
+	"github.com/vynaloze/pgstats/nullable"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTop is the number of statements, tables and indexes reported in each section, unless given
+const DefaultTop = 10
+
+// Views are used by the report; all of them are saved by history.Take by default
+var Views = []string{
+	"pg_stat_database",
+	"pg_stat_bgwriter",
+	"pg_stat_statements",
+	"pg_stat_user_tables",
+	"pg_statio_user_tables",
+	"pg_stat_user_indexes",
+	"pg_statio_user_indexes",
+	"pg_stat_activity",
+}
+
+// maximum length of query texts in tables
+const queryLength = 100
+
+// Report represents the workload between two snapshots
+type Report struct {
+	// Name of the database the snapshots have been taken from
+	Database string
+	// Snapshots the report is built from
+	BeginID int64
+	EndID   int64
+	// Times at which the snapshots have been taken
+	Begin time.Time
+	End   time.Time
+	// Sections of the report, in order
+	Sections []Section
+}
+
+// Section represents a single table of the report
+type Section struct {
+	Title string
+	// Explanation of the section, or the reason why it is not available
+	Note    string
+	Columns []string
+	Rows    [][]string
+}
+
+// Duration returns the length of the interval between the snapshots
+func (r *Report) Duration() time.Duration {
+	return r.End.Sub(r.Begin)
+}
+
+// New builds the report for the interval between snapshots begin and end, with top entries
+// (DefaultTop, if top is not positive) in sections of statements, tables and indexes
+func New(begin *history.Snapshot, end *history.Snapshot, top int) (*Report, error) {
+	if !end.Time.After(begin.Time) {
+		return nil, errors.Errorf("Snapshot %d must be taken after snapshot %d", end.ID, begin.ID)
+	}
+	if begin.Database != end.Database {
+		return nil, errors.Errorf("Snapshots have been taken from different databases: %s and %s", begin.Database, end.Database)
+	}
+	if top <= 0 {
+		top = DefaultTop
+	}
+	r := &Report{Database: begin.Database, BeginID: begin.ID, EndID: end.ID, Begin: begin.Time, End: end.Time}
+	b := builder{begin: begin, end: end, top: top, seconds: r.Duration().Seconds()}
+	r.Sections = []Section{
+		b.throughput(),
+		b.topStatements("Top SQL by time", "Statements which took most time to execute", "time"),
+		b.topStatements("Top SQL by I/O", "Statements which read most blocks from disk or the OS cache", "io"),
+		b.topStatements("Top SQL by calls", "Statements executed most often", "calls"),
+		b.tables(),
+		b.indexes(),
+		b.checkpoints(),
+		b.waits(),
+		b.temp(),
+	}
+	return r, nil
+}
+
+// builder computes sections of the report
+type builder struct {
+	begin   *history.Snapshot
+	end     *history.Snapshot
+	top     int
+	seconds float64
+}
+
+// load decodes the view from both snapshots, returning false if the view is missing in any of them
+func (b *builder) load(name string, begin interface{}, end interface{}) (bool, string) {
+	for _, snap := range []*history.Snapshot{b.begin, b.end} {
+		if _, ok := snap.Views[name]; !ok {
+			reason := "not captured"
+			if err, ok := snap.Errors[name]; ok {
+				reason = err
+			}
+			return false, fmt.Sprintf("Not available: %s in snapshot %d: %s", name, snap.ID, reason)
+		}
+	}
+	if err := b.begin.Decode(name, begin); err != nil {
+		return false, fmt.Sprintf("Not available: %s", err)
+	}
+	if err := b.end.Decode(name, end); err != nil {
+		return false, fmt.Sprintf("Not available: %s", err)
+	}
+	return true, ""
+}
+
+func (b *builder) throughput() Section {
+	s := Section{
+		Title:   "Database throughput",
+		Note:    "Transactions and tuples processed in each database within the interval",
+		Columns: []string{"Database", "Commits", "Commits/s", "Rollbacks", "Tuples returned", "Tuples fetched", "Tuples inserted", "Tuples updated", "Tuples deleted", "Cache hit ratio", "Deadlocks"},
+	}
+	var begin, end pgstats.PgStatDatabaseView
+	if ok, reason := b.load("pg_stat_database", &begin, &end); !ok {
+		s.Note = reason
+		return s
+	}
+	previous := make(map[int64]pgstats.PgStatDatabaseRow, len(begin))
+	for _, p := range begin {
+		previous[p.Datid] = p
+	}
+	for _, e := range end {
+		p, ok := previous[e.Datid]
+		if !ok {
+			continue
+		}
+		commits := delta(e.XactCommit, p.XactCommit)
+		rollbacks := delta(e.XactRollback, p.XactRollback)
+		if commits+rollbacks == 0 {
+			continue
+		}
+		s.Rows = append(s.Rows, []string{
+			e.Datname,
+			formatInt(commits),
+			b.rate(commits),
+			formatInt(rollbacks),
+			formatInt(delta(e.TupReturned, p.TupReturned)),
+			formatInt(delta(e.TupFetched, p.TupFetched)),
+			formatInt(delta(e.TupInserted, p.TupInserted)),
+			formatInt(delta(e.TupUpdated, p.TupUpdated)),
+			formatInt(delta(e.TupDeleted, p.TupDeleted)),
+			ratio(delta(e.BlksHit, p.BlksHit), delta(e.BlksRead, p.BlksRead)),
+			formatInt(delta(e.Deadlocks, p.Deadlocks)),
+		})
+	}
+	return s
+}
+
+// statements returns the delta of pg_stat_statements, or the reason why it is not available
+func (b *builder) statements() (*pgstats.PgStatStatementsDelta, string) {
+	var begin, end pgstats.PgStatStatementsView
+	if ok, reason := b.load("pg_stat_statements", &begin, &end); !ok {
+		return nil, reason
+	}
+	d := end.Delta(begin)
+	return &d, ""
+}
+
+func (b *builder) topStatements(title string, note string, by string) Section {
+	s := Section{Title: title, Note: note}
+	switch by {
+	case "time":
+		s.Columns = []string{"Query", "Total time (ms)", "% of time", "Calls", "Mean time (ms)", "Rows"}
+	case "io":
+		s.Columns = []string{"Query", "Blocks read", "Blocks hit", "Cache hit ratio", "Read time (ms)", "Calls"}
+	case "calls":
+		s.Columns = []string{"Query", "Calls", "Calls/s", "Mean time (ms)", "Rows"}
+	}
+	d, reason := b.statements()
+	if d == nil {
+		s.Note = reason
+		return s
+	}
+	rows := append([]pgstats.PgStatStatementsDeltaRow(nil), d.Rows...)
+	sort.SliceStable(rows, func(i, j int) bool {
+		switch by {
+		case "io":
+			return rows[i].SharedBlksRead+rows[i].LocalBlksRead > rows[j].SharedBlksRead+rows[j].LocalBlksRead
+		case "calls":
+			return rows[i].Calls > rows[j].Calls
+		}
+		return rows[i].TotalTime > rows[j].TotalTime
+	})
+	for _, r := range rows {
+		if len(s.Rows) == b.top {
+			break
+		}
+		query := shorten(r.Query)
+		switch by {
+		case "time":
+			s.Rows = append(s.Rows, []string{query, formatFloat(r.TotalTime), percent(r.TimeShare), formatInt(r.Calls), formatFloat(r.MeanTime), formatInt(r.Rows)})
+		case "io":
+			read := r.SharedBlksRead + r.LocalBlksRead
+			if read == 0 {
+				return s
+			}
+			hit := r.SharedBlksHit + r.LocalBlksHit
+			s.Rows = append(s.Rows, []string{query, formatInt(read), formatInt(hit), ratio(hit, read), formatFloat(r.BlkReadTime), formatInt(r.Calls)})
+		case "calls":
+			s.Rows = append(s.Rows, []string{query, formatInt(r.Calls), b.rate(r.Calls), formatFloat(r.MeanTime), formatInt(r.Rows)})
+		}
+	}
+	return s
+}
+
+func (b *builder) tables() Section {
+	s := Section{
+		Title:   "Hottest tables",
+		Note:    "Tables with most tuples read and modified within the interval",
+		Columns: []string{"Table", "Seq scans", "Seq tuples read", "Index scans", "Index tuples fetched", "Inserted", "Updated", "Deleted", "Heap blocks read", "Heap cache hit ratio"},
+	}
+	var begin, end pgstats.PgStatUserTablesView
+	if ok, reason := b.load("pg_stat_user_tables", &begin, &end); !ok {
+		s.Note = reason
+		return s
+	}
+	var ioBegin, ioEnd pgstats.PgStatIoUserTablesView
+	io, _ := b.load("pg_statio_user_tables", &ioBegin, &ioEnd)
+	ioPrevious := make(map[int64]pgstats.PgStatIoTablesRow, len(ioBegin))
+	for _, p := range ioBegin {
+		ioPrevious[p.Relid] = p
+	}
+	ioCurrent := make(map[int64]pgstats.PgStatIoTablesRow, len(ioEnd))
+	for _, c := range ioEnd {
+		ioCurrent[c.Relid] = c
+	}
+
+	previous := make(map[int64]pgstats.PgStatTablesRow, len(begin))
+	for _, p := range begin {
+		previous[p.Relid] = p
+	}
+	type hot struct {
+		activity int64
+		row      []string
+	}
+	hottest := make([]hot, 0)
+	for _, e := range end {
+		p, ok := previous[e.Relid]
+		if !ok {
+			continue
+		}
+		seqTupRead := delta(e.SeqTupRead, p.SeqTupRead)
+		idxTupFetch := delta(e.IdxTupFetch, p.IdxTupFetch)
+		ins, upd, del := delta(e.NTupIns, p.NTupIns), delta(e.NTupUpd, p.NTupUpd), delta(e.NTupDel, p.NTupDel)
+		activity := seqTupRead + idxTupFetch + ins + upd + del
+		if activity == 0 {
+			continue
+		}
+		blksRead, hitRatio := "", ""
+		if io {
+			if c, ok := ioCurrent[e.Relid]; ok {
+				if p, ok := ioPrevious[e.Relid]; ok {
+					read := delta(c.HeapBlksRead, p.HeapBlksRead)
+					blksRead, hitRatio = formatInt(read), ratio(delta(c.HeapBlksHit, p.HeapBlksHit), read)
+				}
+			}
+		}
+		hottest = append(hottest, hot{activity, []string{
+			e.Schemaname + "." + e.Relname,
+			formatInt(delta(e.SeqScan, p.SeqScan)),
+			formatInt(seqTupRead),
+			formatInt(delta(e.IdxScan, p.IdxScan)),
+			formatInt(idxTupFetch),
+			formatInt(ins),
+			formatInt(upd),
+			formatInt(del),
+			blksRead,
+			hitRatio,
+		}})
+	}
+	sort.SliceStable(hottest, func(i, j int) bool { return hottest[i].activity > hottest[j].activity })
+	for i := 0; i < len(hottest) && i < b.top; i++ {
+		s.Rows = append(s.Rows, hottest[i].row)
+	}
+	return s
+}
+
+func (b *builder) indexes() Section {
+	s := Section{
+		Title:   "Hottest indexes",
+		Note:    "Indexes scanned most often within the interval",
+		Columns: []string{"Index", "Table", "Scans", "Tuples read", "Tuples fetched", "Blocks read", "Cache hit ratio"},
+	}
+	var begin, end pgstats.PgStatUserIndexesView
+	if ok, reason := b.load("pg_stat_user_indexes", &begin, &end); !ok {
+		s.Note = reason
+		return s
+	}
+	var ioBegin, ioEnd pgstats.PgStatIoUserIndexesView
+	io, _ := b.load("pg_statio_user_indexes", &ioBegin, &ioEnd)
+	ioPrevious := make(map[int64]pgstats.PgStatIoIndexesRow, len(ioBegin))
+	for _, p := range ioBegin {
+		ioPrevious[p.Indexrelid] = p
+	}
+	ioCurrent := make(map[int64]pgstats.PgStatIoIndexesRow, len(ioEnd))
+	for _, c := range ioEnd {
+		ioCurrent[c.Indexrelid] = c
+	}
+
+	previous := make(map[int64]pgstats.PgStatIndexesRow, len(begin))
+	for _, p := range begin {
+		previous[p.Indexrelid] = p
+	}
+	type hot struct {
+		scans int64
+		row   []string
+	}
+	hottest := make([]hot, 0)
+	for _, e := range end {
+		p, ok := previous[e.Indexrelid]
+		if !ok {
+			continue
+		}
+		scans := delta(e.IdxScan, p.IdxScan)
+		if scans == 0 {
+			continue
+		}
+		blksRead, hitRatio := "", ""
+		if io {
+			if c, ok := ioCurrent[e.Indexrelid]; ok {
+				if p, ok := ioPrevious[e.Indexrelid]; ok {
+					read := delta(c.IdxBlksRead, p.IdxBlksRead)
+					blksRead, hitRatio = formatInt(read), ratio(delta(c.IdxBlksHit, p.IdxBlksHit), read)
+				}
+			}
+		}
+		hottest = append(hottest, hot{scans, []string{
+			e.Schemaname + "." + e.Indexrelname,
+			e.Relname,
+			formatInt(scans),
+			formatInt(delta(e.IdxTupRead, p.IdxTupRead)),
+			formatInt(delta(e.IdxTupFetch, p.IdxTupFetch)),
+			blksRead,
+			hitRatio,
+		}})
+	}
+	sort.SliceStable(hottest, func(i, j int) bool { return hottest[i].scans > hottest[j].scans })
+	for i := 0; i < len(hottest) && i < b.top; i++ {
+		s.Rows = append(s.Rows, hottest[i].row)
+	}
+	return s
+}
+
+func (b *builder) checkpoints() Section {
+	s := Section{
+		Title:   "Checkpoints and background writer",
+		Note:    "Activity of the checkpointer and the background writer within the interval",
+		Columns: []string{"Statistic", "Total", "Per second"},
+	}
+	var begin, end pgstats.PgStatBgWriterView
+	if ok, reason := b.load("pg_stat_bgwriter", &begin, &end); !ok {
+		s.Note = reason
+		return s
+	}
+	counters := []struct {
+		name       string
+		begin, end nullable.Int64
+	}{
+		{"Scheduled checkpoints", begin.CheckpointsTimed, end.CheckpointsTimed},
+		{"Requested checkpoints", begin.CheckpointsReq, end.CheckpointsReq},
+		{"Buffers written by checkpoints", begin.BuffersCheckpoint, end.BuffersCheckpoint},
+		{"Buffers written by background writer", begin.BuffersClean, end.BuffersClean},
+		{"Background writer stops for writing too many buffers", begin.MaxWrittenClean, end.MaxWrittenClean},
+		{"Buffers written by backends", begin.BuffersBackend, end.BuffersBackend},
+		{"Fsync calls by backends", begin.BuffersBackendFsync, end.BuffersBackendFsync},
+		{"Buffers allocated", begin.BuffersAlloc, end.BuffersAlloc},
+	}
+	for _, c := range counters {
+		if !c.end.Valid {
+			continue
+		}
+		d := delta(c.end, c.begin)
+		s.Rows = append(s.Rows, []string{c.name, formatInt(d), b.rate(d)})
+	}
+	if end.CheckpointWriteTime.Valid {
+		s.Rows = append(s.Rows, []string{"Checkpoint write time (ms)", formatFloat(deltaFloat(end.CheckpointWriteTime, begin.CheckpointWriteTime)), ""})
+	}
+	if end.CheckpointSyncTime.Valid {
+		s.Rows = append(s.Rows, []string{"Checkpoint sync time (ms)", formatFloat(deltaFloat(end.CheckpointSyncTime, begin.CheckpointSyncTime)), ""})
+	}
+	return s
+}
+
+func (b *builder) waits() Section {
+	s := Section{
+		Title:   "Wait events",
+		Note:    "Backends waiting at the moments both snapshots were taken; for a full picture, use active session history",
+		Columns: []string{"Wait event type", "Wait event", "Backends waiting", "% of active backends"},
+	}
+	var begin, end pgstats.PgStatActivityView
+	if ok, reason := b.load("pg_stat_activity", &begin, &end); !ok {
+		s.Note = reason
+		return s
+	}
+	type event struct{ waitType, name string }
+	counts := make(map[event]int64)
+	var active int64
+	for _, a := range append(begin, end...) {
+		if a.State.String != "active" {
+			continue
+		}
+		active++
+		if a.WaitEventType.Valid {
+			counts[event{a.WaitEventType.String, a.WaitEvent.String}]++
+		} else {
+			counts[event{"CPU", "running"}]++
+		}
+	}
+	events := make([]event, 0, len(counts))
+	for e := range counts {
+		events = append(events, e)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if counts[events[i]] != counts[events[j]] {
+			return counts[events[i]] > counts[events[j]]
+		}
+		return events[i].waitType+events[i].name < events[j].waitType+events[j].name
+	})
+	for _, e := range events {
+		s.Rows = append(s.Rows, []string{e.waitType, e.name, formatInt(counts[e]), ratio(counts[e], active-counts[e])})
+	}
+	return s
+}
+
+func (b *builder) temp() Section {
+	s := Section{
+		Title:   "Temporary files",
+		Note:    "Temporary files created by queries exceeding work_mem within the interval",
+		Columns: []string{"Source", "Temp files", "Temp written", "Temp blocks read"},
+	}
+	var begin, end pgstats.PgStatDatabaseView
+	if ok, reason := b.load("pg_stat_database", &begin, &end); !ok {
+		s.Note = reason
+		return s
+	}
+	previous := make(map[int64]pgstats.PgStatDatabaseRow, len(begin))
+	for _, p := range begin {
+		previous[p.Datid] = p
+	}
+	for _, e := range end {
+		p, ok := previous[e.Datid]
+		if !ok {
+			continue
+		}
+		if files := delta(e.TempFiles, p.TempFiles); files > 0 {
+			s.Rows = append(s.Rows, []string{"database " + e.Datname, formatInt(files), formatBytes(delta(e.TempBytes, p.TempBytes)), ""})
+		}
+	}
+
+	d, _ := b.statements()
+	if d == nil {
+		return s
+	}
+	blockSize := b.end.BlockSize
+	if blockSize == 0 {
+		blockSize = 8192
+		s.Note += " (block size not recorded in the snapshot, 8 kB assumed)"
+	}
+	rows := append([]pgstats.PgStatStatementsDeltaRow(nil), d.Rows...)
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].TempBlksWritten > rows[j].TempBlksWritten })
+	for i := 0; i < len(rows) && i < b.top && rows[i].TempBlksWritten > 0; i++ {
+		s.Rows = append(s.Rows, []string{shorten(rows[i].Query), "", formatBytes(rows[i].TempBlksWritten * blockSize), formatInt(rows[i].TempBlksRead)})
+	}
+	return s
+}
+
+func (b *builder) rate(n int64) string {
+	return formatFloat(float64(n) / b.seconds)
+}
+
+// delta returns the increase of a counter; if the counter went down, it has been reset in the meantime,
+// so its current value is the increase since the reset
+func delta(current nullable.Int64, previous nullable.Int64) int64 {
+	if current.Int64 < previous.Int64 {
+		return current.Int64
+	}
+	return current.Int64 - previous.Int64
+}
+
+func deltaFloat(current nullable.Float64, previous nullable.Float64) float64 {
+	if current.Float64 < previous.Float64 {
+		return current.Float64
+	}
+	return current.Float64 - previous.Float64
+}
+
+func ratio(hit int64, miss int64) string {
+	if hit+miss == 0 {
+		return ""
+	}
+	return percent(float64(hit) / float64(hit+miss))
+}
+
+func percent(share float64) string {
+	return strconv.FormatFloat(share*100, 'f', 1, 64) + "%"
+}
+
+func formatInt(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+func formatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+// shorten puts the query in a single line of limited length
+func shorten(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if r := []rune(query); len(r) > queryLength {
+		return string(r[:queryLength-1]) + "…"
+	}
+	return query
+}
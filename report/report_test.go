@@ -0,0 +1,155 @@
+package report
+
+import (
+	"bytes"
+	"database/sql"
+	"github.com/vynaloze/pgstats"
+	"github.com/vynaloze/pgstats/history"
+	"github.com/vynaloze/pgstats/nullable"
+	"strings"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func int64Of(v int64) nullable.Int64 {
+	return nullable.Int64{NullInt64: sql.NullInt64{Int64: v, Valid: true}}
+}
+
+func stringOf(v string) nullable.String {
+	return nullable.String{NullString: sql.NullString{String: v, Valid: true}}
+}
+
+func snapshot(t *testing.T, id int64, minutes int, scale int64) *history.Snapshot {
+	snap := &history.Snapshot{ID: id, Time: start.Add(time.Duration(minutes) * time.Minute), Database: "app", BlockSize: 4096}
+	views := map[string]interface{}{
+		"pg_stat_database": pgstats.PgStatDatabaseView{
+			{Datid: 1, Datname: "app", XactCommit: int64Of(600 * scale), XactRollback: int64Of(scale),
+				BlksRead: int64Of(10 * scale), BlksHit: int64Of(90 * scale), TempFiles: int64Of(2 * scale), TempBytes: int64Of(1 << 20 * scale)},
+			{Datid: 2, Datname: "idle", XactCommit: int64Of(5)},
+		},
+		"pg_stat_bgwriter": pgstats.PgStatBgWriterView{CheckpointsTimed: int64Of(scale), BuffersAlloc: int64Of(1000 * scale)},
+		"pg_stat_statements": pgstats.PgStatStatementsView{
+			{Userid: 10, Dbid: 1, Queryid: 1, Query: "select *\n  from orders where id = $1", Calls: 1000 * scale, TotalTime: 100 * float64(scale)},
+			{Userid: 10, Dbid: 1, Queryid: 2, Query: "select a | b from report", Calls: scale, TotalTime: 500 * float64(scale),
+				SharedBlksRead: 100 * scale, TempBlksWritten: 16 * scale},
+		},
+		"pg_stat_user_tables": pgstats.PgStatUserTablesView{
+			{Relid: 100, Schemaname: "public", Relname: "orders", SeqScan: int64Of(scale), SeqTupRead: int64Of(50 * scale), NTupIns: int64Of(scale)},
+			{Relid: 101, Schemaname: "public", Relname: "unused", SeqScan: int64Of(1)},
+		},
+		"pg_stat_activity": pgstats.PgStatActivityView{
+			{Pid: 1, State: stringOf("active"), WaitEventType: stringOf("IO"), WaitEvent: stringOf("DataFileRead")},
+			{Pid: 2, State: stringOf("active")},
+			{Pid: 3, State: stringOf("idle"), WaitEventType: stringOf("Client"), WaitEvent: stringOf("ClientRead")},
+		},
+	}
+	for name, v := range views {
+		if err := snap.Set(name, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snap.Errors = map[string]string{"pg_stat_user_indexes": "permission denied"}
+	return snap
+}
+
+func section(t *testing.T, r *Report, title string) Section {
+	for _, s := range r.Sections {
+		if s.Title == title {
+			return s
+		}
+	}
+	t.Fatalf("Section %s not found", title)
+	return Section{}
+}
+
+func TestNew(t *testing.T) {
+	r, err := New(snapshot(t, 1, 0, 1), snapshot(t, 2, 10, 2), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Duration() != 10*time.Minute {
+		t.Errorf("Expected 10 minutes; actual %v", r.Duration())
+	}
+
+	throughput := section(t, r, "Database throughput")
+	if len(throughput.Rows) != 1 {
+		t.Fatalf("Expected only the active database; actual %v", throughput.Rows)
+	}
+	if row := throughput.Rows[0]; row[0] != "app" || row[1] != "600" || row[2] != "1.00" || row[3] != "1" || row[9] != "90.0%" {
+		t.Errorf("Unexpected throughput: %v", row)
+	}
+
+	byTime := section(t, r, "Top SQL by time")
+	if len(byTime.Rows) != 2 || byTime.Rows[0][0] != "select a | b from report" || byTime.Rows[1][0] != "select * from orders where id = $1" {
+		t.Errorf("Unexpected top SQL by time: %v", byTime.Rows)
+	}
+	if byIO := section(t, r, "Top SQL by I/O"); len(byIO.Rows) != 1 || byIO.Rows[0][1] != "100" {
+		t.Errorf("Expected only statements reading blocks; actual %v", byIO.Rows)
+	}
+
+	if tables := section(t, r, "Hottest tables"); len(tables.Rows) != 1 || tables.Rows[0][0] != "public.orders" || tables.Rows[0][2] != "50" {
+		t.Errorf("Unexpected hottest tables: %v", tables.Rows)
+	}
+	if indexes := section(t, r, "Hottest indexes"); len(indexes.Rows) != 0 || !strings.Contains(indexes.Note, "permission denied") {
+		t.Errorf("Expected indexes not available; actual %+v", indexes)
+	}
+	if waits := section(t, r, "Wait events"); len(waits.Rows) != 2 || waits.Rows[0][2] != "2" || waits.Rows[0][3] != "50.0%" {
+		t.Errorf("Unexpected wait events: %v", waits.Rows)
+	}
+	temp := section(t, r, "Temporary files")
+	if len(temp.Rows) != 2 || temp.Rows[0][2] != "1.0 MiB" || temp.Rows[1][2] != "64.0 KiB" {
+		t.Errorf("Unexpected temporary files: %v", temp.Rows)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	if _, err := New(snapshot(t, 2, 10, 2), snapshot(t, 1, 0, 1), 0); err == nil {
+		t.Error("Expected error for snapshots in wrong order")
+	}
+	other := snapshot(t, 2, 10, 2)
+	other.Database = "other"
+	if _, err := New(snapshot(t, 1, 0, 1), other, 0); err == nil {
+		t.Error("Expected error for snapshots of different databases")
+	}
+}
+
+func TestRender(t *testing.T) {
+	r, err := New(snapshot(t, 1, 0, 1), snapshot(t, 2, 10, 2), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var md bytes.Buffer
+	if err := r.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"# Workload report: app\n",
+		"- Duration: 10m0s\n",
+		"## Top SQL by time\n\nStatements which took most time to execute\n\n| Query | Total time (ms) |",
+		"| select a \\| b from report | 500.00 | 83.3% | 1 | 500.00 | 0 |\n",
+		"| select \\* from orders where id = $1 |",
+		"## Hottest indexes\n\nNot available: pg_stat_user_indexes in snapshot 1: permission denied\n\nNo data.\n",
+	} {
+		if !strings.Contains(md.String(), expected) {
+			t.Errorf("Expected %q in:\n%s", expected, md.String())
+		}
+	}
+
+	if actual := markdownEscaper.Replace("<b>a_b</b> & `c`"); actual != "&lt;b&gt;a\\_b&lt;/b&gt; &amp; \\`c\\`" {
+		t.Errorf("Expected markup escaped; actual %s", actual)
+	}
+
+	var html bytes.Buffer
+	if err := r.WriteHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html.String(), "<td>select * from orders where id = $1</td>") &&
+		!strings.Contains(html.String(), "<td>select a | b from report</td>") {
+		t.Errorf("Expected statements in HTML:\n%s", html.String())
+	}
+	if !strings.Contains(html.String(), "<h2>Checkpoints and background writer</h2>") {
+		t.Errorf("Expected sections in HTML:\n%s", html.String())
+	}
+}
@@ -0,0 +1,157 @@
+package pgstats
+
+import (
+	"github.com/pkg/errors"
+	"github.com/vynaloze/pgstats/nullable"
+	"math"
+	"sort"
+)
+
+// SequenceUsageView represents usage of all sequences in the current database, ordered by PercentUsed (highest first)
+type SequenceUsageView []SequenceUsageRow
+
+// SequenceUsageRow represents usage of a single sequence
+type SequenceUsageRow struct {
+	// Name of the schema containing the sequence
+	Schemaname string `json:"schemaname"`
+	// Name of the sequence
+	Sequencename string `json:"sequencename"`
+	// Data type of the sequence
+	DataType string `json:"data_type"`
+	// Start value of the sequence
+	StartValue int64 `json:"start_value"`
+	// Minimum value of the sequence
+	MinValue int64 `json:"min_value"`
+	// Maximum value of the sequence
+	MaxValue int64 `json:"max_value"`
+	// Increment value of the sequence
+	IncrementBy int64 `json:"increment_by"`
+	// Whether the sequence cycles
+	Cycle bool `json:"cycle"`
+	// The last sequence value written to disk. Null if the sequence has not been read from yet,
+	// or if the current user does not have USAGE or SELECT privilege on the sequence.
+	LastValue nullable.Int64 `json:"last_value"`
+	// Name of the table owning the sequence (serial or identity column), if any
+	Relname nullable.String `json:"relname"`
+	// Name of the column owning the sequence (serial or identity column), if any
+	Attname nullable.String `json:"attname"`
+	// Data type of the column owning the sequence, if any
+	ColumnType nullable.String `json:"column_type"`
+	// True if the sequence can generate values which do not fit into the column owning it,
+	// e.g. bigint sequence for an integer column
+	TypeMismatch bool `json:"type_mismatch"`
+	// The last value the sequence can generate before it fails (or cycles),
+	// taking the range of the owning column type into account: the maximum value for ascending sequences,
+	// the minimum value for descending ones
+	Limit int64 `json:"limit"`
+	// Percentage of the range of the sequence already used: the distance of LastValue from MinValue
+	// (MaxValue for descending sequences), as a fraction of the distance of Limit from it.
+	// StartValue is not taken into account, as a cycling sequence restarts from MinValue (or MaxValue)
+	PercentUsed float64 `json:"percent_used"`
+}
+
+// NearingExhaustion returns only the sequences which have used at least given percentage of their range
+func (v SequenceUsageView) NearingExhaustion(percent float64) SequenceUsageView {
+	res := make(SequenceUsageView, 0)
+	for _, row := range v {
+		if row.PercentUsed >= percent {
+			res = append(res, row)
+		}
+	}
+	return res
+}
+
+func (s *PgStats) fetchSequenceUsage() (SequenceUsageView, error) {
+	version, err := s.getPgVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version < 10 {
+		return nil, errors.Errorf("Unsupported PostgreSQL version: %f", version)
+	}
+
+	db := s.db()
+	query := "select s.schemaname,s.sequencename,s.data_type::text,s.start_value,s.min_value,s.max_value," +
+		"s.increment_by,s.cycle,s.last_value,t.relname,a.attname,ty.typname " +
+		"from pg_sequences s join pg_namespace n on n.nspname=s.schemaname " +
+		"join pg_class c on c.relnamespace=n.oid and c.relname=s.sequencename " +
+		"left join pg_depend d on d.classid='pg_class'::regclass and d.objid=c.oid " +
+		"and d.refclassid='pg_class'::regclass and d.refobjsubid>0 and d.deptype in ('a','i') " +
+		"left join pg_class t on t.oid=d.refobjid " +
+		"left join pg_attribute a on a.attrelid=d.refobjid and a.attnum=d.refobjsubid " +
+		"left join pg_type ty on ty.oid=a.atttypid"
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make(SequenceUsageView, 0)
+	for rows.Next() {
+		row := new(SequenceUsageRow)
+		err := rows.Scan(&row.Schemaname, &row.Sequencename, &row.DataType, &row.StartValue, &row.MinValue, &row.MaxValue,
+			&row.IncrementBy, &row.Cycle, &row.LastValue, &row.Relname, &row.Attname, &row.ColumnType)
+		if err != nil {
+			return nil, err
+		}
+		computeSequenceUsage(row)
+		data = append(data, *row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(data, func(i, j int) bool {
+		return data[i].PercentUsed > data[j].PercentUsed
+	})
+	return data, nil
+}
+
+// computeSequenceUsage fills TypeMismatch, Limit and PercentUsed of the row
+func computeSequenceUsage(row *SequenceUsageRow) {
+	row.Limit = row.MaxValue
+	if row.IncrementBy < 0 {
+		row.Limit = row.MinValue
+	}
+	if row.ColumnType.Valid {
+		if min, max, ok := integerTypeRange(row.ColumnType.String); ok {
+			row.TypeMismatch = row.MaxValue > max || row.MinValue < min
+			if row.IncrementBy > 0 && max < row.Limit {
+				row.Limit = max
+			}
+			if row.IncrementBy < 0 && min > row.Limit {
+				row.Limit = min
+			}
+		}
+	}
+
+	row.PercentUsed = 0
+	if !row.LastValue.Valid {
+		return
+	}
+	// float64 avoids overflow when the range spans the whole bigint type
+	first := float64(row.MinValue)
+	if row.IncrementBy < 0 {
+		first = float64(row.MaxValue)
+	}
+	total := math.Abs(float64(row.Limit) - first)
+	if total == 0 {
+		row.PercentUsed = 100
+		return
+	}
+	row.PercentUsed = math.Abs(float64(row.LastValue.Int64)-first) / total * 100
+}
+
+// integerTypeRange returns the range of values of given integer type
+func integerTypeRange(typname string) (int64, int64, bool) {
+	switch typname {
+	case "int2":
+		return math.MinInt16, math.MaxInt16, true
+	case "int4":
+		return math.MinInt32, math.MaxInt32, true
+	case "int8":
+		return math.MinInt64, math.MaxInt64, true
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,52 @@
+package pgstats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSequenceUsage(t *testing.T) {
+	serial := sequence(1, 1, math.MaxInt32, 1, 1073741824, "int4")
+	bigserialOnInt := sequence(1, 1, math.MaxInt64, 1, 2147483000, "int4")
+	descending := sequence(-100, math.MinInt16, -1, -1, -100, "int2")
+	unused := sequence(1, 1, math.MaxInt64, 1, 0, "")
+	unused.LastValue.Valid = false
+	standalone := sequence(1, 1, 100, 1, 95, "")
+	standalone.ColumnType.Valid = false
+	restarted := sequence(1000, 1, 2000, 1, 1500, "int4")
+
+	for _, row := range []*SequenceUsageRow{&serial, &bigserialOnInt, &descending, &unused, &standalone, &restarted} {
+		computeSequenceUsage(row)
+	}
+
+	if serial.TypeMismatch || serial.Limit != math.MaxInt32 || math.Abs(serial.PercentUsed-50) > 0.001 {
+		t.Errorf("Unexpected usage of serial sequence: %+v", serial)
+	}
+	if !bigserialOnInt.TypeMismatch || bigserialOnInt.Limit != math.MaxInt32 || bigserialOnInt.PercentUsed < 99.99 {
+		t.Errorf("Unexpected usage of bigint sequence on integer column: %+v", bigserialOnInt)
+	}
+	if descending.Limit != math.MinInt16 || math.Abs(descending.PercentUsed-99.0/32767*100) > 0.001 {
+		t.Errorf("Unexpected usage of descending sequence: %+v", descending)
+	}
+	if unused.PercentUsed != 0 {
+		t.Errorf("Expected unused sequence to have 0 percent used; actual %f", unused.PercentUsed)
+	}
+	if standalone.TypeMismatch || standalone.Limit != 100 || math.Abs(standalone.PercentUsed-94.0/99*100) > 0.001 {
+		t.Errorf("Unexpected usage of standalone sequence: %+v", standalone)
+	}
+	if restarted.Limit != 2000 || math.Abs(restarted.PercentUsed-1499.0/1999*100) > 0.001 {
+		t.Errorf("Expected usage of sequence starting above its minimum measured from the minimum; actual %+v", restarted)
+	}
+
+	view := SequenceUsageView{serial, bigserialOnInt, descending, unused, standalone}
+	if n := len(view.NearingExhaustion(90)); n != 2 {
+		t.Errorf("Expected 2 sequences nearing exhaustion; actual %d", n)
+	}
+}
+
+func sequence(start int64, min int64, max int64, increment int64, last int64, columnType string) SequenceUsageRow {
+	row := SequenceUsageRow{StartValue: start, MinValue: min, MaxValue: max, IncrementBy: increment}
+	row.LastValue.Valid, row.LastValue.Int64 = true, last
+	row.ColumnType.Valid, row.ColumnType.String = columnType != "", columnType
+	return row
+}
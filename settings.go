@@ -0,0 +1,28 @@
+package pgstats
+
+func (s *PgStats) fetchSettingInt(name string) (int64, error) {
+	db := s.db()
+	query := "select current_setting($1)::bigint"
+	row := db.QueryRow(query, name)
+	var value int64
+	err := row.Scan(&value)
+	return value, err
+}
+
+func (s *PgStats) fetchSettingFloat(name string) (float64, error) {
+	db := s.db()
+	query := "select current_setting($1)::float8"
+	row := db.QueryRow(query, name)
+	var value float64
+	err := row.Scan(&value)
+	return value, err
+}
+
+func (s *PgStats) fetchSettingBool(name string) (bool, error) {
+	db := s.db()
+	query := "select current_setting($1)::bool"
+	row := db.QueryRow(query, name)
+	var value bool
+	err := row.Scan(&value)
+	return value, err
+}
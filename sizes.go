@@ -0,0 +1,166 @@
+package pgstats
+
+import (
+	"github.com/lib/pq"
+	"github.com/vynaloze/pgstats/nullable"
+	"time"
+)
+
+// DatabaseSizeView represents sizes of all databases in the cluster
+type DatabaseSizeView []DatabaseSizeRow
+
+// DatabaseSizeRow represents size of a single database
+type DatabaseSizeRow struct {
+	// OID of a database
+	Datid int64 `json:"datid"`
+	// Name of this database
+	Datname string `json:"datname"`
+	// Disk space used by this database, in bytes.
+	// Null if the user is not allowed to connect to this database.
+	Size nullable.Int64 `json:"size"`
+	// Server time at which the size was measured
+	Time time.Time `json:"time"`
+}
+
+// DatabaseGrowthView represents change in sizes of databases between two snapshots
+type DatabaseGrowthView []DatabaseGrowthRow
+
+// DatabaseGrowthRow represents change in size of a single database between two snapshots
+type DatabaseGrowthRow struct {
+	// OID of a database
+	Datid int64 `json:"datid"`
+	// Name of this database
+	Datname string `json:"datname"`
+	// Disk space used by this database at the time of the later snapshot, in bytes
+	Size int64 `json:"size"`
+	// Change in disk space used by this database between the snapshots, in bytes
+	Growth int64 `json:"growth"`
+	// Average growth rate between the snapshots, in bytes per second
+	Rate float64 `json:"rate"`
+}
+
+// Growth computes the change in sizes of databases since the earlier snapshot prev.
+// Databases whose size is unknown in any of the snapshots are omitted.
+func (v DatabaseSizeView) Growth(prev DatabaseSizeView) DatabaseGrowthView {
+	previous := make(map[int64]DatabaseSizeRow, len(prev))
+	for _, p := range prev {
+		previous[p.Datid] = p
+	}
+	res := make(DatabaseGrowthView, 0)
+	for _, c := range v {
+		p, ok := previous[c.Datid]
+		if !ok || !p.Size.Valid || !c.Size.Valid {
+			continue
+		}
+		row := DatabaseGrowthRow{
+			Datid:   c.Datid,
+			Datname: c.Datname,
+			Size:    c.Size.Int64,
+			Growth:  c.Size.Int64 - p.Size.Int64,
+		}
+		if elapsed := c.Time.Sub(p.Time).Seconds(); elapsed > 0 {
+			row.Rate = float64(row.Growth) / elapsed
+		}
+		res = append(res, row)
+	}
+	return res
+}
+
+func (s *PgStats) fetchDatabaseSizes() (DatabaseSizeView, error) {
+	db := s.db()
+	query := "select oid,datname," +
+		"case when has_database_privilege(oid,'CONNECT') then pg_database_size(oid) end,now() " +
+		"from pg_database where not datistemplate"
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make(DatabaseSizeView, 0)
+	for rows.Next() {
+		row := new(DatabaseSizeRow)
+		err := rows.Scan(&row.Datid, &row.Datname, &row.Size, &row.Time)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, *row)
+	}
+	return data, rows.Err()
+}
+
+func (s *PgStats) joinTableSizes(tables []PgStatTablesRow) error {
+	relids := make([]int64, len(tables))
+	for i, t := range tables {
+		relids[i] = t.Relid
+	}
+
+	db := s.db()
+	query := "select oid,pg_relation_size(oid),coalesce(pg_total_relation_size(nullif(reltoastrelid,0)),0)," +
+		"pg_indexes_size(oid),pg_total_relation_size(oid) from pg_class where oid=any($1::oid[])"
+
+	rows, err := db.Query(query, pq.Array(relids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	sizes := make(map[int64]PgStatTablesRow, len(tables))
+	for rows.Next() {
+		var relid int64
+		row := new(PgStatTablesRow)
+		err := rows.Scan(&relid, &row.HeapSize, &row.ToastSize, &row.IndexesSize, &row.TotalSize)
+		if err != nil {
+			return err
+		}
+		sizes[relid] = *row
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i := range tables {
+		if size, ok := sizes[tables[i].Relid]; ok {
+			tables[i].HeapSize = size.HeapSize
+			tables[i].ToastSize = size.ToastSize
+			tables[i].IndexesSize = size.IndexesSize
+			tables[i].TotalSize = size.TotalSize
+		}
+	}
+	return nil
+}
+
+func (s *PgStats) joinIndexSizes(indexes []PgStatIndexesRow) error {
+	relids := make([]int64, len(indexes))
+	for i, idx := range indexes {
+		relids[i] = idx.Indexrelid
+	}
+
+	db := s.db()
+	query := "select oid,pg_relation_size(oid) from pg_class where oid=any($1::oid[])"
+
+	rows, err := db.Query(query, pq.Array(relids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	sizes := make(map[int64]nullable.Int64, len(indexes))
+	for rows.Next() {
+		var relid int64
+		var size nullable.Int64
+		if err := rows.Scan(&relid, &size); err != nil {
+			return err
+		}
+		sizes[relid] = size
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i := range indexes {
+		if size, ok := sizes[indexes[i].Indexrelid]; ok {
+			indexes[i].Size = size
+		}
+	}
+	return nil
+}
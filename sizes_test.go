@@ -0,0 +1,38 @@
+package pgstats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDatabaseGrowth(t *testing.T) {
+	start := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	prev := DatabaseSizeView{
+		sizeAt(1, "foo", 1000, start),
+		sizeAt(2, "bar", 5000, start),
+		{Datid: 3, Datname: "forbidden", Time: start},
+	}
+	cur := DatabaseSizeView{
+		sizeAt(1, "foo", 3000, start.Add(10*time.Second)),
+		sizeAt(2, "bar", 4000, start.Add(10*time.Second)),
+		{Datid: 3, Datname: "forbidden", Time: start.Add(10 * time.Second)},
+		sizeAt(4, "new", 100, start.Add(10*time.Second)),
+	}
+
+	g := cur.Growth(prev)
+	if len(g) != 2 {
+		t.Fatalf("Expected 2 rows; actual %+v", g)
+	}
+	if g[0].Datname != "foo" || g[0].Size != 3000 || g[0].Growth != 2000 || g[0].Rate != 200 {
+		t.Errorf("Unexpected growth of foo: %+v", g[0])
+	}
+	if g[1].Datname != "bar" || g[1].Growth != -1000 || g[1].Rate != -100 {
+		t.Errorf("Unexpected growth of bar: %+v", g[1])
+	}
+}
+
+func sizeAt(datid int64, datname string, size int64, at time.Time) DatabaseSizeRow {
+	row := DatabaseSizeRow{Datid: datid, Datname: datname, Time: at}
+	row.Size.Valid, row.Size.Int64 = true, size
+	return row
+}
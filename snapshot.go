@@ -0,0 +1,100 @@
+package pgstats
+
+import (
+	"database/sql"
+	"github.com/pkg/errors"
+	"github.com/vynaloze/pgstats/nullable"
+	"time"
+)
+
+// SnapshotView holds views fetched together in a single transaction
+type SnapshotView struct {
+	// Start time of the transaction the views have been fetched in, as returned by now() on the server
+	Time time.Time `json:"time"`
+	// Time at which the cumulative statistics have been cached for the transaction,
+	// as returned by pg_stat_get_snapshot_timestamp(). Null if none of the views read cumulative statistics.
+	StatsTime nullable.Time `json:"stats_time"`
+	// Size of a disk block of the server (block_size), in bytes, e.g. to convert block counts of the views to bytes
+	BlockSize int64 `json:"block_size"`
+	// Views by name, as returned by Fetch
+	Views map[string]interface{} `json:"views"`
+	// Errors of views which could not be fetched, by name
+	Errors map[string]error `json:"-"`
+}
+
+func (s *PgStats) takeSnapshot(names []string) (*SnapshotView, error) {
+	if len(names) == 0 {
+		names = viewNames()
+	}
+	for _, name := range names {
+		if _, ok := views[name]; !ok {
+			return nil, errors.Errorf("Unknown view: %s", name)
+		}
+	}
+	version, err := s.getPgVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.conn.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// a no-op once the transaction is committed
+	defer tx.Rollback()
+	// repeatable read keeps catalogs joined with statistics consistent between the views
+	if _, err := tx.Exec("set transaction isolation level repeatable read read only"); err != nil {
+		return nil, err
+	}
+	if version >= 15 {
+		// before PostgreSQL 15, statistics are always cached for the whole transaction
+		if _, err := tx.Exec("set local stats_fetch_consistency = snapshot"); err != nil {
+			return nil, err
+		}
+	}
+	snap := &SnapshotView{Views: make(map[string]interface{}, len(names)), Errors: make(map[string]error)}
+	if err := tx.QueryRow("select now(),current_setting('block_size')::bigint").Scan(&snap.Time, &snap.BlockSize); err != nil {
+		return nil, err
+	}
+
+	t := &PgStats{conn: s.conn, tx: tx}
+	for _, name := range names {
+		v, err := t.fetchInSavepoint(tx, name)
+		if err != nil {
+			if _, ok := err.(savepointError); ok {
+				return nil, err
+			}
+			snap.Errors[name] = err
+			continue
+		}
+		snap.Views[name] = v
+	}
+
+	if err := tx.QueryRow("select pg_stat_get_snapshot_timestamp()").Scan(&snap.StatsTime); err != nil {
+		return nil, err
+	}
+	return snap, tx.Commit()
+}
+
+// savepointError is returned if the savepoint itself failed, so that the whole snapshot has to be abandoned
+type savepointError struct {
+	error
+}
+
+// fetchInSavepoint fetches the view within a savepoint, so that failure of a single view does not abort the transaction
+func (s *PgStats) fetchInSavepoint(tx *sql.Tx, name string) (interface{}, error) {
+	if _, err := tx.Exec("savepoint pgstats_view"); err != nil {
+		return nil, savepointError{err}
+	}
+	v, err := s.fetchView(name)
+	if err != nil {
+		if _, rollbackErr := tx.Exec("rollback to savepoint pgstats_view"); rollbackErr != nil {
+			return nil, savepointError{rollbackErr}
+		}
+		return nil, err
+	}
+	if _, err := tx.Exec("release savepoint pgstats_view"); err != nil {
+		return nil, savepointError{err}
+	}
+	return v, nil
+}
@@ -10,169 +10,83 @@ type PgStatActivityView []PgStatActivityRow
 // PgStatActivityRow represents schema of pg_stat_activity view
 type PgStatActivityRow struct {
 	// OID of the database this backend is connected to
-	Datid nullable.Int64 `json:"datid"`
+	Datid nullable.Int64 `json:"datid" pg:"datid"`
 	// Name of the database this backend is connected to
-	Datname nullable.String `json:"datname"`
+	Datname nullable.String `json:"datname" pg:"datname"`
 	// Process ID of this backend
-	Pid int64 `json:"pid"`
+	Pid int64 `json:"pid" pg:"pid"`
 	// OID of the user logged into this backend
-	Usesysid nullable.Int64 `json:"usesysid"`
+	Usesysid nullable.Int64 `json:"usesysid" pg:"usesysid"`
 	// Name of the user logged into this backend
-	Usename nullable.String `json:"usename"`
+	Usename nullable.String `json:"usename" pg:"usename"`
 	// Name of the application that is connected to this backend
-	ApplicationName nullable.String `json:"application_name"`
+	ApplicationName nullable.String `json:"application_name" pg:"application_name"`
 	// IP address of the client connected to this backend.
 	// If this field is null, it indicates either that the client is connected via a Unix socket on the server machine
 	// or that this is an internal process such as autovacuum.
-	ClientAddr nullable.String `json:"client_addr"`
+	ClientAddr nullable.String `json:"client_addr" pg:"client_addr"`
 	// Host name of the connected client, as reported by a reverse DNS lookup of client_addr.
 	// This field will only be non-null for IP connections, and only when log_hostname is enabled.
-	ClientHostname nullable.String `json:"client_hostname"`
+	ClientHostname nullable.String `json:"client_hostname" pg:"client_hostname"`
 	// TCP port number that the client is using for communication with this backend,
 	// or -1 if a Unix socket is used
-	ClientPort nullable.Int64 `json:"client_port"`
+	ClientPort nullable.Int64 `json:"client_port" pg:"client_port"`
 	// Time when this process was started.
 	// For client backends, this is the time the client connected to the server.
-	BackendStart nullable.Time `json:"backend_start"`
+	BackendStart nullable.Time `json:"backend_start" pg:"backend_start"`
 	// Time when this process' current transaction was started,
 	// or null if no transaction is active.
 	// If the current query is the first of its transaction, this column is equal to the query_start column.
-	XactStart nullable.Time `json:"xact_start"`
+	XactStart nullable.Time `json:"xact_start" pg:"xact_start"`
 	// Time when the currently active query was started,
 	// or if state is not active, when the last query was started
-	QueryStart nullable.Time `json:"query_start"`
+	QueryStart nullable.Time `json:"query_start" pg:"query_start"`
 	// ime when the state was last changed
-	StateChange nullable.Time `json:"state_change"`
+	StateChange nullable.Time `json:"state_change" pg:"state_change"`
 	// The type of event for which the backend is waiting, if any; otherwise NULL.
 	// Supported since PostgreSQL 9.6.
 	// For possible values, see:
 	// https://www.postgresql.org/docs/current/monitoring-stats.html#PG-STAT-ACTIVITY-VIEW
-	WaitEventType nullable.String `json:"wait_event_type"`
+	WaitEventType nullable.String `json:"wait_event_type" pg:"wait_event_type,min=9.6"`
 	// Wait event name if backend is currently waiting, otherwise NULL.
 	// Supported since PostgreSQL 9.6.
 	// For details, see:
 	// https://www.postgresql.org/docs/current/monitoring-stats.html#WAIT-EVENT-TABLE
-	WaitEvent nullable.String `json:"wait_event"`
+	WaitEvent nullable.String `json:"wait_event" pg:"wait_event,min=9.6"`
 	// True if this backend is currently waiting on a lock.
 	// Supported until PostgreSQL 9.5 (inclusive).
-	Waiting nullable.Bool `json:"waiting"`
+	Waiting nullable.Bool `json:"waiting" pg:"waiting,max=9.5"`
 	// Current overall state of this backend.
 	// For possible values, see:
 	// https://www.postgresql.org/docs/current/monitoring-stats.html#PG-STAT-ACTIVITY-VIEW
-	State nullable.String `json:"state"`
+	State nullable.String `json:"state" pg:"state"`
 	// Top-level transaction identifier of this backend, if any.
-	BackendXid nullable.Int64 `json:"backend_xid"`
+	BackendXid nullable.Int64 `json:"backend_xid" pg:"backend_xid"`
 	// The current backend's xmin horizon.
-	BackendXmin nullable.Int64 `json:"backend_xmin"`
+	BackendXmin nullable.Int64 `json:"backend_xmin" pg:"backend_xmin"`
 	// Text of this backend's most recent query.
 	// If state is active this field shows the currently executing query.
 	// In all other states, it shows the last query that was executed.
 	// By default the query text is truncated at 1024 characters;
 	// this value can be changed via the parameter track_activity_query_size.
-	Query nullable.String `json:"query"`
+	Query nullable.String `json:"query" pg:"query"`
 	// Type of current backend.
 	// Possible types are autovacuum launcher, autovacuum worker, logical replication launcher,
 	// logical replication worker, parallel worker, background writer, client backend, checkpointer,
 	// startup, walreceiver, walsender and walwriter.
 	// In addition, background workers registered by extensions may have additional types.
 	// Supported since PostgreSQL 10
-	BackendType nullable.String `json:"backend_type"`
+	BackendType nullable.String `json:"backend_type" pg:"backend_type,min=10"`
+	// Identifier of this backend's most recent query.
+	// Available only if compute_query_id is enabled or a third-party module that computes query identifiers is configured.
+	// Supported since PostgreSQL 14
+	QueryId nullable.Int64 `json:"query_id" pg:"query_id,min=14"`
 }
 
 func (s *PgStats) fetchActivity() ([]PgStatActivityRow, error) {
-	version, err := s.getPgVersion()
-	if err != nil {
-		return nil, err
-	}
-	if version > 9.6 {
-		return s.fetchActivity10()
-	}
-	if version == 9.6 {
-		return s.fetchActivity96()
-	}
-	return s.fetchActivity95()
-}
-
-func (s *PgStats) fetchActivity10() ([]PgStatActivityRow, error) {
-	db := s.conn.db
-	query := "select datid,datname,pid,usesysid,usename," +
-		"application_name,client_addr,client_hostname,client_port,backend_start," +
-		"xact_start,query_start,state_change,wait_event_type,wait_event," +
-		"state,backend_xid,backend_xmin,query,backend_type from pg_stat_activity"
-
-	rows, err := db.Query(query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
 	data := make([]PgStatActivityRow, 0)
-	for rows.Next() {
-		row := new(PgStatActivityRow)
-		err := rows.Scan(&row.Datid, &row.Datname, &row.Pid, &row.Usesysid, &row.Usename,
-			&row.ApplicationName, &row.ClientAddr, &row.ClientHostname, &row.ClientPort, &row.BackendStart,
-			&row.XactStart, &row.QueryStart, &row.StateChange, &row.WaitEventType, &row.WaitEvent,
-			&row.State, &row.BackendXid, &row.BackendXmin, &row.Query, &row.BackendType)
-		if err != nil {
-			return nil, err
-		}
-		data = append(data, *row)
-	}
-	return data, rows.Err()
-}
-
-func (s *PgStats) fetchActivity96() ([]PgStatActivityRow, error) {
-	db := s.conn.db
-	query := "select datid,datname,pid,usesysid,usename," +
-		"application_name,client_addr,client_hostname,client_port,backend_start," +
-		"xact_start,query_start,state_change,wait_event_type,wait_event," +
-		"state,backend_xid,backend_xmin,query from pg_stat_activity"
-
-	rows, err := db.Query(query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	data := make([]PgStatActivityRow, 0)
-	for rows.Next() {
-		row := new(PgStatActivityRow)
-		err := rows.Scan(&row.Datid, &row.Datname, &row.Pid, &row.Usesysid, &row.Usename,
-			&row.ApplicationName, &row.ClientAddr, &row.ClientHostname, &row.ClientPort, &row.BackendStart,
-			&row.XactStart, &row.QueryStart, &row.StateChange, &row.WaitEventType, &row.WaitEvent,
-			&row.State, &row.BackendXid, &row.BackendXmin, &row.Query)
-		if err != nil {
-			return nil, err
-		}
-		data = append(data, *row)
-	}
-	return data, rows.Err()
-}
-
-func (s *PgStats) fetchActivity95() ([]PgStatActivityRow, error) {
-	db := s.conn.db
-	query := "select datid,datname,pid,usesysid,usename," +
-		"application_name,client_addr,client_hostname,client_port,backend_start," +
-		"xact_start,query_start,state_change,waiting," +
-		"state,backend_xid,backend_xmin,query from pg_stat_activity"
-
-	rows, err := db.Query(query)
-	if err != nil {
+	if err := s.fetchRows(&data, "pg_stat_activity"); err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	data := make([]PgStatActivityRow, 0)
-	for rows.Next() {
-		row := new(PgStatActivityRow)
-		err := rows.Scan(&row.Datid, &row.Datname, &row.Pid, &row.Usesysid, &row.Usename,
-			&row.ApplicationName, &row.ClientAddr, &row.ClientHostname, &row.ClientPort, &row.BackendStart,
-			&row.XactStart, &row.QueryStart, &row.StateChange, &row.Waiting,
-			&row.State, &row.BackendXid, &row.BackendXmin, &row.Query)
-		if err != nil {
-			return nil, err
-		}
-		data = append(data, *row)
-	}
-	return data, rows.Err()
+	return data, nil
 }
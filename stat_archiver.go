@@ -23,7 +23,7 @@ type PgStatArchiverView struct {
 }
 
 func (s *PgStats) fetchArchiver() (PgStatArchiverView, error) {
-	db := s.conn.db
+	db := s.db()
 	query := "select archived_count,last_archived_wal,last_archived_time,failed_count," +
 		"last_failed_wal,last_failed_time,stats_reset from pg_stat_archiver"
 	row := db.QueryRow(query)
@@ -34,7 +34,7 @@ type PgStatBgWriterView struct {
 }
 
 func (s *PgStats) fetchBgWriter() (PgStatBgWriterView, error) {
-	db := s.conn.db
+	db := s.db()
 	query := "select checkpoints_timed,checkpoints_req,checkpoint_write_time,checkpoint_sync_time,buffers_checkpoint," +
 		"buffers_clean,maxwritten_clean,buffers_backend,buffers_backend_fsync,buffers_alloc,stats_reset" +
 		" from pg_stat_bgwriter"
@@ -60,7 +60,7 @@ type PgStatDatabaseRow struct {
 }
 
 func (s *PgStats) fetchDatabases() ([]PgStatDatabaseRow, error) {
-	db := s.conn.db
+	db := s.db()
 	query := "select datid,datname,numbackends,xact_commit,xact_rollback," +
 		"blks_read,blks_hit,tup_returned,tup_fetched,tup_inserted," +
 		"tup_updated,tup_deleted,conflicts,temp_files,temp_bytes," +
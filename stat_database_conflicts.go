@@ -26,7 +26,7 @@ type PgStatDatabaseConflictsRow struct {
 }
 
 func (s *PgStats) fetchDatabaseConflicts() ([]PgStatDatabaseConflictsRow, error) {
-	db := s.conn.db
+	db := s.db()
 	query := "select datid,datname," +
 		"confl_tablespace,confl_lock,confl_snapshot,confl_bufferpin,confl_deadlock" +
 		" from pg_stat_database_conflicts"
@@ -27,7 +27,7 @@ type PgStatFunctionsRow struct {
 }
 
 func (s *PgStats) fetchFunctions(view string) ([]PgStatFunctionsRow, error) {
-	db := s.conn.db
+	db := s.db()
 	query := "select funcid,schemaname,funcname,calls,total_time,self_time from " + view
 
 	rows, err := db.Query(query)
@@ -31,10 +31,13 @@ type PgStatIndexesRow struct {
 	IdxTupRead nullable.Int64 `json:"idx_tup_read"`
 	// Number of live table rows fetched by simple index scans using this index
 	IdxTupFetch nullable.Int64 `json:"idx_tup_fetch"`
+	// Size of this index, in bytes.
+	// Null unless sizes have been joined with JoinIndexSizes
+	Size nullable.Int64 `json:"size"`
 }
 
 func (s *PgStats) fetchIndexes(view string) ([]PgStatIndexesRow, error) {
-	db := s.conn.db
+	db := s.db()
 	query := "select relid,indexrelid,schemaname,relname,indexrelname," +
 		"idx_scan,idx_tup_read,idx_tup_fetch from " + view
 
@@ -49,7 +49,7 @@ func (s *PgStats) fetchProgressVacuum() (PgStatProgressVacuumView, error) {
 		return nil, errors.Errorf("Unsupported PostgreSQL version: %f", version)
 	}
 
-	db := s.conn.db
+	db := s.db()
 	query := "select pid,datid,datname,relid,phase," +
 		"heap_blks_total,heap_blks_scanned,heap_blks_vacuumed,index_vacuum_count,max_dead_tuples," +
 		"num_dead_tuples from pg_stat_progress_vacuum"
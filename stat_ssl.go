@@ -37,7 +37,7 @@ func (s *PgStats) fetchSsl() (PgStatSslView, error) {
 		return nil, errors.Errorf("Unsupported PostgreSQL version: %f", version)
 	}
 
-	db := s.conn.db
+	db := s.db()
 	query := "select pid,ssl,version,cipher,bits," +
 		"compression,clientdn from pg_stat_ssl"
 
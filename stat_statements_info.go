@@ -0,0 +1,32 @@
+package pgstats
+
+import (
+	"github.com/pkg/errors"
+	"github.com/vynaloze/pgstats/nullable"
+)
+
+// PgStatStatementsInfoView represents content of pg_stat_statements_info view
+type PgStatStatementsInfoView struct {
+	// Total number of times pg_stat_statements entries about the least-executed statements were deallocated
+	// because more distinct statements than pg_stat_statements.max were observed
+	Dealloc int64 `json:"dealloc"`
+	// Time at which all statistics in the pg_stat_statements view were last reset
+	StatsReset nullable.Time `json:"stats_reset"`
+}
+
+func (s *PgStats) fetchStatementsInfo() (PgStatStatementsInfoView, error) {
+	version, err := s.getPgVersion()
+	if err != nil {
+		return PgStatStatementsInfoView{}, err
+	}
+	if version < 14 {
+		return PgStatStatementsInfoView{}, errors.Errorf("Unsupported PostgreSQL version: %f", version)
+	}
+
+	db := s.db()
+	query := "select dealloc,stats_reset from pg_stat_statements_info"
+	row := db.QueryRow(query)
+	res := new(PgStatStatementsInfoView)
+	err = row.Scan(&res.Dealloc, &res.StatsReset)
+	return *res, err
+}
@@ -39,7 +39,7 @@ func (s *PgStats) fetchSubscription() (PgStatSubscriptionView, error) {
 		return nil, errors.Errorf("Unsupported PostgreSQL version: %f", version)
 	}
 
-	db := s.conn.db
+	db := s.db()
 	query := "select subid,subname,pid,relid,received_lsn," +
 		"last_msg_send_time,last_msg_receipt_time,latest_end_lsn,latest_end_time " +
 		"from pg_stat_subscription"
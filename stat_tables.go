@@ -59,10 +59,22 @@ type PgStatTablesRow struct {
 	AnalyzeCount nullable.Int64 `json:"analyze_count"`
 	// Number of times this table has been analyzed by the autovacuum daemon
 	AutoanalyzeCount nullable.Int64 `json:"autoanalyze_count"`
+	// Size of the main data fork of this table, in bytes.
+	// Null unless sizes have been joined with JoinTableSizes
+	HeapSize nullable.Int64 `json:"heap_size"`
+	// Size of the TOAST table of this table (including its index), in bytes.
+	// Null unless sizes have been joined with JoinTableSizes
+	ToastSize nullable.Int64 `json:"toast_size"`
+	// Total size of all indexes of this table, in bytes.
+	// Null unless sizes have been joined with JoinTableSizes
+	IndexesSize nullable.Int64 `json:"indexes_size"`
+	// Total size of this table, including TOAST and indexes, in bytes.
+	// Null unless sizes have been joined with JoinTableSizes
+	TotalSize nullable.Int64 `json:"total_size"`
 }
 
 func (s *PgStats) fetchTables(view string) ([]PgStatTablesRow, error) {
-	db := s.conn.db
+	db := s.db()
 	query := "select relid,schemaname,relname,seq_scan,seq_tup_read," +
 		"idx_scan,idx_tup_fetch,n_tup_ins,n_tup_upd,n_tup_del," +
 		"n_tup_hot_upd,n_live_tup,n_dead_tup,n_mod_since_analyze,last_vacuum," +
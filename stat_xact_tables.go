@@ -40,7 +40,7 @@ type PgStatXactTablesRow struct {
 }
 
 func (s *PgStats) fetchXactTables(view string) ([]PgStatXactTablesRow, error) {
-	db := s.conn.db
+	db := s.db()
 	query := "select relid,schemaname,relname,seq_scan,seq_tup_read," +
 		"idx_scan,idx_tup_fetch,n_tup_ins,n_tup_upd,n_tup_del,n_tup_hot_upd from " + view
 
@@ -0,0 +1,186 @@
+package pgstats
+
+import (
+	"math"
+	"sort"
+)
+
+// PgStatStatementsDelta represents the difference between two snapshots of pg_stat_statements view,
+// i.e. the workload executed between the moments both snapshots were taken
+type PgStatStatementsDelta struct {
+	// Per-statement deltas, ordered by share of total time (descending)
+	Rows []PgStatStatementsDeltaRow `json:"rows"`
+	// Total time spent in all statements within the window, in milliseconds
+	TotalTime float64 `json:"total_time"`
+	// Total number of calls of all statements within the window
+	Calls int64 `json:"calls"`
+	// True if pg_stat_statements_reset() was called between the snapshots.
+	// In such case, values counted since the reset are reported for every statement.
+	Reset bool `json:"reset"`
+	// Number of entries deallocated between the snapshots because more distinct statements
+	// than pg_stat_statements.max were observed. Always zero if pg_stat_statements_info was not provided.
+	Evicted int64 `json:"evicted"`
+}
+
+// PgStatStatementsDeltaRow represents the difference in statistics of a single statement between two snapshots
+type PgStatStatementsDeltaRow struct {
+	// OID of user who executed the statement
+	Userid int64 `json:"userid"`
+	// OID of database in which the statement was executed
+	Dbid int64 `json:"dbid"`
+	// Internal hash code, computed from the statement's parse tree
+	Queryid int64 `json:"queryid"`
+	// Text of a representative statement
+	Query string `json:"query"`
+	// True if the statement was not present in the earlier snapshot (it is new, was evicted and re-added,
+	// or the statistics were reset), so its values are counted from zero
+	New bool `json:"new"`
+	// Number of times executed within the window
+	Calls int64 `json:"calls"`
+	// Time spent in the statement within the window, in milliseconds
+	TotalTime float64 `json:"total_time"`
+	// Mean time spent in the statement within the window, in milliseconds
+	MeanTime float64 `json:"mean_time"`
+	// Population standard deviation of time spent in the statement within the window, in milliseconds
+	StddevTime float64 `json:"stddev_time"`
+	// Share of this statement in total time spent in all statements within the window, between 0 and 1
+	TimeShare float64 `json:"time_share"`
+	// Number of rows retrieved or affected by the statement within the window
+	Rows int64 `json:"rows"`
+	// Number of shared block cache hits by the statement within the window
+	SharedBlksHit int64 `json:"shared_blks_hit"`
+	// Number of shared blocks read by the statement within the window
+	SharedBlksRead int64 `json:"shared_blks_read"`
+	// Number of shared blocks dirtied by the statement within the window
+	SharedBlksDirtied int64 `json:"shared_blks_dirtied"`
+	// Number of shared blocks written by the statement within the window
+	SharedBlksWritten int64 `json:"shared_blks_written"`
+	// Number of local block cache hits by the statement within the window
+	LocalBlksHit int64 `json:"local_blks_hit"`
+	// Number of local blocks read by the statement within the window
+	LocalBlksRead int64 `json:"local_blks_read"`
+	// Number of local blocks dirtied by the statement within the window
+	LocalBlksDirtied int64 `json:"local_blks_dirtied"`
+	// Number of local blocks written by the statement within the window
+	LocalBlksWritten int64 `json:"local_blks_written"`
+	// Number of temp blocks read by the statement within the window
+	TempBlksRead int64 `json:"temp_blks_read"`
+	// Number of temp blocks written by the statement within the window
+	TempBlksWritten int64 `json:"temp_blks_written"`
+	// Time the statement spent reading blocks within the window, in milliseconds
+	BlkReadTime float64 `json:"blk_read_time"`
+	// Time the statement spent writing blocks within the window, in milliseconds
+	BlkWriteTime float64 `json:"blk_write_time"`
+}
+
+type statementKey struct {
+	userid  int64
+	dbid    int64
+	queryid int64
+}
+
+// Delta computes per-statement differences between the earlier snapshot prev and this one.
+//
+// A statement whose number of calls went down since prev is assumed to have been
+// evicted and re-added, and is reported with values counted from zero.
+// Statements which did not execute within the window are omitted.
+func (v PgStatStatementsView) Delta(prev PgStatStatementsView) PgStatStatementsDelta {
+	return v.delta(prev, false)
+}
+
+// DeltaWithInfo computes per-statement differences between the earlier snapshot prev and this one,
+// using pg_stat_statements_info taken together with both snapshots
+// to detect pg_stat_statements_reset() calls and evictions of entries.
+//
+// Supported since PostgreSQL 14.
+func (v PgStatStatementsView) DeltaWithInfo(prev PgStatStatementsView,
+	info PgStatStatementsInfoView, prevInfo PgStatStatementsInfoView) PgStatStatementsDelta {
+	reset := info.StatsReset.Valid && (!prevInfo.StatsReset.Valid || !info.StatsReset.Time.Equal(prevInfo.StatsReset.Time))
+	d := v.delta(prev, reset)
+	if !reset && info.Dealloc > prevInfo.Dealloc {
+		d.Evicted = info.Dealloc - prevInfo.Dealloc
+	} else if reset {
+		// dealloc is reset together with the statistics
+		d.Evicted = info.Dealloc
+	}
+	return d
+}
+
+func (v PgStatStatementsView) delta(prev PgStatStatementsView, reset bool) PgStatStatementsDelta {
+	previous := make(map[statementKey]PgStatStatementsRow, len(prev))
+	if !reset {
+		for _, p := range prev {
+			previous[statementKey{p.Userid, p.Dbid, p.Queryid}] = p
+		}
+	}
+
+	res := PgStatStatementsDelta{Reset: reset, Rows: make([]PgStatStatementsDeltaRow, 0)}
+	for _, c := range v {
+		p, ok := previous[statementKey{c.Userid, c.Dbid, c.Queryid}]
+		if ok && c.Calls < p.Calls {
+			ok = false
+		}
+		if !ok {
+			p = PgStatStatementsRow{}
+		}
+		if c.Calls == p.Calls {
+			continue
+		}
+		row := statementDelta(c, p)
+		row.New = !ok
+		res.Rows = append(res.Rows, row)
+		res.TotalTime += row.TotalTime
+		res.Calls += row.Calls
+	}
+
+	for i := range res.Rows {
+		if res.TotalTime > 0 {
+			res.Rows[i].TimeShare = res.Rows[i].TotalTime / res.TotalTime
+		}
+	}
+	sort.SliceStable(res.Rows, func(i, j int) bool {
+		return res.Rows[i].TotalTime > res.Rows[j].TotalTime
+	})
+	return res
+}
+
+func statementDelta(c PgStatStatementsRow, p PgStatStatementsRow) PgStatStatementsDeltaRow {
+	row := PgStatStatementsDeltaRow{
+		Userid:            c.Userid,
+		Dbid:              c.Dbid,
+		Queryid:           c.Queryid,
+		Query:             c.Query,
+		Calls:             c.Calls - p.Calls,
+		TotalTime:         c.TotalTime - p.TotalTime,
+		Rows:              c.Rows - p.Rows,
+		SharedBlksHit:     c.SharedBlksHit - p.SharedBlksHit,
+		SharedBlksRead:    c.SharedBlksRead - p.SharedBlksRead,
+		SharedBlksDirtied: c.SharedBlksDirtied - p.SharedBlksDirtied,
+		SharedBlksWritten: c.SharedBlksWritten - p.SharedBlksWritten,
+		LocalBlksHit:      c.LocalBlksHit - p.LocalBlksHit,
+		LocalBlksRead:     c.LocalBlksRead - p.LocalBlksRead,
+		LocalBlksDirtied:  c.LocalBlksDirtied - p.LocalBlksDirtied,
+		LocalBlksWritten:  c.LocalBlksWritten - p.LocalBlksWritten,
+		TempBlksRead:      c.TempBlksRead - p.TempBlksRead,
+		TempBlksWritten:   c.TempBlksWritten - p.TempBlksWritten,
+		BlkReadTime:       c.BlkReadTime - p.BlkReadTime,
+		BlkWriteTime:      c.BlkWriteTime - p.BlkWriteTime,
+	}
+	if row.Calls > 0 {
+		// Both mean and stddev are derived from the sums of times and squared times,
+		// which (unlike the means themselves) can be subtracted between snapshots.
+		n := float64(row.Calls)
+		row.MeanTime = row.TotalTime / n
+		sumSq := sumOfSquares(c) - sumOfSquares(p)
+		variance := sumSq/n - row.MeanTime*row.MeanTime
+		if variance > 0 {
+			row.StddevTime = math.Sqrt(variance)
+		}
+	}
+	return row
+}
+
+func sumOfSquares(r PgStatStatementsRow) float64 {
+	n := float64(r.Calls)
+	return n * (r.StddevTime*r.StddevTime + r.MeanTime*r.MeanTime)
+}
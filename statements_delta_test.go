@@ -0,0 +1,73 @@
+package pgstats
+
+import (
+	"github.com/vynaloze/pgstats/nullable"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestStatementsDelta(t *testing.T) {
+	// first statement: 2 calls of 10ms and 30ms, then 2 more calls of 20ms and 40ms
+	prev := PgStatStatementsView{
+		{Userid: 1, Dbid: 1, Queryid: 1, Calls: 2, TotalTime: 40, MeanTime: 20, StddevTime: 10, Rows: 2},
+		{Userid: 1, Dbid: 1, Queryid: 2, Calls: 5, TotalTime: 5, MeanTime: 1},
+		{Userid: 1, Dbid: 1, Queryid: 3, Calls: 100, TotalTime: 100, MeanTime: 1},
+	}
+	cur := PgStatStatementsView{
+		{Userid: 1, Dbid: 1, Queryid: 1, Calls: 4, TotalTime: 100, MeanTime: 25, StddevTime: math.Sqrt(125), Rows: 4},
+		{Userid: 1, Dbid: 1, Queryid: 2, Calls: 5, TotalTime: 5, MeanTime: 1},
+		{Userid: 1, Dbid: 1, Queryid: 3, Calls: 10, TotalTime: 20, MeanTime: 2},
+	}
+
+	d := cur.Delta(prev)
+	if len(d.Rows) != 2 {
+		t.Fatalf("Expected 2 rows; actual %d", len(d.Rows))
+	}
+	first := d.Rows[0]
+	if first.Queryid != 1 || first.Calls != 2 || first.TotalTime != 60 || first.Rows != 2 || first.New {
+		t.Errorf("Unexpected delta of first statement: %+v", first)
+	}
+	if first.MeanTime != 30 || math.Abs(first.StddevTime-10) > 1e-9 {
+		t.Errorf("Expected mean 30 and stddev 10; actual %f and %f", first.MeanTime, first.StddevTime)
+	}
+	second := d.Rows[1]
+	if second.Queryid != 3 || second.Calls != 10 || !second.New {
+		t.Errorf("Expected statement 3 to be reported as re-added; actual %+v", second)
+	}
+	if d.TotalTime != 80 || math.Abs(first.TimeShare-0.75) > 1e-9 {
+		t.Errorf("Expected total time 80 and share 0.75; actual %f and %f", d.TotalTime, first.TimeShare)
+	}
+}
+
+func TestStatementsDeltaWithInfo(t *testing.T) {
+	prev := PgStatStatementsView{{Userid: 1, Dbid: 1, Queryid: 1, Calls: 2, TotalTime: 2, MeanTime: 1}}
+	cur := PgStatStatementsView{{Userid: 1, Dbid: 1, Queryid: 1, Calls: 3, TotalTime: 3, MeanTime: 1}}
+	before := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	after := before.Add(time.Minute)
+
+	var infoTests = []struct {
+		prevInfo PgStatStatementsInfoView
+		info     PgStatStatementsInfoView
+		reset    bool
+		evicted  int64
+		calls    int64
+	}{
+		{infoAt(before, 3), infoAt(before, 5), false, 2, 1},
+		{infoAt(before, 3), infoAt(after, 1), true, 1, 3},
+	}
+	for _, tt := range infoTests {
+		d := cur.DeltaWithInfo(prev, tt.info, tt.prevInfo)
+		if d.Reset != tt.reset || d.Evicted != tt.evicted || d.Calls != tt.calls {
+			t.Errorf("Expected reset=%t evicted=%d calls=%d; actual reset=%t evicted=%d calls=%d",
+				tt.reset, tt.evicted, tt.calls, d.Reset, d.Evicted, d.Calls)
+		}
+	}
+}
+
+func infoAt(reset time.Time, dealloc int64) PgStatStatementsInfoView {
+	info := PgStatStatementsInfoView{Dealloc: dealloc, StatsReset: nullable.Time{}}
+	info.StatsReset.Valid = true
+	info.StatsReset.Time = reset
+	return info
+}
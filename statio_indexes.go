@@ -32,7 +32,7 @@ type PgStatIoIndexesRow struct {
 }
 
 func (s *PgStats) fetchIoIndexes(view string) ([]PgStatIoIndexesRow, error) {
-	db := s.conn.db
+	db := s.db()
 	query := "select relid,indexrelid,schemaname,relname,indexrelname," +
 		"idx_blks_read,idx_blks_hit from " + view
 
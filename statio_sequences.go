@@ -28,7 +28,7 @@ type PgStatIoSequencesRow struct {
 }
 
 func (s *PgStats) fetchIoSequences(view string) ([]PgStatIoSequencesRow, error) {
-	db := s.conn.db
+	db := s.db()
 	query := "select relid,schemaname,relname,blks_read,blks_hit from " + view
 
 	rows, err := db.Query(query)
@@ -40,7 +40,7 @@ type PgStatIoTablesRow struct {
 }
 
 func (s *PgStats) fetchIoTables(view string) ([]PgStatIoTablesRow, error) {
-	db := s.conn.db
+	db := s.db()
 	query := "select relid,schemaname,relname," +
 		"heap_blks_read,heap_blks_hit," +
 		"idx_blks_read,idx_blks_hit," +
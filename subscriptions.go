@@ -0,0 +1,306 @@
+package pgstats
+
+import (
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/vynaloze/pgstats/nullable"
+	"strconv"
+	"strings"
+)
+
+// SubscriptionStatsView represents content of pg_stat_subscription_stats view
+type SubscriptionStatsView []SubscriptionStatsRow
+
+// SubscriptionStatsRow represents schema of pg_stat_subscription_stats view
+type SubscriptionStatsRow struct {
+	// OID of the subscription
+	Subid int64 `json:"subid"`
+	// Name of the subscription
+	Subname string `json:"subname"`
+	// Number of times an error occurred while applying changes
+	ApplyErrorCount int64 `json:"apply_error_count"`
+	// Number of times an error occurred during the initial table synchronization
+	SyncErrorCount int64 `json:"sync_error_count"`
+	// Time at which these statistics were last reset
+	StatsReset nullable.Time `json:"stats_reset"`
+}
+
+// SubscriptionRelView represents synchronization state of each table of subscriptions in the current database
+type SubscriptionRelView []SubscriptionRelRow
+
+// SubscriptionRelRow represents synchronization state of a single table of a subscription
+type SubscriptionRelRow struct {
+	// OID of the subscription
+	Subid int64 `json:"subid"`
+	// Name of the subscription
+	Subname string `json:"subname"`
+	// OID of the table
+	Relid int64 `json:"relid"`
+	// Name of the schema that the table is in
+	Schemaname string `json:"schemaname"`
+	// Name of the table
+	Relname string `json:"relname"`
+	// State code: i = initialize, d = data is being copied, f = finished table copy,
+	// s = synchronized, r = ready (normal replication)
+	State string `json:"state"`
+	// Descriptive name of the state: initialize, data_copy, finished_copy, synchronized or ready
+	StateName string `json:"state_name"`
+	// Remote LSN of the state change used for synchronization coordination when in s or r states
+	Lsn nullable.String `json:"lsn"`
+}
+
+// SubscriptionLagView represents lag of subscriptions in the current database behind their publisher
+type SubscriptionLagView []SubscriptionLagRow
+
+// SubscriptionLagRow represents lag of a single subscription behind its publisher
+type SubscriptionLagRow struct {
+	// OID of the subscription
+	Subid int64 `json:"subid"`
+	// Name of the subscription
+	Subname string `json:"subname"`
+	// True if the subscription is enabled
+	Enabled bool `json:"enabled"`
+	// Name of the replication slot on the publisher, if any
+	SlotName nullable.String `json:"slot_name"`
+	// Process ID of the apply worker, if running
+	Pid nullable.Int64 `json:"pid"`
+	// Last write-ahead log location received by the apply worker
+	ReceivedLsn nullable.String `json:"received_lsn"`
+	// Last write-ahead log location reported to the publisher
+	LatestEndLsn nullable.String `json:"latest_end_lsn"`
+	// Receipt time of the last message received from the publisher
+	LastMsgReceiptTime nullable.Time `json:"last_msg_receipt_time"`
+	// True if the replication slot has been found on the publisher
+	SlotFound bool `json:"slot_found"`
+	// True if the replication slot is currently in use on the publisher
+	SlotActive nullable.Bool `json:"slot_active"`
+	// Current write-ahead log location of the publisher
+	PublisherLsn nullable.String `json:"publisher_lsn"`
+	// Location up to which the subscriber has confirmed receiving data (confirmed_flush_lsn of the slot)
+	ConfirmedFlushLsn nullable.String `json:"confirmed_flush_lsn"`
+	// Amount of WAL generated on the publisher but not yet received by the subscriber, in bytes
+	ReceiveLagBytes nullable.Int64 `json:"receive_lag_bytes"`
+	// Amount of WAL generated on the publisher but not yet confirmed by the subscriber, in bytes.
+	// This is also the amount of WAL retained by the slot for decoding.
+	ConfirmedFlushLagBytes nullable.Int64 `json:"confirmed_flush_lag_bytes"`
+}
+
+// publisherSlot represents state of a logical replication slot on the publisher
+type publisherSlot struct {
+	active            bool
+	confirmedFlushLsn nullable.String
+	currentLsn        string
+}
+
+var subscriptionRelStates = map[string]string{
+	"i": "initialize",
+	"d": "data_copy",
+	"f": "finished_copy",
+	"s": "synchronized",
+	"r": "ready",
+}
+
+func (s *PgStats) fetchSubscriptionStats() (SubscriptionStatsView, error) {
+	version, err := s.getPgVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version < 15 {
+		return nil, errors.Errorf("Unsupported PostgreSQL version: %f", version)
+	}
+
+	db := s.db()
+	query := "select subid,subname,apply_error_count,sync_error_count,stats_reset from pg_stat_subscription_stats"
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make(SubscriptionStatsView, 0)
+	for rows.Next() {
+		row := new(SubscriptionStatsRow)
+		err := rows.Scan(&row.Subid, &row.Subname, &row.ApplyErrorCount, &row.SyncErrorCount, &row.StatsReset)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, *row)
+	}
+	return data, rows.Err()
+}
+
+func (s *PgStats) fetchSubscriptionRels() (SubscriptionRelView, error) {
+	version, err := s.getPgVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version < 10 {
+		return nil, errors.Errorf("Unsupported PostgreSQL version: %f", version)
+	}
+
+	db := s.db()
+	query := "select s.oid,s.subname,c.oid,n.nspname,c.relname,r.srsubstate::text,r.srsublsn::text " +
+		"from pg_subscription_rel r join pg_subscription s on s.oid=r.srsubid " +
+		"join pg_class c on c.oid=r.srrelid join pg_namespace n on n.oid=c.relnamespace " +
+		"order by s.subname,n.nspname,c.relname"
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make(SubscriptionRelView, 0)
+	for rows.Next() {
+		row := new(SubscriptionRelRow)
+		err := rows.Scan(&row.Subid, &row.Subname, &row.Relid, &row.Schemaname, &row.Relname, &row.State, &row.Lsn)
+		if err != nil {
+			return nil, err
+		}
+		row.StateName = subscriptionRelStates[row.State]
+		data = append(data, *row)
+	}
+	return data, rows.Err()
+}
+
+func (s *PgStats) fetchSubscriptionLag(publisher *PgStats) (SubscriptionLagView, error) {
+	version, err := s.getPgVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version < 10 {
+		return nil, errors.Errorf("Unsupported PostgreSQL version: %f", version)
+	}
+	applyWorker := "w.relid is null"
+	if version >= 16 {
+		applyWorker += " and w.leader_pid is null"
+	}
+
+	db := s.db()
+	query := "select s.oid,s.subname,s.subenabled,s.subslotname::text,w.pid," +
+		"w.received_lsn::text,w.latest_end_lsn::text,w.last_msg_receipt_time " +
+		"from pg_subscription s left join pg_stat_subscription w on w.subid=s.oid and " + applyWorker + " " +
+		"where s.subdbid=(select oid from pg_database where datname=current_database())"
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make(SubscriptionLagView, 0)
+	slotNames := make([]string, 0)
+	for rows.Next() {
+		row := new(SubscriptionLagRow)
+		err := rows.Scan(&row.Subid, &row.Subname, &row.Enabled, &row.SlotName, &row.Pid,
+			&row.ReceivedLsn, &row.LatestEndLsn, &row.LastMsgReceiptTime)
+		if err != nil {
+			return nil, err
+		}
+		if row.SlotName.Valid {
+			slotNames = append(slotNames, row.SlotName.String)
+		}
+		data = append(data, *row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slots, err := publisher.fetchPublisherSlots(slotNames)
+	if err != nil {
+		return nil, err
+	}
+	return subscriptionLag(data, slots)
+}
+
+func (s *PgStats) fetchPublisherSlots(names []string) (map[string]publisherSlot, error) {
+	version, err := s.getPgVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version < 10 {
+		return nil, errors.Errorf("Unsupported PostgreSQL version: %f", version)
+	}
+
+	db := s.db()
+	query := "select slot_name::text,active,confirmed_flush_lsn::text," + walFunctionsFor(version).currentLsnExpr() + "::text " +
+		"from pg_replication_slots where slot_type='logical' and slot_name=any($1)"
+
+	rows, err := db.Query(query, pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slots := make(map[string]publisherSlot, len(names))
+	for rows.Next() {
+		var name string
+		slot := publisherSlot{}
+		if err := rows.Scan(&name, &slot.active, &slot.confirmedFlushLsn, &slot.currentLsn); err != nil {
+			return nil, err
+		}
+		slots[name] = slot
+	}
+	return slots, rows.Err()
+}
+
+// subscriptionLag pairs subscriptions with replication slots on the publisher and computes the lags
+func subscriptionLag(subscriptions SubscriptionLagView, slots map[string]publisherSlot) (SubscriptionLagView, error) {
+	for i := range subscriptions {
+		row := &subscriptions[i]
+		slot, ok := slots[row.SlotName.String]
+		if !row.SlotName.Valid || !ok {
+			continue
+		}
+		row.SlotFound = true
+		row.SlotActive.Valid, row.SlotActive.Bool = true, slot.active
+		row.PublisherLsn.Valid, row.PublisherLsn.String = true, slot.currentLsn
+		row.ConfirmedFlushLsn = slot.confirmedFlushLsn
+
+		current, err := parseLsn(slot.currentLsn)
+		if err != nil {
+			return nil, err
+		}
+		if row.ReceivedLsn.Valid {
+			received, err := parseLsn(row.ReceivedLsn.String)
+			if err != nil {
+				return nil, err
+			}
+			row.ReceiveLagBytes.Valid, row.ReceiveLagBytes.Int64 = true, lsnDiff(current, received)
+		}
+		if slot.confirmedFlushLsn.Valid {
+			confirmed, err := parseLsn(slot.confirmedFlushLsn.String)
+			if err != nil {
+				return nil, err
+			}
+			row.ConfirmedFlushLagBytes.Valid, row.ConfirmedFlushLagBytes.Int64 = true, lsnDiff(current, confirmed)
+		}
+	}
+	return subscriptions, nil
+}
+
+// parseLsn converts textual representation of a WAL location (e.g. 16/B374D848) to a byte position
+func parseLsn(lsn string) (uint64, error) {
+	parts := strings.SplitN(lsn, "/", 2)
+	if len(parts) != 2 {
+		return 0, errors.Errorf("Invalid WAL location: %s", lsn)
+	}
+	hi, err := strconv.ParseUint(parts[0], 16, 32)
+	if err != nil {
+		return 0, errors.Wrapf(err, "Invalid WAL location: %s", lsn)
+	}
+	lo, err := strconv.ParseUint(parts[1], 16, 32)
+	if err != nil {
+		return 0, errors.Wrapf(err, "Invalid WAL location: %s", lsn)
+	}
+	return hi<<32 | lo, nil
+}
+
+// lsnDiff returns the number of bytes between WAL locations, like pg_wal_lsn_diff
+func lsnDiff(a uint64, b uint64) int64 {
+	if a >= b {
+		return int64(a - b)
+	}
+	return -int64(b - a)
+}
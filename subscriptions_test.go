@@ -0,0 +1,64 @@
+package pgstats
+
+import (
+	"github.com/vynaloze/pgstats/nullable"
+	"testing"
+)
+
+func TestParseLsn(t *testing.T) {
+	lsn, err := parseLsn("16/B374D848")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lsn != 0x16B374D848 {
+		t.Errorf("Expected %d; actual %d", uint64(0x16B374D848), lsn)
+	}
+	for _, invalid := range []string{"", "16B374D848", "X/1", "1/100000000"} {
+		if _, err := parseLsn(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func TestSubscriptionLag(t *testing.T) {
+	subs := SubscriptionLagView{
+		subscription("orders", "orders_slot", "0/3000000"),
+		subscription("stopped", "stopped_slot", ""),
+		subscription("elsewhere", "other_slot", "0/1000000"),
+		subscription("slotless", "", ""),
+	}
+	slots := map[string]publisherSlot{
+		"orders_slot":  {active: true, currentLsn: "1/0", confirmedFlushLsn: lsnString("0/2000000")},
+		"stopped_slot": {currentLsn: "1/0", confirmedFlushLsn: lsnString("0/FF000000")},
+	}
+
+	res, err := subscriptionLag(subs, slots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res[0].SlotFound || !res[0].SlotActive.Bool || res[0].ReceiveLagBytes.Int64 != 0x100000000-0x3000000 ||
+		res[0].ConfirmedFlushLagBytes.Int64 != 0x100000000-0x2000000 || res[0].PublisherLsn.String != "1/0" {
+		t.Errorf("Unexpected lag of orders: %+v", res[0])
+	}
+	if !res[1].SlotFound || res[1].SlotActive.Bool || res[1].ReceiveLagBytes.Valid || res[1].ConfirmedFlushLagBytes.Int64 != 0x1000000 {
+		t.Errorf("Unexpected lag of stopped: %+v", res[1])
+	}
+	for _, r := range res[2:] {
+		if r.SlotFound || r.ReceiveLagBytes.Valid || r.ConfirmedFlushLagBytes.Valid {
+			t.Errorf("Expected no lag of %s; actual %+v", r.Subname, r)
+		}
+	}
+}
+
+func subscription(name string, slot string, received string) SubscriptionLagRow {
+	row := SubscriptionLagRow{Subname: name, Enabled: true}
+	row.SlotName.Valid, row.SlotName.String = slot != "", slot
+	row.ReceivedLsn = lsnString(received)
+	return row
+}
+
+func lsnString(lsn string) nullable.String {
+	res := nullable.String{}
+	res.Valid, res.String = lsn != "", lsn
+	return res
+}
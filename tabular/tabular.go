@@ -0,0 +1,300 @@
+// Package tabular writes pgstats views as CSV, TSV or aligned text tables for terminals.
+//
+// Columns are named after json tags of the fields of rows. Nullable values are unwrapped,
+// with nulls written as the configured null string, and nested rows are written as JSON.
+package tabular
+
+import (
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Format represents an output format
+type Format int
+
+const (
+	// CSV is comma-separated values, as described in RFC 4180
+	CSV Format = iota
+	// TSV is tab-separated values; tabs, newlines and backslashes in values are escaped
+	// as in the text format of PostgreSQL COPY
+	TSV
+	// Aligned is a table with aligned columns, similar to the output of psql
+	Aligned
+)
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// Encoder writes views in the chosen format
+type Encoder struct {
+	// Null is written in place of null values. Empty by default.
+	Null string
+	// TimeFormat is the layout of times, as accepted by time.Format. RFC 3339 with fractional seconds by default.
+	TimeFormat string
+	// Location, if set, is the time zone times are converted to
+	Location *time.Location
+	// Header tells whether to write a row of column names. True by default.
+	// In CSV and TSV, the header is written only before the first encoded view.
+	Header bool
+	w      io.Writer
+	format Format
+	// wroteHeader is true once the header has been written in CSV or TSV
+	wroteHeader bool
+}
+
+// NewEncoder returns a new encoder writing to w in given format
+func NewEncoder(w io.Writer, format Format) *Encoder {
+	return &Encoder{TimeFormat: time.RFC3339Nano, Header: true, w: w, format: format}
+}
+
+// Encode writes v - a view, a single row or a pointer to either of them
+func (e *Encoder) Encode(v interface{}) error {
+	columns, rows := Rows(v)
+	return e.EncodeRows(columns, rows)
+}
+
+// EncodeRows writes given rows of values under given column names, e.g. as returned by Rows
+// and then sorted or filtered
+func (e *Encoder) EncodeRows(columns []string, rows [][]interface{}) error {
+	switch e.format {
+	case CSV:
+		return e.writeCSV(columns, rows)
+	case TSV:
+		return e.writeTSV(columns, rows)
+	}
+	return e.writeAligned(columns, rows)
+}
+
+// Write writes v to w in given format, with default settings
+func Write(w io.Writer, format Format, v interface{}) error {
+	return NewEncoder(w, format).Encode(v)
+}
+
+// Rows returns column names and rows of values of v - a view, a single row or a pointer to either of them.
+// Nullable values are unwrapped into their underlying values or nil; other values are kept as they are.
+func Rows(v interface{}) ([]string, [][]interface{}) {
+	r := reflect.ValueOf(v)
+	for r.Kind() == reflect.Ptr {
+		if r.IsNil() {
+			return nil, nil
+		}
+		r = r.Elem()
+	}
+	var rows [][]interface{}
+	switch r.Kind() {
+	case reflect.Slice:
+		if r.Type().Elem().Kind() != reflect.Struct {
+			return nil, nil
+		}
+		for i := 0; i < r.Len(); i++ {
+			rows = append(rows, values(r.Index(i)))
+		}
+		return columns(r.Type().Elem()), rows
+	case reflect.Struct:
+		return columns(r.Type()), [][]interface{}{values(r)}
+	}
+	return nil, nil
+}
+
+func columns(t reflect.Type) []string {
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if tag := jsonTag(t.Field(i)); tag != "" {
+			names = append(names, tag)
+		}
+	}
+	return names
+}
+
+func values(v reflect.Value) []interface{} {
+	row := make([]interface{}, 0, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		if jsonTag(v.Type().Field(i)) != "" {
+			row = append(row, value(v.Field(i)))
+		}
+	}
+	return row
+}
+
+func jsonTag(f reflect.StructField) string {
+	tag := strings.Split(f.Tag.Get("json"), ",")[0]
+	if f.PkgPath != "" || tag == "-" {
+		return ""
+	}
+	if tag == "" {
+		return f.Name
+	}
+	return tag
+}
+
+func value(v reflect.Value) interface{} {
+	if v.Type().Implements(valuerType) {
+		value, err := v.Interface().(driver.Valuer).Value()
+		if err != nil {
+			return nil
+		}
+		return value
+	}
+	return v.Interface()
+}
+
+// text renders a single value as text
+func (e *Encoder) text(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return e.Null
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	case bool:
+		return strconv.FormatBool(x)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'f', -1, 32)
+	case time.Time:
+		if e.Location != nil {
+			x = x.In(e.Location)
+		}
+		return x.Format(e.TimeFormat)
+	case time.Duration:
+		return x.String()
+	case fmt.Stringer:
+		return x.String()
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Map, reflect.Ptr:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
+
+func (e *Encoder) cells(row []interface{}) []string {
+	cells := make([]string, len(row))
+	for i, v := range row {
+		cells[i] = e.text(v)
+	}
+	return cells
+}
+
+func (e *Encoder) writeCSV(columns []string, rows [][]interface{}) error {
+	cw := csv.NewWriter(e.w)
+	if e.Header && !e.wroteHeader {
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+		e.wroteHeader = true
+	}
+	for _, row := range rows {
+		if err := cw.Write(e.cells(row)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func (e *Encoder) writeTSV(columns []string, rows [][]interface{}) error {
+	var b strings.Builder
+	writeLine := func(cells []string) {
+		for i, cell := range cells {
+			cells[i] = tsvEscaper.Replace(cell)
+		}
+		b.WriteString(strings.Join(cells, "\t") + "\n")
+	}
+	if e.Header && !e.wroteHeader {
+		writeLine(append([]string(nil), columns...))
+		e.wroteHeader = true
+	}
+	for _, row := range rows {
+		writeLine(e.cells(row))
+	}
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+var alignedEscaper = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+// writeAligned writes a table in the style of psql: columns separated with vertical bars,
+// numbers aligned right, and the number of rows below
+func (e *Encoder) writeAligned(columns []string, rows [][]interface{}) error {
+	widths := make([]int, len(columns))
+	numeric := make([]bool, len(columns))
+	for i, c := range columns {
+		widths[i] = utf8.RuneCountInString(c)
+		numeric[i] = true
+	}
+	cells := make([][]string, len(rows))
+	for r, row := range rows {
+		cells[r] = e.cells(row)
+		for i, cell := range cells[r] {
+			cell = alignedEscaper.Replace(cell)
+			cells[r][i] = cell
+			if w := utf8.RuneCountInString(cell); w > widths[i] {
+				widths[i] = w
+			}
+			if row[i] != nil && !isNumber(row[i]) {
+				numeric[i] = false
+			}
+		}
+	}
+
+	var b strings.Builder
+	writeLine := func(cells []string, right []bool) {
+		parts := make([]string, len(cells))
+		for i, cell := range cells {
+			padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			if right[i] {
+				parts[i] = " " + padding + cell + " "
+			} else {
+				parts[i] = " " + cell + padding + " "
+			}
+		}
+		b.WriteString(strings.TrimRight(strings.Join(parts, "|"), " ") + "\n")
+	}
+	if e.Header && len(columns) > 0 {
+		// headers are centered, as in psql
+		headers := make([]string, len(columns))
+		for i, c := range columns {
+			left := (widths[i] - utf8.RuneCountInString(c)) / 2
+			headers[i] = strings.Repeat(" ", left) + c
+		}
+		writeLine(headers, make([]bool, len(columns)))
+		separators := make([]string, len(columns))
+		for i := range columns {
+			separators[i] = strings.Repeat("-", widths[i]+2)
+		}
+		b.WriteString(strings.Join(separators, "+") + "\n")
+	}
+	for _, row := range cells {
+		writeLine(row, numeric)
+	}
+	if len(rows) == 1 {
+		b.WriteString("(1 row)\n")
+	} else {
+		b.WriteString(fmt.Sprintf("(%d rows)\n", len(rows)))
+	}
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func isNumber(v interface{}) bool {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package tabular
+
+import (
+	"bytes"
+	"database/sql"
+	"github.com/lib/pq"
+	"github.com/vynaloze/pgstats"
+	"github.com/vynaloze/pgstats/nullable"
+	"testing"
+	"time"
+)
+
+var reset = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+var view = pgstats.PgStatArchiverView{
+	ArchivedCount:    nullable.Int64{NullInt64: sql.NullInt64{Int64: 42, Valid: true}},
+	LastArchivedWal:  nullable.String{NullString: sql.NullString{String: "0000000100000000000000A1", Valid: true}},
+	LastArchivedTime: nullable.Time{NullTime: pq.NullTime{Time: reset, Valid: true}},
+	FailedCount:      nullable.Int64{NullInt64: sql.NullInt64{Int64: 0, Valid: true}},
+	LastFailedWal:    nullable.String{NullString: sql.NullString{String: "a,\"b\"\tc\nd", Valid: true}},
+}
+
+var databases = pgstats.PgStatDatabaseView{
+	{Datid: 1, Datname: "app", NumBackends: 3, XactCommit: nullable.Int64{NullInt64: sql.NullInt64{Int64: 10, Valid: true}}},
+	{Datid: 22, Datname: "żółw", NumBackends: 0},
+}
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf, CSV)
+	e.Null = "NULL"
+	e.TimeFormat = "2006-01-02 15:04:05"
+	if err := e.Encode(view); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Encode(&view); err != nil {
+		t.Fatal(err)
+	}
+	row := "42,0000000100000000000000A1,2024-01-02 03:04:05,0,\"a,\"\"b\"\"\tc\nd\",NULL,NULL\n"
+	expected := "archived_count,last_archived_wal,last_archived_time,failed_count,last_failed_wal,last_failed_time,stats_reset\n" + row + row
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Expected:\n%s\nactual:\n%s", expected, actual)
+	}
+}
+
+func TestTSV(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf, TSV)
+	e.Header = false
+	e.Location = time.FixedZone("", 3600)
+	if err := e.Encode(view); err != nil {
+		t.Fatal(err)
+	}
+	expected := "42\t0000000100000000000000A1\t2024-01-02T04:04:05+01:00\t0\ta,\"b\"\\tc\\nd\t\t\n"
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Expected:\n%q\nactual:\n%q", expected, actual)
+	}
+}
+
+func TestAligned(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf, Aligned)
+	columns, rows := Rows(databases)
+	if err := e.EncodeRows(columns[:4], [][]interface{}{rows[0][:4], rows[1][:4]}); err != nil {
+		t.Fatal(err)
+	}
+	expected := ` datid | datname | numbackends | xact_commit
+-------+---------+-------------+-------------
+     1 | app     |           3 |          10
+    22 | żółw    |           0 |
+(2 rows)
+`
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Expected:\n%s\nactual:\n%s", expected, actual)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, Aligned, (*pgstats.PgStatArchiverView)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if actual := buf.String(); actual != "(0 rows)\n" {
+		t.Errorf("Expected no rows; actual %q", actual)
+	}
+}
+
+func TestRows(t *testing.T) {
+	columns, rows := Rows(databases)
+	if len(columns) != len(rows[0]) || columns[1] != "datname" {
+		t.Errorf("Unexpected columns: %v", columns)
+	}
+	if rows[0][3] != int64(10) || rows[1][3] != nil {
+		t.Errorf("Expected unwrapped nullable values; actual %v and %v", rows[0][3], rows[1][3])
+	}
+}
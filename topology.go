@@ -0,0 +1,279 @@
+package pgstats
+
+import (
+	"fmt"
+	"github.com/vynaloze/pgstats/nullable"
+	"io"
+	"net"
+	"strconv"
+)
+
+// Roles of nodes in the replication topology
+const (
+	// RolePrimary marks servers not in recovery
+	RolePrimary = "primary"
+	// RoleStandby marks servers in recovery, including cascading standbys
+	RoleStandby = "standby"
+	// RoleSubscriber marks logical replication subscribers
+	RoleSubscriber = "subscriber"
+)
+
+// Kinds of edges in the replication topology
+const (
+	// ReplicationPhysical marks streaming replication
+	ReplicationPhysical = "physical"
+	// ReplicationLogical marks logical replication
+	ReplicationLogical = "logical"
+)
+
+// TopologyView represents the replication topology: servers and replication connections between them
+type TopologyView struct {
+	// Servers found in the topology, in the order of discovery
+	Nodes []TopologyNode `json:"nodes"`
+	// Replication connections, directed from the sending server to the receiving one
+	Edges []TopologyEdge `json:"edges"`
+}
+
+// TopologyNode represents a single server in the replication topology
+type TopologyNode struct {
+	// Identifier of the server: address and port reported by the server itself if connected via TCP,
+	// otherwise the address and port used to connect to it
+	ID string `json:"id"`
+	// Host used to connect to the server
+	Host string `json:"host"`
+	// Port used to connect to the server
+	Port int `json:"port"`
+	// Role of the server: primary, standby or subscriber. Empty if the server is unreachable and its role is unknown.
+	Role string `json:"role"`
+	// True if the server has been connected to
+	Reachable bool `json:"reachable"`
+	// Reason for which the server could not be connected to, if any
+	Error string `json:"error,omitempty"`
+}
+
+// TopologyEdge represents a single replication connection
+type TopologyEdge struct {
+	// Identifier of the sending server
+	From string `json:"from"`
+	// Identifier of the receiving server
+	To string `json:"to"`
+	// Kind of replication: physical or logical
+	Kind string `json:"kind"`
+	// Name of the application connected to the WAL sender (subscription name for logical replication)
+	ApplicationName nullable.String `json:"application_name"`
+	// Name of the replication slot used, if any
+	SlotName nullable.String `json:"slot_name"`
+	// Current WAL sender state
+	State nullable.String `json:"state"`
+	// Synchronous state of the receiving server
+	SyncState nullable.String `json:"sync_state"`
+	// Amount of WAL not yet replayed by the receiving server, in bytes
+	ReplayLagBytes nullable.Int64 `json:"replay_lag_bytes"`
+	// Time elapsed between flushing recent WAL on the sending server and receiving notification
+	// that the receiving server has applied it, in seconds. Supported since PostgreSQL 10
+	ReplayLag nullable.Float64 `json:"replay_lag"`
+}
+
+// WriteDOT writes the topology as a Graphviz DOT digraph.
+// Unreachable servers are drawn dashed, as well as logical replication edges.
+func (t *TopologyView) WriteDOT(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "digraph replication {"); err != nil {
+		return err
+	}
+	for _, n := range t.Nodes {
+		style := "solid"
+		if !n.Reachable {
+			style = "dashed"
+		}
+		label := n.ID + "\n" + n.Role
+		if _, err := fmt.Fprintf(w, "  %s [label=%s, shape=box, style=%s];\n", strconv.Quote(n.ID), strconv.Quote(label), style); err != nil {
+			return err
+		}
+	}
+	for _, e := range t.Edges {
+		style := "solid"
+		if e.Kind == ReplicationLogical {
+			style = "dashed"
+		}
+		label := e.Kind
+		if e.ReplayLagBytes.Valid {
+			label += "\n" + strconv.FormatInt(e.ReplayLagBytes.Int64, 10) + " bytes"
+		}
+		if e.ReplayLag.Valid {
+			label += "\n" + strconv.FormatFloat(e.ReplayLag.Float64, 'f', 3, 64) + " s"
+		}
+		if _, err := fmt.Fprintf(w, "  %s -> %s [label=%s, style=%s];\n", strconv.Quote(e.From), strconv.Quote(e.To), strconv.Quote(label), style); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// topologyProbe holds everything discovered about a single server
+type topologyProbe struct {
+	// address and port reported by the server, if connected via TCP
+	host       string
+	port       int
+	inRecovery bool
+	// upstream server, if known (PostgreSQL 11 and later)
+	senderHost string
+	senderPort int
+	downstream ReplicationLagView
+}
+
+type topologyProber func(host string, port int) (topologyProbe, error)
+
+func (s *PgStats) fetchTopology() (*TopologyView, error) {
+	host := s.conn.config["host"]
+	if host == "" {
+		host = "localhost"
+	}
+	port := 5432
+	if p, ok := s.conn.config["port"]; ok {
+		if parsed, err := strconv.Atoi(p); err == nil {
+			port = parsed
+		}
+	}
+
+	start, err := s.probeTopology()
+	if err != nil {
+		return nil, err
+	}
+	probe := func(h string, p int) (topologyProbe, error) {
+		if h == host && p == port {
+			return start, nil
+		}
+		n, err := s.connectTo(h, p)
+		if err != nil {
+			return topologyProbe{}, err
+		}
+		defer n.Close()
+		return n.probeTopology()
+	}
+	return discoverTopology(host, port, probe), nil
+}
+
+func (s *PgStats) probeTopology() (topologyProbe, error) {
+	p := topologyProbe{}
+	db := s.db()
+	row := db.QueryRow("select coalesce(host(inet_server_addr()),''),coalesce(inet_server_port(),0)")
+	if err := row.Scan(&p.host, &p.port); err != nil {
+		return p, err
+	}
+	standby, err := s.fetchStandbyLag()
+	if err != nil {
+		return p, err
+	}
+	p.inRecovery = standby.InRecovery
+	p.senderHost = standby.SenderHost.String
+	p.senderPort = int(standby.SenderPort.Int64)
+	p.downstream, err = s.fetchReplicationLag()
+	return p, err
+}
+
+type topologyBuilder struct {
+	probe topologyProber
+	// identifiers of already visited servers by the address used to connect to them
+	visited map[string]string
+	nodes   map[string]int
+	edges   map[[2]string]int
+	view    *TopologyView
+}
+
+// discoverTopology walks the replication topology starting from given server, both downstream
+// (standbys and subscribers from pg_stat_replication) and upstream (sender_host and sender_port
+// of the WAL receiver). Standbys are assumed to listen on the same port as the server they replicate from.
+// Logical subscribers are not connected to, as the name of their database is unknown.
+func discoverTopology(host string, port int, probe topologyProber) *TopologyView {
+	b := &topologyBuilder{
+		probe:   probe,
+		visited: make(map[string]string),
+		nodes:   make(map[string]int),
+		edges:   make(map[[2]string]int),
+		view:    &TopologyView{Nodes: make([]TopologyNode, 0), Edges: make([]TopologyEdge, 0)},
+	}
+	b.visit(host, port, "")
+	return b.view
+}
+
+// visit probes the server (unless already visited), explores its neighbours and returns its identifier.
+// If the server is unreachable, it is assigned the expected role.
+func (b *topologyBuilder) visit(host string, port int, expectedRole string) string {
+	key := net.JoinHostPort(host, strconv.Itoa(port))
+	if id, ok := b.visited[key]; ok {
+		return id
+	}
+	b.visited[key] = key
+
+	p, err := b.probe(host, port)
+	if err != nil {
+		b.addNode(TopologyNode{ID: key, Host: host, Port: port, Role: expectedRole, Error: err.Error()})
+		return key
+	}
+	id := key
+	if p.host != "" {
+		id = net.JoinHostPort(p.host, strconv.Itoa(p.port))
+	}
+	b.visited[key] = id
+	if _, ok := b.nodes[id]; ok {
+		return id
+	}
+	role := RolePrimary
+	if p.inRecovery {
+		role = RoleStandby
+	}
+	b.addNode(TopologyNode{ID: id, Host: host, Port: port, Role: role, Reachable: true})
+
+	for _, r := range p.downstream {
+		edge := TopologyEdge{
+			From:            id,
+			Kind:            ReplicationPhysical,
+			ApplicationName: r.ApplicationName,
+			SlotName:        r.SlotName,
+			State:           r.State,
+			SyncState:       r.SyncState,
+			ReplayLagBytes:  r.ReplayLagBytes,
+			ReplayLag:       r.ReplayLag,
+		}
+		if r.SlotType.String == ReplicationLogical {
+			edge.Kind = ReplicationLogical
+			edge.To = r.ApplicationName.String + "@" + r.ClientAddr.String
+			b.addNode(TopologyNode{ID: edge.To, Host: r.ClientAddr.String, Role: RoleSubscriber})
+		} else {
+			childHost := host
+			if r.ClientAddr.Valid {
+				childHost = r.ClientAddr.String
+			}
+			edge.To = b.visit(childHost, port, RoleStandby)
+		}
+		b.addEdge(edge, true)
+	}
+
+	if p.inRecovery && p.senderHost != "" {
+		upstream := b.visit(p.senderHost, p.senderPort, "")
+		b.addEdge(TopologyEdge{From: upstream, To: id, Kind: ReplicationPhysical}, false)
+	}
+	return id
+}
+
+func (b *topologyBuilder) addNode(node TopologyNode) {
+	if _, ok := b.nodes[node.ID]; ok {
+		return
+	}
+	b.nodes[node.ID] = len(b.view.Nodes)
+	b.view.Nodes = append(b.view.Nodes, node)
+}
+
+// addEdge adds the edge, replacing the existing one between the same servers if replace is set
+func (b *topologyBuilder) addEdge(edge TopologyEdge, replace bool) {
+	key := [2]string{edge.From, edge.To}
+	if i, ok := b.edges[key]; ok {
+		if replace {
+			b.view.Edges[i] = edge
+		}
+		return
+	}
+	b.edges[key] = len(b.view.Edges)
+	b.view.Edges = append(b.view.Edges, edge)
+}
@@ -0,0 +1,89 @@
+package pgstats
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverTopology(t *testing.T) {
+	probes := map[string]topologyProbe{
+		"10.0.0.1": {host: "10.0.0.1", port: 5432, downstream: ReplicationLagView{
+			downstreamRow("10.0.0.2", "standby1", "", 100),
+			downstreamRow("10.0.0.9", "orders_sub", ReplicationLogical, 5),
+		}},
+		"10.0.0.2": {host: "10.0.0.2", port: 5432, inRecovery: true, senderHost: "primary.example.com", senderPort: 5432,
+			downstream: ReplicationLagView{
+				downstreamRow("10.0.0.3", "standby2", "", 20),
+				downstreamRow("10.0.0.4", "standby3", "", 30),
+			}},
+		"10.0.0.3":  {host: "10.0.0.3", port: 5432, inRecovery: true, senderHost: "10.0.0.2", senderPort: 5432},
+		"localhost": {host: "10.0.0.3", port: 5432, inRecovery: true, senderHost: "10.0.0.2", senderPort: 5432},
+		"primary.example.com": {host: "10.0.0.1", port: 5432, downstream: ReplicationLagView{
+			downstreamRow("10.0.0.2", "standby1", "", 100),
+			downstreamRow("10.0.0.9", "orders_sub", ReplicationLogical, 5),
+		}},
+	}
+	probe := func(host string, port int) (topologyProbe, error) {
+		if p, ok := probes[host]; ok {
+			return p, nil
+		}
+		return topologyProbe{}, errors.New("connection refused")
+	}
+
+	topology := discoverTopology("localhost", 5432, probe)
+
+	roles := map[string]string{
+		"10.0.0.3:5432":       RoleStandby,
+		"10.0.0.2:5432":       RoleStandby,
+		"10.0.0.1:5432":       RolePrimary,
+		"orders_sub@10.0.0.9": RoleSubscriber,
+		"10.0.0.4:5432":       RoleStandby,
+	}
+	if len(topology.Nodes) != len(roles) {
+		t.Fatalf("Expected %d nodes; actual %+v", len(roles), topology.Nodes)
+	}
+	for _, n := range topology.Nodes {
+		if roles[n.ID] != n.Role {
+			t.Errorf("Expected %s to be %s; actual %s", n.ID, roles[n.ID], n.Role)
+		}
+		if n.Reachable != (n.ID != "10.0.0.4:5432" && n.Role != RoleSubscriber) {
+			t.Errorf("Unexpected reachability of %+v", n)
+		}
+	}
+
+	lags := map[[2]string]int64{
+		{"10.0.0.2:5432", "10.0.0.3:5432"}:       20,
+		{"10.0.0.2:5432", "10.0.0.4:5432"}:       30,
+		{"10.0.0.1:5432", "10.0.0.2:5432"}:       100,
+		{"10.0.0.1:5432", "orders_sub@10.0.0.9"}: 5,
+	}
+	if len(topology.Edges) != len(lags) {
+		t.Fatalf("Expected %d edges; actual %+v", len(lags), topology.Edges)
+	}
+	for _, e := range topology.Edges {
+		lag, ok := lags[[2]string{e.From, e.To}]
+		if !ok || e.ReplayLagBytes.Int64 != lag {
+			t.Errorf("Unexpected edge %+v", e)
+		}
+	}
+
+	var dot bytes.Buffer
+	if err := topology.WriteDOT(&dot); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(dot.String(), "digraph replication {\n") ||
+		!strings.Contains(dot.String(), `"10.0.0.1:5432" -> "orders_sub@10.0.0.9" [label="logical\n5 bytes", style=dashed];`) {
+		t.Errorf("Unexpected DOT output:\n%s", dot.String())
+	}
+}
+
+func downstreamRow(addr string, app string, slotType string, lag int64) ReplicationLagRow {
+	row := ReplicationLagRow{}
+	row.ClientAddr.Valid, row.ClientAddr.String = true, addr
+	row.ApplicationName.Valid, row.ApplicationName.String = true, app
+	row.SlotType.Valid, row.SlotType.String = slotType != "", slotType
+	row.ReplayLagBytes.Valid, row.ReplayLagBytes.Int64 = true, lag
+	return row
+}
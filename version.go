@@ -7,7 +7,7 @@ import (
 )
 
 func (s *PgStats) getPgVersion() (float64, error) {
-	db := s.conn.db
+	db := s.db()
 	query := "show server_version;"
 	row := db.QueryRow(query)
 	version := new(string)
@@ -0,0 +1,89 @@
+package pgstats
+
+import (
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+)
+
+type view struct {
+	// zero value of the view, describing its type
+	zero  interface{}
+	fetch func(s *PgStats) (interface{}, error)
+}
+
+// views maps names of views to their types and fetchers. Views which need arguments are fetched with their defaults:
+// bloat is estimated, xid ages are limited to 100 tables and idle connections are not reported.
+var views = map[string]view{
+	"pg_stat_activity":            {PgStatActivityView{}, func(s *PgStats) (interface{}, error) { return s.fetchActivity() }},
+	"pg_stat_replication":         {PgStatReplicationView{}, func(s *PgStats) (interface{}, error) { return s.fetchReplication() }},
+	"pg_stat_wal_receiver":        {PgStatWalReceiverView{}, func(s *PgStats) (interface{}, error) { return s.fetchWalReceiver() }},
+	"pg_stat_subscription":        {PgStatSubscriptionView{}, func(s *PgStats) (interface{}, error) { return s.fetchSubscription() }},
+	"pg_stat_ssl":                 {PgStatSslView{}, func(s *PgStats) (interface{}, error) { return s.fetchSsl() }},
+	"pg_stat_progress_vacuum":     {PgStatProgressVacuumView{}, func(s *PgStats) (interface{}, error) { return s.fetchProgressVacuum() }},
+	"pg_stat_archiver":            {PgStatArchiverView{}, func(s *PgStats) (interface{}, error) { return s.fetchArchiver() }},
+	"pg_stat_bgwriter":            {PgStatBgWriterView{}, func(s *PgStats) (interface{}, error) { return s.fetchBgWriter() }},
+	"pg_stat_database":            {PgStatDatabaseView{}, func(s *PgStats) (interface{}, error) { return s.fetchDatabases() }},
+	"pg_stat_database_conflicts":  {PgStatDatabaseConflictsView{}, func(s *PgStats) (interface{}, error) { return s.fetchDatabaseConflicts() }},
+	"pg_stat_all_tables":          {PgStatAllTablesView{}, func(s *PgStats) (interface{}, error) { return s.fetchTables("pg_stat_all_tables") }},
+	"pg_stat_sys_tables":          {PgStatSystemTablesView{}, func(s *PgStats) (interface{}, error) { return s.fetchTables("pg_stat_sys_tables") }},
+	"pg_stat_user_tables":         {PgStatUserTablesView{}, func(s *PgStats) (interface{}, error) { return s.fetchTables("pg_stat_user_tables") }},
+	"pg_stat_xact_all_tables":     {PgStatXactAllTablesView{}, func(s *PgStats) (interface{}, error) { return s.fetchXactTables("pg_stat_xact_all_tables") }},
+	"pg_stat_xact_sys_tables":     {PgStatXactSystemTablesView{}, func(s *PgStats) (interface{}, error) { return s.fetchXactTables("pg_stat_xact_sys_tables") }},
+	"pg_stat_xact_user_tables":    {PgStatXactUserTablesView{}, func(s *PgStats) (interface{}, error) { return s.fetchXactTables("pg_stat_xact_user_tables") }},
+	"pg_stat_all_indexes":         {PgStatAllIndexesView{}, func(s *PgStats) (interface{}, error) { return s.fetchIndexes("pg_stat_all_indexes") }},
+	"pg_stat_sys_indexes":         {PgStatSystemIndexesView{}, func(s *PgStats) (interface{}, error) { return s.fetchIndexes("pg_stat_sys_indexes") }},
+	"pg_stat_user_indexes":        {PgStatUserIndexesView{}, func(s *PgStats) (interface{}, error) { return s.fetchIndexes("pg_stat_user_indexes") }},
+	"pg_statio_all_tables":        {PgStatIoAllTablesView{}, func(s *PgStats) (interface{}, error) { return s.fetchIoTables("pg_statio_all_tables") }},
+	"pg_statio_sys_tables":        {PgStatIoSystemTablesView{}, func(s *PgStats) (interface{}, error) { return s.fetchIoTables("pg_statio_sys_tables") }},
+	"pg_statio_user_tables":       {PgStatIoUserTablesView{}, func(s *PgStats) (interface{}, error) { return s.fetchIoTables("pg_statio_user_tables") }},
+	"pg_statio_all_indexes":       {PgStatIoAllIndexesView{}, func(s *PgStats) (interface{}, error) { return s.fetchIoIndexes("pg_statio_all_indexes") }},
+	"pg_statio_sys_indexes":       {PgStatIoSystemIndexesView{}, func(s *PgStats) (interface{}, error) { return s.fetchIoIndexes("pg_statio_sys_indexes") }},
+	"pg_statio_user_indexes":      {PgStatIoUserIndexesView{}, func(s *PgStats) (interface{}, error) { return s.fetchIoIndexes("pg_statio_user_indexes") }},
+	"pg_statio_all_sequences":     {PgStatIoAllSequencesView{}, func(s *PgStats) (interface{}, error) { return s.fetchIoSequences("pg_statio_all_sequences") }},
+	"pg_statio_sys_sequences":     {PgStatIoSystemSequencesView{}, func(s *PgStats) (interface{}, error) { return s.fetchIoSequences("pg_statio_sys_sequences") }},
+	"pg_statio_user_sequences":    {PgStatIoUserSequencesView{}, func(s *PgStats) (interface{}, error) { return s.fetchIoSequences("pg_statio_user_sequences") }},
+	"pg_stat_user_functions":      {PgStatUserFunctionsView{}, func(s *PgStats) (interface{}, error) { return s.fetchFunctions("pg_stat_user_functions") }},
+	"pg_stat_xact_user_functions": {PgStatXactUserFunctionsView{}, func(s *PgStats) (interface{}, error) { return s.fetchFunctions("pg_stat_xact_user_functions") }},
+	"pg_stat_statements":          {PgStatStatementsView{}, func(s *PgStats) (interface{}, error) { return s.fetchStatements() }},
+	"pg_stat_statements_info":     {PgStatStatementsInfoView{}, func(s *PgStats) (interface{}, error) { return s.fetchStatementsInfo() }},
+	"database_xid_ages":           {DatabaseXidAgeView{}, func(s *PgStats) (interface{}, error) { return s.fetchDatabaseXidAges() }},
+	"table_xid_ages":              {TableXidAgeView{}, func(s *PgStats) (interface{}, error) { return s.fetchTableXidAges(100) }},
+	"xmin_horizon":                {XminHorizonView{}, func(s *PgStats) (interface{}, error) { return s.fetchXminHorizon() }},
+	"autovacuum_queue":            {AutovacuumQueueView{}, func(s *PgStats) (interface{}, error) { return s.fetchAutovacuumQueue() }},
+	"table_bloat":                 {TableBloatView{}, func(s *PgStats) (interface{}, error) { return s.fetchTableBloat(false) }},
+	"index_bloat":                 {IndexBloatView{}, func(s *PgStats) (interface{}, error) { return s.fetchIndexBloat(false) }},
+	"database_sizes":              {DatabaseSizeView{}, func(s *PgStats) (interface{}, error) { return s.fetchDatabaseSizes() }},
+	"index_advice":                {IndexAdviceView{}, func(s *PgStats) (interface{}, error) { return s.fetchIndexAdvice() }},
+	"sequence_usage":              {SequenceUsageView{}, func(s *PgStats) (interface{}, error) { return s.fetchSequenceUsage() }},
+	"connections_report":          {(*ConnectionsReportView)(nil), func(s *PgStats) (interface{}, error) { return s.fetchConnectionsReport(ConnectionThresholds{}) }},
+	"replication_lag":             {ReplicationLagView{}, func(s *PgStats) (interface{}, error) { return s.fetchReplicationLag() }},
+	"standby_lag":                 {StandbyLagView{}, func(s *PgStats) (interface{}, error) { return s.fetchStandbyLag() }},
+	"subscription_stats":          {SubscriptionStatsView{}, func(s *PgStats) (interface{}, error) { return s.fetchSubscriptionStats() }},
+	"subscription_rels":           {SubscriptionRelView{}, func(s *PgStats) (interface{}, error) { return s.fetchSubscriptionRels() }},
+}
+
+func (s *PgStats) fetchView(name string) (interface{}, error) {
+	v, ok := views[name]
+	if !ok {
+		return nil, errors.Errorf("Unknown view: %s", name)
+	}
+	return v.fetch(s)
+}
+
+func viewType(name string) (reflect.Type, error) {
+	v, ok := views[name]
+	if !ok {
+		return nil, errors.Errorf("Unknown view: %s", name)
+	}
+	return reflect.TypeOf(v.zero), nil
+}
+
+func viewNames() []string {
+	names := make([]string, 0, len(views))
+	for name := range views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
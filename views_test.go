@@ -0,0 +1,32 @@
+package pgstats
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestViewNames(t *testing.T) {
+	names := viewNames()
+	if len(names) != len(views) || !sort.StringsAreSorted(names) {
+		t.Errorf("Expected all %d views in alphabetical order; actual %v", len(views), names)
+	}
+}
+
+func TestFetchUnknownView(t *testing.T) {
+	s := &PgStats{}
+	_, err := s.fetchView("pg_stat_nothing")
+	if err == nil || err.Error() != "Unknown view: pg_stat_nothing" {
+		t.Errorf("Expected unknown view error; actual %v", err)
+	}
+}
+
+func TestViewType(t *testing.T) {
+	typ, err := viewType("pg_stat_database")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ != reflect.TypeOf(PgStatDatabaseView{}) {
+		t.Errorf("Expected PgStatDatabaseView; actual %v", typ)
+	}
+}
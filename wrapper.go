@@ -3,6 +3,7 @@ package pgstats
 import (
 	"errors"
 	"sync"
+	"time"
 )
 
 var wrapper = struct {
@@ -403,3 +404,357 @@ func PgStatStatements() (PgStatStatementsView, error) {
 	}
 	return wrapper.stats.fetchStatements()
 }
+
+// PgStatStatementsInfo returns a single struct, containing statistics
+// of the pg_stat_statements module itself.
+//
+// Supported since PostgreSQL 14.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/pgstatstatements.html#PGSTATSTATEMENTS-PG-STAT-STATEMENTS-INFO
+func PgStatStatementsInfo() (PgStatStatementsInfoView, error) {
+	if !wrapper.opened {
+		return PgStatStatementsInfoView{}, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchStatementsInfo()
+}
+
+// DatabaseXidAges returns a slice containing the age of the oldest unfrozen transaction ID and multixact ID
+// of each database in the cluster, related to autovacuum_freeze_max_age and to the wraparound limit.
+// Databases are ordered by transaction ID age, oldest first.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/routine-vacuuming.html#VACUUM-FOR-WRAPAROUND
+func DatabaseXidAges() (DatabaseXidAgeView, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchDatabaseXidAges()
+}
+
+// TableXidAges returns a slice containing at most limit tables of the current database
+// with the oldest unfrozen transaction IDs, related to autovacuum_freeze_max_age and to the wraparound limit.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/routine-vacuuming.html#VACUUM-FOR-WRAPAROUND
+func TableXidAges(limit int) (TableXidAgeView, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchTableXidAges(limit)
+}
+
+// XminHorizon returns a slice containing everything that holds back the xmin horizon -
+// long-running transactions, prepared transactions, replication slots and standbys with hot_standby_feedback -
+// ordered by the age of the transaction ID held back, oldest first.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/routine-vacuuming.html#VACUUM-FOR-WRAPAROUND
+func XminHorizon() (XminHorizonView, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchXminHorizon()
+}
+
+// AutovacuumQueue returns a slice containing all user tables in the current database and their TOAST tables
+// together with their autovacuum and autoanalyze thresholds (computed from global settings
+// and table storage parameters, including the insert threshold since PostgreSQL 13)
+// and vacuums currently running on them,
+// ordered by how far past their thresholds they are, most urgent first.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/routine-vacuuming.html#AUTOVACUUM
+func AutovacuumQueue() (AutovacuumQueueView, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchAutovacuumQueue()
+}
+
+// TableBloat returns a slice containing estimated bloat (space wasted by dead rows and excessive free space)
+// of each user table in the current database.
+// Bloat is estimated from statistics in pg_stats, so the tables should be analyzed recently.
+// If precise is set and pgstattuple extension is installed, bloat is measured with pgstattuple instead,
+// which reads whole tables and can be expensive.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/pgstattuple.html
+func TableBloat(precise bool) (TableBloatView, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchTableBloat(precise)
+}
+
+// IndexBloat returns a slice containing estimated bloat of each user btree index in the current database.
+// Bloat is estimated from statistics in pg_stats, so the tables should be analyzed recently.
+// If precise is set and pgstattuple extension is installed, bloat is measured with pgstatindex instead,
+// which reads whole indexes and can be expensive.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/pgstattuple.html
+func IndexBloat(precise bool) (IndexBloatView, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchIndexBloat(precise)
+}
+
+// DatabaseSizes returns a slice containing disk space used by each database in the cluster,
+// together with the time of measurement, so that the growth between two snapshots can be computed with Growth.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-DBOBJECT
+func DatabaseSizes() (DatabaseSizeView, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchDatabaseSizes()
+}
+
+// JoinTableSizes fills size fields (heap, TOAST, indexes and total size) of given rows of pg_stat_*_tables views.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-DBOBJECT
+func JoinTableSizes(tables []PgStatTablesRow) error {
+	if !wrapper.opened {
+		return errors.New("connection has not been defined")
+	}
+	return wrapper.stats.joinTableSizes(tables)
+}
+
+// JoinIndexSizes fills size field of given rows of pg_stat_*_indexes views.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-DBOBJECT
+func JoinIndexSizes(indexes []PgStatIndexesRow) error {
+	if !wrapper.opened {
+		return errors.New("connection has not been defined")
+	}
+	return wrapper.stats.joinIndexSizes(indexes)
+}
+
+// IndexAdvice returns user indexes in the current database which are candidates for removal:
+// invalid indexes, exact duplicates, indexes whose columns are a left prefix of another index,
+// and indexes never scanned since statistics were last reset. Indexes backing constraints are skipped,
+// unless invalid. Sizes of the indexes are reported to estimate the savings.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/catalog-pg-index.html
+func IndexAdvice() (IndexAdviceView, error) {
+	if !wrapper.opened {
+		return IndexAdviceView{}, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchIndexAdvice()
+}
+
+// SequenceUsage returns a slice containing current value and percentage of the range used of each sequence
+// in the current database. Serial and identity columns whose type is narrower than the type of their sequence
+// are flagged, and their range is limited to the range of the column type.
+// Supported since PostgreSQL 10.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/view-pg-sequences.html
+func SequenceUsage() (SequenceUsageView, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchSequenceUsage()
+}
+
+// ConnectionsReport returns client connections aggregated by state, database, user, application and client address,
+// compared to max_connections, superuser_reserved_connections and per-database and per-role connection limits.
+// Connections idle (or idle in transaction) for longer than the given thresholds are listed.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/runtime-config-connection.html
+func ConnectionsReport(thresholds ConnectionThresholds) (*ConnectionsReportView, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchConnectionsReport(thresholds)
+}
+
+// LongRunning returns a slice containing client backends whose current query, transaction
+// or idle in transaction state lasts longer than given thresholds, together with the ages computed
+// from the server clock.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/monitoring-stats.html#PG-STAT-ACTIVITY-VIEW
+func LongRunning(thresholds LongRunningThresholds) (LongRunningView, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchLongRunning(thresholds)
+}
+
+// Enforce cancels or terminates long running backends according to the rules of given policy,
+// skipping excluded users and applications. In dry-run mode the actions are only returned and logged.
+// Sending signals to backends of other users requires superuser or pg_signal_backend role.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-SIGNAL
+func Enforce(policy EnforcementPolicy) ([]EnforcementAction, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.enforce(policy)
+}
+
+// CancelBackend cancels the current query of the backend with given process ID.
+// It returns false if the signal could not be sent.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-SIGNAL
+func CancelBackend(pid int64) (bool, error) {
+	if !wrapper.opened {
+		return false, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.cancelBackend(pid)
+}
+
+// TerminateBackend terminates the backend with given process ID.
+// It returns false if the signal could not be sent.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-SIGNAL
+func TerminateBackend(pid int64) (bool, error) {
+	if !wrapper.opened {
+		return false, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.terminateBackend(pid)
+}
+
+// CancelBackendStartedAt cancels the current query of the backend with given process ID,
+// provided that the backend has been started at given time (see PgStatActivityRow.BackendStart),
+// so that a process reusing the ID is never signalled.
+// It returns false if there is no such backend or the signal could not be sent.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-SIGNAL
+func CancelBackendStartedAt(pid int64, backendStart time.Time) (bool, error) {
+	if !wrapper.opened {
+		return false, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.cancelBackendStartedAt(pid, backendStart)
+}
+
+// TerminateBackendStartedAt terminates the backend with given process ID,
+// provided that it has been started at given time (see PgStatActivityRow.BackendStart),
+// so that a process reusing the ID is never signalled.
+// It returns false if there is no such backend or the signal could not be sent.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-SIGNAL
+func TerminateBackendStartedAt(pid int64, backendStart time.Time) (bool, error) {
+	if !wrapper.opened {
+		return false, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.terminateBackendStartedAt(pid, backendStart)
+}
+
+// ReplicationLag returns a slice containing replication lag of each standby connected to the current server:
+// amount of WAL not yet sent, written, flushed and replayed by the standby, time lags and associated replication slot.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/monitoring-stats.html#PG-STAT-REPLICATION-VIEW
+func ReplicationLag() (ReplicationLagView, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchReplicationLag()
+}
+
+// StandbyLag returns replication lag of the current server as seen by itself, if it is a standby:
+// state of the WAL receiver, amount of WAL received but not yet replayed and time since the last replayed transaction.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-RECOVERY-INFO-TABLE
+func StandbyLag() (StandbyLagView, error) {
+	if !wrapper.opened {
+		return StandbyLagView{}, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchStandbyLag()
+}
+
+// Topology discovers the replication topology the current server is part of: its primary, standbys,
+// cascading standbys and logical subscribers, with replication lag on each connection.
+// Neighbouring servers are connected to with the same connection parameters except for host and port.
+// Standbys are assumed to listen on the same port as the server they replicate from, and upstream servers
+// can be discovered only since PostgreSQL 11.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/warm-standby.html#CASCADING-REPLICATION
+func Topology() (*TopologyView, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchTopology()
+}
+
+// SubscriptionStats returns a slice containing error statistics of each logical replication subscription.
+// Supported since PostgreSQL 15.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-SUBSCRIPTION-STATS
+func SubscriptionStats() (SubscriptionStatsView, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchSubscriptionStats()
+}
+
+// SubscriptionRels returns a slice containing synchronization state of each table
+// of logical replication subscriptions in the current database.
+// Supported since PostgreSQL 10.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/catalog-pg-subscription-rel.html
+func SubscriptionRels() (SubscriptionRelView, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchSubscriptionRels()
+}
+
+// SubscriptionLag returns a slice containing lag of each logical replication subscription in the current database
+// behind the publisher, which is computed by pairing the subscriptions with their replication slots on the publisher.
+// Subscriptions without a matching slot on the given publisher have no lag computed.
+// Supported since PostgreSQL 10.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/logical-replication-monitoring.html
+func SubscriptionLag(publisher *PgStats) (SubscriptionLagView, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchSubscriptionLag(publisher)
+}
+
+// Fetch returns the view of given name, as returned by its function (e.g. PgStatDatabaseView for "pg_stat_database").
+// Views which need arguments are fetched with defaults: bloat is estimated, xid ages are limited to 100 tables
+// and idle connections are not reported.
+// See Views for all supported names.
+func Fetch(view string) (interface{}, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.fetchView(view)
+}
+
+// Snapshot fetches given views (all views, if none given) in a single read-only transaction,
+// so that they reflect the same moment. On PostgreSQL 15 and later, stats_fetch_consistency is set to snapshot
+// for the transaction, so that all views see the same cached statistics; in earlier versions,
+// statistics are cached for the whole transaction anyway.
+// Views which cannot be fetched, e.g. pg_stat_statements without the extension, are reported in Errors
+// of the snapshot without affecting the remaining ones.
+//
+// For more details, see:
+// https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-STATS-VIEWS
+func Snapshot(views ...string) (*SnapshotView, error) {
+	if !wrapper.opened {
+		return nil, errors.New("connection has not been defined")
+	}
+	return wrapper.stats.takeSnapshot(views)
+}
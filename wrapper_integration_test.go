@@ -6,6 +6,7 @@ import (
 	"github.com/vynaloze/pgstats"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestReturnErrorOnUndefinedConnection(t *testing.T) {
@@ -268,3 +269,202 @@ func TestPgStatStatementsWrapper(t *testing.T) {
 	ss, err := pgstats.PgStatStatements()
 	validate(t, len(ss), err)
 }
+
+func TestPgStatStatementsInfoWrapper(t *testing.T) {
+	t.Parallel()
+	err := pgstats.DefineConnection(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = pgstats.PgStatStatementsInfo()
+	if err != nil && !strings.Contains(err.Error(), "Unsupported PostgreSQL version") {
+		t.Error(err)
+	}
+}
+
+func TestXidWraparoundWrapper(t *testing.T) {
+	t.Parallel()
+	err := pgstats.DefineConnection(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	d, err := pgstats.DatabaseXidAges()
+	validate(t, len(d), err)
+	tbl, err := pgstats.TableXidAges(10)
+	validate(t, len(tbl), err)
+	_, err = pgstats.XminHorizon()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAutovacuumQueueWrapper(t *testing.T) {
+	t.Parallel()
+	err := pgstats.DefineConnection(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	q, err := pgstats.AutovacuumQueue()
+	validate(t, len(q), err)
+}
+
+func TestBloatWrapper(t *testing.T) {
+	t.Parallel()
+	err := pgstats.DefineConnection(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	tbl, err := pgstats.TableBloat(false)
+	validate(t, len(tbl), err)
+	_, err = pgstats.IndexBloat(false)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSizesWrapper(t *testing.T) {
+	t.Parallel()
+	err := pgstats.DefineConnection(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	d, err := pgstats.DatabaseSizes()
+	validate(t, len(d), err)
+	tbl, err := pgstats.PgStatUserTables()
+	validate(t, len(tbl), err)
+	err = pgstats.JoinTableSizes(tbl)
+	if err != nil {
+		t.Error(err)
+	}
+	idx, err := pgstats.PgStatUserIndexes()
+	validate(t, len(idx), err)
+	err = pgstats.JoinIndexSizes(idx)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIndexAdviceWrapper(t *testing.T) {
+	t.Parallel()
+	err := pgstats.DefineConnection(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = pgstats.IndexAdvice()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSequenceUsageWrapper(t *testing.T) {
+	t.Parallel()
+	err := pgstats.DefineConnection(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = pgstats.SequenceUsage()
+	if err != nil && !strings.Contains(err.Error(), "Unsupported PostgreSQL version: 9.") {
+		t.Error(err)
+	}
+}
+
+func TestConnectionsReportWrapper(t *testing.T) {
+	t.Parallel()
+	err := pgstats.DefineConnection(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	r, err := pgstats.ConnectionsReport(pgstats.ConnectionThresholds{Idle: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	validate(t, int(r.Total), err)
+}
+
+func TestLongRunningWrapper(t *testing.T) {
+	t.Parallel()
+	err := pgstats.DefineConnection(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = pgstats.LongRunning(pgstats.LongRunningThresholds{Transaction: time.Hour})
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = pgstats.Enforce(pgstats.EnforcementPolicy{DryRun: true})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReplicationLagWrapper(t *testing.T) {
+	t.Parallel()
+	err := pgstats.DefineConnection(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = pgstats.ReplicationLag()
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = pgstats.StandbyLag()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTopologyWrapper(t *testing.T) {
+	t.Parallel()
+	err := pgstats.DefineConnection(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	topology, err := pgstats.Topology()
+	if err != nil {
+		t.Fatal(err)
+	}
+	validate(t, len(topology.Nodes), err)
+}
+
+func TestSubscriptionsWrapper(t *testing.T) {
+	t.Parallel()
+	err := pgstats.DefineConnection(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = pgstats.SubscriptionStats()
+	if err != nil && !strings.Contains(err.Error(), "Unsupported PostgreSQL version") {
+		t.Error(err)
+	}
+	_, err = pgstats.SubscriptionRels()
+	if err != nil && !strings.Contains(err.Error(), "Unsupported PostgreSQL version: 9.") {
+		t.Error(err)
+	}
+}
+
+func TestFetchWrapper(t *testing.T) {
+	t.Parallel()
+	err := pgstats.DefineConnection(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = pgstats.Fetch("pg_stat_bgwriter")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSnapshotWrapper(t *testing.T) {
+	t.Parallel()
+	err := pgstats.DefineConnection(*dbname, *user, *password, pgstats.SslMode("disable"))
+	if err != nil {
+		t.Error(err)
+	}
+	snap, err := pgstats.Snapshot("pg_stat_database", "pg_stat_bgwriter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Views) != 2 {
+		t.Errorf("Expected 2 views; actual %d", len(snap.Views))
+	}
+}
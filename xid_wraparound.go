@@ -0,0 +1,215 @@
+package pgstats
+
+import (
+	"github.com/vynaloze/pgstats/nullable"
+)
+
+// xidWraparoundLimit is the number of transaction IDs available before a wraparound,
+// i.e. the maximum age a transaction ID can reach before the server refuses to assign new ones
+const xidWraparoundLimit = 1 << 31
+
+// DatabaseXidAgeView represents transaction ID and multixact ID age of each database in the cluster
+type DatabaseXidAgeView []DatabaseXidAgeRow
+
+// DatabaseXidAgeRow represents transaction ID and multixact ID age of a single database
+type DatabaseXidAgeRow struct {
+	// OID of a database
+	Datid int64 `json:"datid"`
+	// Name of this database
+	Datname string `json:"datname"`
+	// False if connections to this database are not allowed, e.g. template0.
+	// Such a database can only be vacuumed by autovacuum, unless connections are allowed first.
+	Datallowconn bool `json:"datallowconn"`
+	// Age of the oldest unfrozen transaction ID in this database - age(datfrozenxid)
+	XidAge int64 `json:"xid_age"`
+	// Age of the oldest unfrozen multixact ID in this database - mxid_age(datminmxid).
+	// Supported since PostgreSQL 9.5
+	MxidAge nullable.Int64 `json:"mxid_age"`
+	// XidAge as a fraction of autovacuum_freeze_max_age.
+	// Above 1, anti-wraparound autovacuum is forced on the oldest tables.
+	FreezeMaxAgeRatio float64 `json:"freeze_max_age_ratio"`
+	// MxidAge as a fraction of autovacuum_multixact_freeze_max_age.
+	// Supported since PostgreSQL 9.5
+	MultixactFreezeMaxAgeRatio nullable.Float64 `json:"multixact_freeze_max_age_ratio"`
+	// XidAge as a fraction of transaction IDs available before wraparound.
+	// The server stops accepting commands shortly before it reaches 1.
+	WraparoundRatio float64 `json:"wraparound_ratio"`
+}
+
+// TableXidAgeView represents transaction ID and multixact ID age of tables in the current database
+type TableXidAgeView []TableXidAgeRow
+
+// TableXidAgeRow represents transaction ID and multixact ID age of a single table
+type TableXidAgeRow struct {
+	// OID of a table
+	Relid int64 `json:"relid"`
+	// Name of the schema that this table is in
+	Schemaname string `json:"schemaname"`
+	// Name of this table
+	Relname string `json:"relname"`
+	// Age of the oldest unfrozen transaction ID in this table (including its TOAST table) - age(relfrozenxid)
+	XidAge int64 `json:"xid_age"`
+	// Age of the oldest unfrozen multixact ID in this table (including its TOAST table) - mxid_age(relminmxid).
+	// Supported since PostgreSQL 9.5
+	MxidAge nullable.Int64 `json:"mxid_age"`
+	// XidAge as a fraction of autovacuum_freeze_max_age.
+	// Above 1, anti-wraparound autovacuum is forced on this table.
+	FreezeMaxAgeRatio float64 `json:"freeze_max_age_ratio"`
+	// XidAge as a fraction of transaction IDs available before wraparound
+	WraparoundRatio float64 `json:"wraparound_ratio"`
+}
+
+// XminHorizonView represents everything that holds back the xmin horizon,
+// preventing vacuum from removing dead rows and freezing old transaction IDs
+type XminHorizonView []XminHorizonRow
+
+// XminHorizonRow represents a single holder of the xmin horizon
+type XminHorizonRow struct {
+	// Kind of the holder: transaction, prepared_transaction, replication_slot or standby
+	// (a standby with hot_standby_feedback enabled)
+	Kind string `json:"kind"`
+	// Identifier of the holder: process ID of the backend, global identifier of the prepared transaction,
+	// name of the replication slot or application name of the standby
+	Name string `json:"name"`
+	// Name of the database the holder is associated with, if any
+	Datname nullable.String `json:"datname"`
+	// Age of the oldest transaction ID held back
+	XminAge int64 `json:"xmin_age"`
+	// Time since when the holder exists (start of the transaction, time of preparation,
+	// or start of the WAL sender process), if known
+	Since nullable.Time `json:"since"`
+}
+
+func (s *PgStats) fetchDatabaseXidAges() (DatabaseXidAgeView, error) {
+	version, err := s.getPgVersion()
+	if err != nil {
+		return nil, err
+	}
+	freezeMaxAge, err := s.fetchSettingInt("autovacuum_freeze_max_age")
+	if err != nil {
+		return nil, err
+	}
+	var multixactFreezeMaxAge int64
+	mxidAge := "null"
+	if version > 9.4 {
+		mxidAge = "mxid_age(datminmxid)"
+		multixactFreezeMaxAge, err = s.fetchSettingInt("autovacuum_multixact_freeze_max_age")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	db := s.db()
+	query := "select oid,datname,datallowconn,age(datfrozenxid)," + mxidAge + " from pg_database order by 4 desc"
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make(DatabaseXidAgeView, 0)
+	for rows.Next() {
+		row := new(DatabaseXidAgeRow)
+		err := rows.Scan(&row.Datid, &row.Datname, &row.Datallowconn, &row.XidAge, &row.MxidAge)
+		if err != nil {
+			return nil, err
+		}
+		computeDatabaseXidAge(row, freezeMaxAge, multixactFreezeMaxAge)
+		data = append(data, *row)
+	}
+	return data, rows.Err()
+}
+
+func (s *PgStats) fetchTableXidAges(limit int) (TableXidAgeView, error) {
+	version, err := s.getPgVersion()
+	if err != nil {
+		return nil, err
+	}
+	freezeMaxAge, err := s.fetchSettingInt("autovacuum_freeze_max_age")
+	if err != nil {
+		return nil, err
+	}
+	mxidAge := "null"
+	if version > 9.4 {
+		mxidAge = "greatest(mxid_age(c.relminmxid),mxid_age(t.relminmxid))"
+	}
+
+	db := s.db()
+	query := "select c.oid,n.nspname,c.relname," +
+		"greatest(age(c.relfrozenxid),age(t.relfrozenxid))," + mxidAge + " " +
+		"from pg_class c join pg_namespace n on n.oid=c.relnamespace " +
+		"left join pg_class t on t.oid=c.reltoastrelid " +
+		"where c.relkind in ('r','m') order by 4 desc limit $1"
+
+	rows, err := db.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make(TableXidAgeView, 0)
+	for rows.Next() {
+		row := new(TableXidAgeRow)
+		err := rows.Scan(&row.Relid, &row.Schemaname, &row.Relname, &row.XidAge, &row.MxidAge)
+		if err != nil {
+			return nil, err
+		}
+		computeTableXidAge(row, freezeMaxAge)
+		data = append(data, *row)
+	}
+	return data, rows.Err()
+}
+
+// computeDatabaseXidAge fills the ratios of the row
+func computeDatabaseXidAge(row *DatabaseXidAgeRow, freezeMaxAge int64, multixactFreezeMaxAge int64) {
+	row.FreezeMaxAgeRatio = float64(row.XidAge) / float64(freezeMaxAge)
+	row.WraparoundRatio = float64(row.XidAge) / xidWraparoundLimit
+	if row.MxidAge.Valid && multixactFreezeMaxAge > 0 {
+		row.MultixactFreezeMaxAgeRatio.Valid = true
+		row.MultixactFreezeMaxAgeRatio.Float64 = float64(row.MxidAge.Int64) / float64(multixactFreezeMaxAge)
+	}
+}
+
+// computeTableXidAge fills the ratios of the row
+func computeTableXidAge(row *TableXidAgeRow, freezeMaxAge int64) {
+	row.FreezeMaxAgeRatio = float64(row.XidAge) / float64(freezeMaxAge)
+	row.WraparoundRatio = float64(row.XidAge) / xidWraparoundLimit
+}
+
+func (s *PgStats) fetchXminHorizon() (XminHorizonView, error) {
+	db := s.db()
+	// the session running this query always holds an xmin itself
+	query := "select 'transaction',pid::text,datname::text," +
+		"greatest(age(backend_xid),age(backend_xmin)),xact_start from pg_stat_activity " +
+		"where (backend_xid is not null or backend_xmin is not null) and pid<>pg_backend_pid() " +
+		"and pid not in (select pid from pg_stat_replication) " +
+		"union all " +
+		"select 'prepared_transaction',gid,database::text,age(transaction),prepared from pg_prepared_xacts " +
+		"union all " +
+		"select 'replication_slot',slot_name::text,database::text," +
+		"greatest(age(xmin),age(catalog_xmin)),null from pg_replication_slots " +
+		"where xmin is not null or catalog_xmin is not null " +
+		"union all " +
+		"select 'standby',coalesce(application_name,pid::text),null," +
+		"age(backend_xmin),backend_start from pg_stat_replication " +
+		"where backend_xmin is not null " +
+		"order by 4 desc"
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make(XminHorizonView, 0)
+	for rows.Next() {
+		row := new(XminHorizonRow)
+		err := rows.Scan(&row.Kind, &row.Name, &row.Datname, &row.XminAge, &row.Since)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, *row)
+	}
+	return data, rows.Err()
+}
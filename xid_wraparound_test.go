@@ -0,0 +1,36 @@
+package pgstats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDatabaseXidAge(t *testing.T) {
+	row := DatabaseXidAgeRow{Datname: "app", XidAge: 100000000}
+	row.MxidAge.Valid, row.MxidAge.Int64 = true, 100000000
+	computeDatabaseXidAge(&row, 200000000, 400000000)
+	if row.FreezeMaxAgeRatio != 0.5 || row.MultixactFreezeMaxAgeRatio.Float64 != 0.25 {
+		t.Errorf("Expected freeze max age ratios 0.5 and 0.25; actual %f and %f", row.FreezeMaxAgeRatio, row.MultixactFreezeMaxAgeRatio.Float64)
+	}
+	if math.Abs(row.WraparoundRatio-100000000.0/2147483648) > 1e-9 {
+		t.Errorf("Unexpected wraparound ratio: %f", row.WraparoundRatio)
+	}
+
+	// before PostgreSQL 9.5
+	old := DatabaseXidAgeRow{Datname: "app", XidAge: 100000000}
+	computeDatabaseXidAge(&old, 200000000, 0)
+	if old.MultixactFreezeMaxAgeRatio.Valid {
+		t.Errorf("Expected no multixact ratio without multixact age; actual %f", old.MultixactFreezeMaxAgeRatio.Float64)
+	}
+}
+
+func TestTableXidAge(t *testing.T) {
+	row := TableXidAgeRow{Relname: "events", XidAge: 300000000}
+	computeTableXidAge(&row, 200000000)
+	if row.FreezeMaxAgeRatio != 1.5 {
+		t.Errorf("Expected freeze max age ratio 1.5; actual %f", row.FreezeMaxAgeRatio)
+	}
+	if math.Abs(row.WraparoundRatio-300000000.0/2147483648) > 1e-9 {
+		t.Errorf("Unexpected wraparound ratio: %f", row.WraparoundRatio)
+	}
+}